@@ -26,12 +26,13 @@ const docTemplate = `{
     "paths": {
         "/examples": {
             "get": {
-                "description": "Returns a list of examples with optional pagination",
+                "description": "Returns a list of examples ordered by creation time, then ID, with optional pagination",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml"
                 ],
                 "tags": [
                     "examples"
@@ -41,7 +42,7 @@ const docTemplate = `{
                     {
                         "type": "integer",
                         "default": 10,
-                        "description": "Maximum number of results to return",
+                        "description": "Maximum number of results to return, clamped to the server's max page size",
                         "name": "limit",
                         "in": "query"
                     },
@@ -51,16 +52,25 @@ const docTemplate = `{
                         "description": "Number of items to skip",
                         "name": "offset",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from a previous page's next_cursor; an empty value starts from the first page",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully retrieved examples",
+                        "description": "Successfully retrieved a page of examples",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Example"
-                            }
+                            "$ref": "#/definitions/models.Page-models_Example"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cursor or pagination parameter",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
                     "500": {
@@ -77,7 +87,8 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml"
                 ],
                 "tags": [
                     "examples"
@@ -113,6 +124,145 @@ const docTemplate = `{
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
+                    "415": {
+                        "description": "Content-Type must be application/json",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes every example. Only registered when server.devRoutes is enabled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "examples"
+                ],
+                "summary": "Delete all examples",
+                "responses": {
+                    "204": {
+                        "description": "Successfully deleted all examples"
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Repository does not support reset",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/examples/archive": {
+            "post": {
+                "description": "Sets the status of every example matching the filter to archived and reports how many changed. The filter needs a status (active or inactive), an olderThan creation cutoff, or both.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml"
+                ],
+                "tags": [
+                    "examples"
+                ],
+                "summary": "Archive examples",
+                "parameters": [
+                    {
+                        "description": "Examples to archive",
+                        "name": "filter",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ArchiveFilter"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Number of examples archived",
+                        "schema": {
+                            "$ref": "#/definitions/models.ArchiveResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type must be application/json",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/examples/batch-get": {
+            "post": {
+                "description": "Retrieves many examples by ID in one request. Found examples are returned in request order and unknown IDs are listed as missing.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml"
+                ],
+                "tags": [
+                    "examples"
+                ],
+                "summary": "Get examples by ID",
+                "parameters": [
+                    {
+                        "description": "IDs to get, at most the server's max page size",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchGetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Found examples and missing IDs",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchGetResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type must be application/json",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -122,6 +272,226 @@ const docTemplate = `{
                 }
             }
         },
+        "/examples/bulk": {
+            "post": {
+                "description": "Creates many examples in one request and reports a result per item. With atomic=true any failure rolls back the whole batch.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml"
+                ],
+                "tags": [
+                    "examples"
+                ],
+                "summary": "Bulk create examples",
+                "parameters": [
+                    {
+                        "description": "Examples to create, at most the server's max page size",
+                        "name": "examples",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ExampleRequest"
+                            }
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Roll back all items if any item fails",
+                        "name": "atomic",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "207": {
+                        "description": "Per-item results",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.BulkCreateItemResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type must be application/json",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/examples/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Streams a Server-Sent Event for every example created, updated or deleted, with a keep-alive comment every 15 seconds. A final close event is sent when the server ends the stream.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "examples"
+                ],
+                "summary": "Stream example changes",
+                "responses": {
+                    "200": {
+                        "description": "One data line per event",
+                        "schema": {
+                            "$ref": "#/definitions/service.Event"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "501": {
+                        "description": "Streaming is not available",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/examples/export": {
+            "get": {
+                "description": "Streams every example as newline-delimited JSON",
+                "produces": [
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "examples"
+                ],
+                "summary": "Export examples",
+                "responses": {
+                    "200": {
+                        "description": "One example per line",
+                        "schema": {
+                            "$ref": "#/definitions/models.Example"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/examples/search": {
+            "get": {
+                "description": "Returns the examples whose name or description contains the search term, ignoring case, most relevant first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml"
+                ],
+                "tags": [
+                    "examples"
+                ],
+                "summary": "Search examples",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search term",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Maximum number of results to return, clamped to the server's max page size",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Matching examples, most relevant first",
+                        "schema": {
+                            "$ref": "#/definitions/models.Page-models_Example"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing search term or invalid limit",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/examples/watch": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Upgrades to a WebSocket streaming a JSON event for every example created, updated or deleted",
+                "tags": [
+                    "examples"
+                ],
+                "summary": "Watch example changes",
+                "responses": {
+                    "101": {
+                        "description": "Switching to a WebSocket of events",
+                        "schema": {
+                            "$ref": "#/definitions/service.Event"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "426": {
+                        "description": "Not a WebSocket handshake",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "501": {
+                        "description": "Watching is not available",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/examples/{id}": {
             "get": {
                 "description": "Retrieves a single example by its ID",
@@ -129,7 +499,8 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml"
                 ],
                 "tags": [
                     "examples"
@@ -142,6 +513,12 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached representation",
+                        "name": "If-None-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -151,6 +528,9 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.Example"
                         }
                     },
+                    "304": {
+                        "description": "Example not modified"
+                    },
                     "404": {
                         "description": "Example not found",
                         "schema": {
@@ -166,12 +546,13 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "Updates an existing example by ID",
+                "description": "Updates an existing example by ID, or creates it if create-on-PUT is enabled",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml"
                 ],
                 "tags": [
                     "examples"
@@ -193,6 +574,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ExampleRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the update is conditional on",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -202,6 +589,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.Example"
                         }
                     },
+                    "201": {
+                        "description": "Successfully created example",
+                        "schema": {
+                            "$ref": "#/definitions/models.Example"
+                        }
+                    },
                     "400": {
                         "description": "Invalid request",
                         "schema": {
@@ -214,6 +607,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Example already exists",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Example has been modified",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type must be application/json",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -300,7 +711,8 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml"
                 ],
                 "tags": [
                     "user"
@@ -316,7 +728,7 @@ const docTemplate = `{
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
                     "500": {
@@ -335,25 +747,51 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns a list of resources that require JWT authentication",
+                "description": "Returns the resources that require JWT authentication, limited to those the token's scopes grant",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml"
                 ],
                 "tags": [
                     "protected"
                 ],
                 "summary": "Get JWT protected resources",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit, clamped to the server's max page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return resources owned by this user",
+                        "name": "ownerId",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "Successfully retrieved protected resources",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.ProtectedResource"
-                            }
+                            "$ref": "#/definitions/models.Page-models_ProtectedResource"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid pagination parameter",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
                     "401": {
@@ -384,25 +822,51 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns a list of resources that require OAuth2 authentication",
+                "description": "Returns the resources that require OAuth2 authentication, limited to those the token's scopes grant",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml"
                 ],
                 "tags": [
                     "protected"
                 ],
                 "summary": "Get OAuth2 protected resources",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit, clamped to the server's max page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return resources owned by this user",
+                        "name": "ownerId",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "Successfully retrieved protected resources",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.ProtectedResource"
-                            }
+                            "$ref": "#/definitions/models.Page-models_ProtectedResource"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid pagination parameter",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
                     "401": {
@@ -425,6 +889,47 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/schemas/{model}": {
+            "get": {
+                "description": "Returns the JSON Schema of a model, generated from its Go type and validate tags",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schemas"
+                ],
+                "summary": "Get model JSON Schema",
+                "parameters": [
+                    {
+                        "enum": [
+                            "Example",
+                            "ExampleRequest",
+                            "ProtectedResource",
+                            "UserProfile"
+                        ],
+                        "type": "string",
+                        "description": "Model name",
+                        "name": "model",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully generated schema",
+                        "schema": {
+                            "$ref": "#/definitions/models.JSONSchema"
+                        }
+                    },
+                    "404": {
+                        "description": "Schema not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
         }
     },
     "definitions": {
@@ -434,8 +939,101 @@ const docTemplate = `{
                 "error": {
                     "type": "string"
                 },
+                "fields": {
+                    "description": "Fields lists per-field problems of a request that failed validation",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.FieldError"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "requestId": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "traceId": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
                 "message": {
                     "type": "string"
+                }
+            }
+        },
+        "models.ArchiveFilter": {
+            "type": "object",
+            "properties": {
+                "olderThan": {
+                    "description": "Only examples created before this time",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "inactive"
+                    ]
+                }
+            }
+        },
+        "models.ArchiveResponse": {
+            "type": "object",
+            "properties": {
+                "archived": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BatchGetRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.BatchGetResponse": {
+            "type": "object",
+            "properties": {
+                "examples": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Example"
+                    }
+                },
+                "missing": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.BulkCreateItemResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "example": {
+                    "$ref": "#/definitions/models.Example"
+                },
+                "index": {
+                    "type": "integer"
                 },
                 "status": {
                     "type": "integer"
@@ -479,6 +1077,126 @@ const docTemplate = `{
                     "type": "string",
                     "maxLength": 100,
                     "minLength": 3
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "inactive",
+                        "archived"
+                    ]
+                }
+            }
+        },
+        "models.JSONSchema": {
+            "type": "object",
+            "properties": {
+                "$schema": {
+                    "type": "string"
+                },
+                "enum": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "format": {
+                    "type": "string"
+                },
+                "items": {
+                    "$ref": "#/definitions/models.JSONSchema"
+                },
+                "maxItems": {
+                    "type": "integer"
+                },
+                "maxLength": {
+                    "type": "integer"
+                },
+                "maximum": {
+                    "type": "number"
+                },
+                "minItems": {
+                    "type": "integer"
+                },
+                "minLength": {
+                    "type": "integer"
+                },
+                "minimum": {
+                    "type": "number"
+                },
+                "properties": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.JSONSchema"
+                    }
+                },
+                "required": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Page-models_Example": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "Data holds the items of the page. Items are marshaled to XML under\ntheir own element name.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Example"
+                    }
+                },
+                "limit": {
+                    "description": "Limit is the effective page size after clamping",
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "description": "NextCursor fetches the following page, empty on the last page or when\npaging by offset",
+                    "type": "string"
+                },
+                "offset": {
+                    "description": "Offset is the number of items skipped before this page",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "Total is the number of items across all pages, when known",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Page-models_ProtectedResource": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "Data holds the items of the page. Items are marshaled to XML under\ntheir own element name.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProtectedResource"
+                    }
+                },
+                "limit": {
+                    "description": "Limit is the effective page size after clamping",
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "description": "NextCursor fetches the following page, empty on the last page or when\npaging by offset",
+                    "type": "string"
+                },
+                "offset": {
+                    "description": "Offset is the number of items skipped before this page",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "Total is the number of items across all pages, when known",
+                    "type": "integer"
                 }
             }
         },
@@ -499,6 +1217,10 @@ const docTemplate = `{
                 },
                 "ownerId": {
                     "type": "string"
+                },
+                "scope": {
+                    "description": "Scope a caller needs to see the resource",
+                    "type": "string"
                 }
             }
         },
@@ -527,6 +1249,20 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "service.Event": {
+            "type": "object",
+            "properties": {
+                "entityId": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
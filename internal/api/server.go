@@ -5,9 +5,12 @@ package api
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"runtime"
+	"sync"
 	"syscall"
 	"time"
 
@@ -15,6 +18,8 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	httpSwagger "github.com/swaggo/http-swagger"
 
+	"github.com/dBiTech/go-apiTemplate/docs"
+	"github.com/dBiTech/go-apiTemplate/internal/audit"
 	"github.com/dBiTech/go-apiTemplate/internal/auth"
 	"github.com/dBiTech/go-apiTemplate/internal/config"
 	"github.com/dBiTech/go-apiTemplate/internal/handlers"
@@ -22,6 +27,7 @@ import (
 	"github.com/dBiTech/go-apiTemplate/internal/repository"
 	"github.com/dBiTech/go-apiTemplate/internal/service"
 	"github.com/dBiTech/go-apiTemplate/pkg/health"
+	"github.com/dBiTech/go-apiTemplate/pkg/httpclient"
 	"github.com/dBiTech/go-apiTemplate/pkg/logger"
 	"github.com/dBiTech/go-apiTemplate/pkg/metrics"
 	"github.com/dBiTech/go-apiTemplate/pkg/telemetry"
@@ -29,20 +35,69 @@ import (
 
 const (
 	appName        = "api-template"
-	appVersion     = "0.1.0"
 	appDescription = "API Template Application"
+
+	// defaultBasePath is where the API routes are mounted unless
+	// server.basePath is set
+	defaultBasePath = "/api/v1"
+
+	// watchEventBuffer is how many events a watcher may fall behind before
+	// it misses some
+	watchEventBuffer = 64
+)
+
+// Build metadata, injected at build time with
+// -ldflags "-X github.com/dBiTech/go-apiTemplate/internal/api.Version=..."
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
 )
 
+// buildInfo returns the injected build metadata along with the Go version
+func buildInfo() health.BuildInfo {
+	return health.BuildInfo{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+}
+
+// oauth2HealthURL returns the OAuth2 provider URL to health check, or empty
+// when tokens aren't verified against the provider
+func oauth2HealthURL(cfg config.AuthConfig) string {
+	if cfg.OAuth2HealthURL != "" {
+		return cfg.OAuth2HealthURL
+	}
+	return cfg.OAuth2IntrospectionURL
+}
+
 // Server represents the API server
 type Server struct {
 	config     *config.Config
 	router     *chi.Mux
 	httpServer *http.Server
-	log        logger.Logger
-	metrics    *metrics.Metrics
-	telemetry  *telemetry.Telemetry
-	health     *health.Checker
-	auth       *auth.Authenticator
+	listener   net.Listener
+
+	// adminRouter serves the observability routes on their own listener when
+	// metrics.adminListener is set; the admin fields are nil otherwise
+	adminRouter   *chi.Mux
+	adminServer   *http.Server
+	adminListener net.Listener
+
+	log       logger.Logger
+	metrics   *metrics.Metrics
+	telemetry *telemetry.Telemetry
+	health    *health.Checker
+	auth      *auth.Authenticator
+
+	// events broadcasts example changes to open streams, which Stop closes
+	events *service.Broadcaster
+
+	// shutdownHooks are run in reverse order of registration by Stop
+	hooksMu       sync.Mutex
+	shutdownHooks []func(ctx context.Context) error
 }
 
 // NewServer creates a new API server
@@ -54,27 +109,40 @@ func NewServer(cfg *config.Config) (*Server, error) {
 	}
 
 	log.Info("initializing api server",
-		logger.String("version", appVersion),
+		logger.String("version", Version),
+		logger.String("commit", Commit),
 		logger.String("config", cfg.String()),
 	)
 
 	// Initialize metrics
-	m := metrics.NewMetrics(appName)
+	m := metrics.NewMetrics(appName,
+		metrics.WithGoCollector(cfg.Metrics.Collectors.Go),
+		metrics.WithProcessCollector(cfg.Metrics.Collectors.Process),
+		metrics.WithBuildInfo(Version, Commit, runtime.Version()),
+		metrics.WithDurationBuckets(cfg.Metrics.Buckets.Duration),
+		metrics.WithRequestSizeBuckets(cfg.Metrics.Buckets.RequestSize),
+		metrics.WithResponseSizeBuckets(cfg.Metrics.Buckets.ResponseSize),
+	)
 
 	// Initialize telemetry
 	tel, err := telemetry.New(context.Background(), telemetry.Config{
 		ServiceName:    appName,
-		ServiceVersion: appVersion,
-		Environment:    "development", // TODO: Make configurable
+		ServiceVersion: Version,
+		Environment:    cfg.Environment,
 		Endpoint:       cfg.Tracing.Endpoint,
 		Enabled:        cfg.Tracing.Enabled,
+		MetricsEnabled: cfg.Tracing.MetricsEnabled,
+		Sampler:        cfg.Tracing.Sampler,
+		Protocol:       cfg.Tracing.Protocol,
+		Insecure:       cfg.Tracing.Insecure,
 	}, log)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create telemetry: %w", err)
 	}
 
 	// Initialize health check
-	healthCheck := health.NewHealthCheck(appName, appVersion, appDescription, log)
+	healthCheck := health.NewHealthCheck(appName, appDescription, buildInfo(), log)
+	healthCheck.SetDegradedIsReady(cfg.Observability.DegradedIsReady)
 
 	// Initialize authenticator
 	authenticator, err := auth.NewAuthenticator(auth.Config{
@@ -82,13 +150,24 @@ func NewServer(cfg *config.Config) (*Server, error) {
 		JWTSigningMethod:   cfg.Auth.JWTSigningMethod,
 		JWTExpirationTime:  cfg.Auth.JWTExpirationTime,
 		JWTIssuer:          cfg.Auth.JWTIssuer,
+		JWTSkipIssuerCheck: cfg.Auth.JWTSkipIssuerCheck,
 		OAuth2ClientID:     cfg.Auth.OAuth2ClientID,
 		OAuth2ClientSecret: cfg.Auth.OAuth2ClientSecret,
 		OAuth2RedirectURL:  cfg.Auth.OAuth2RedirectURL,
 		OAuth2AuthURL:      cfg.Auth.OAuth2AuthURL,
 		OAuth2TokenURL:     cfg.Auth.OAuth2TokenURL,
 		OAuth2Scopes:       cfg.Auth.OAuth2Scopes,
-	}, log)
+		OAuth2HTTPTimeout:  cfg.Auth.OAuth2HTTPTimeout,
+		OAuth2StateTTL:     cfg.Auth.OAuth2StateTTL,
+		OAuth2TokenCookie:  cfg.Auth.OAuth2TokenCookie,
+		AdminScope:         cfg.Auth.AdminScope,
+
+		OAuth2IntrospectionURL:      cfg.Auth.OAuth2IntrospectionURL,
+		OAuth2IntrospectionCacheTTL: cfg.Auth.OAuth2IntrospectionCacheTTL,
+	}, log,
+		auth.WithMetrics(m),
+		auth.WithTransport(httpclient.NewTransport(http.DefaultTransport, tel, m)),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create authenticator: %w", err)
 	}
@@ -114,39 +193,134 @@ func NewServer(cfg *config.Config) (*Server, error) {
 		},
 	}
 
+	// Serve the observability routes on a separate admin listener
+	if cfg.Metrics.Enabled && cfg.Metrics.AdminListener {
+		server.adminRouter = chi.NewRouter()
+		server.adminRouter.NotFound(handlers.NotFoundHandler())
+		server.adminServer = &http.Server{
+			Addr:         fmt.Sprintf("%s:%d", cfg.Metrics.Host, cfg.Metrics.Port),
+			Handler:      server.adminRouter,
+			ReadTimeout:  cfg.Server.ReadTimeout,
+			WriteTimeout: cfg.Server.WriteTimeout,
+			IdleTimeout:  cfg.Server.IdleTimeout,
+		}
+	}
+
 	// Setup routes
-	server.setupRoutes()
+	if err := server.setupRoutes(); err != nil {
+		return nil, fmt.Errorf("failed to setup routes: %w", err)
+	}
+
+	// Apply log level changes from config reloads until the server stops
+	if cfg.Watch {
+		unsubscribe := config.OnChange(func(reloaded *config.Config) {
+			setter, ok := log.(logger.LevelSetter)
+			if !ok {
+				return
+			}
+			if err := setter.SetLevel(reloaded.Logging.Level); err != nil {
+				log.Warn("failed to apply reloaded log level", logger.Error(err))
+			}
+		})
+		server.OnShutdown(func(_ context.Context) error {
+			unsubscribe()
+			return nil
+		})
+	}
 
 	return server, nil
 }
 
 // setupRoutes sets up the API routes
-func (s *Server) setupRoutes() {
-	// Create repository
-	repo := repository.NewMemoryRepository(s.log)
+func (s *Server) setupRoutes() error {
+	// Create repository, timing the calls that reach the store
+	var repo repository.Repository = repository.NewInstrumentedRepository(repository.NewMemoryRepository(s.log), s.metrics)
+	if s.config.Database.BreakerThreshold > 0 {
+		breaker := repository.NewCircuitBreakerRepository(repo, s.log,
+			s.config.Database.BreakerThreshold,
+			s.config.Database.BreakerCooldown,
+		)
+		s.health.AddCheck(health.CircuitBreakerCheck("database-circuit", func() string {
+			return string(breaker.State())
+		}))
+		repo = breaker
+	}
+	if s.config.Cache.ExampleTTL > 0 && s.config.Cache.ExampleSize > 0 {
+		repo = repository.NewCachingRepository(repo, s.log,
+			s.config.Cache.ExampleSize,
+			s.config.Cache.ExampleTTL,
+			repository.WithCacheMetrics(s.metrics),
+		)
+	}
 
-	// Create service
-	svc := service.New(repo, s.log, s.telemetry)
+	// Create service, broadcasting its events to watchers
+	s.events = service.NewBroadcaster(watchEventBuffer)
+	svc := service.New(repo, s.log, s.telemetry,
+		service.WithEventPublisher(s.events),
+		service.WithMetrics(s.metrics),
+		service.WithListCache(s.config.Cache.ListTTL),
+		service.WithUniqueNames(s.config.Examples.UniqueNames),
+		service.WithAuditor(audit.NewLogAuditor(s.log)),
+	)
 
 	// Create handler
-	handler := handlers.NewHandler(s.log, svc)
+	handler := handlers.NewHandler(s.log, svc,
+		handlers.WithMaxPageSize(s.config.Server.MaxPageSize),
+		handlers.WithCreateOnPut(s.config.Examples.CreateOnPut),
+		handlers.WithEventSubscriber(s.events),
+	)
 
 	// Add health check for database
 	s.health.AddCheck(health.DBCheck("database", repo.Ping))
 
+	// Add health check for the OAuth2 provider tokens are verified against
+	if s.config.Auth.Enabled {
+		if url := oauth2HealthURL(s.config.Auth); url != "" {
+			s.health.AddCheck(health.OAuth2Check("oauth2", url, httpclient.New(s.telemetry, s.metrics)))
+		}
+	}
+
+	realIP, err := appmiddleware.RealIP(s.config.Server.TrustedProxies)
+	if err != nil {
+		return fmt.Errorf("failed to create real IP middleware: %w", err)
+	}
+
 	// Middleware
 	s.router.Use(middleware.RequestID)
-	s.router.Use(middleware.RealIP)
-	s.router.Use(appmiddleware.RequestLogger(s.log))
+	if s.config.Server.ServerTiming {
+		s.router.Use(appmiddleware.ServerTiming())
+	}
+	// Ahead of routing, logging and metrics so they all see the overridden method
+	s.router.Use(appmiddleware.MethodOverride())
+	s.router.Use(realIP)
+	s.router.Use(appmiddleware.RequestLogger(s.log, s.config.Observability.ExcludePaths, s.config.Logging.AccessLog))
 	s.router.Use(appmiddleware.Tracing(s.telemetry))
-	s.router.Use(appmiddleware.Metrics(s.metrics))
-	s.router.Use(appmiddleware.Recover(s.log))
+	s.router.Use(appmiddleware.Baggage(s.config.Observability.BaggageHeaders))
+	// Limit ahead of the metrics so rejected requests aren't counted in flight
+	s.router.Use(appmiddleware.MaxConcurrent(s.config.Server.MaxConcurrent))
+	s.router.Use(appmiddleware.Metrics(s.metrics, s.config.Observability.ExcludePaths))
+	s.router.Use(appmiddleware.Recover(s.log, s.config.Server.DebugErrors))
+	s.router.Use(appmiddleware.RedirectHTTPS(appmiddleware.HTTPSOptions{
+		Enabled:      s.config.Server.RedirectHTTPS,
+		HSTSMaxAge:   s.config.Server.HSTSMaxAge,
+		ExcludePaths: s.config.Server.HTTPSExcludePaths,
+	}))
 	s.router.Use(appmiddleware.CORS([]string{"*"})) // TODO: Make configurable
+	if s.config.Server.Compression {
+		s.router.Use(appmiddleware.Compress(s.config.Server.MinCompressBytes))
+	}
+	// Inside compression so the logged bodies are uncompressed
+	s.router.Use(appmiddleware.BodyLogger(s.config.Logging.MaxBodyLogBytes))
+
+	// JSON responses for unmatched routes and methods
+	s.router.NotFound(handlers.NotFoundHandler())
+	s.router.MethodNotAllowed(handlers.MethodNotAllowedHandler())
 
 	// Health routes
 	s.router.Get("/health", s.health.HealthHandler())
 	s.router.Get("/health/liveness", s.health.LivenessHandler())
 	s.router.Get("/health/readiness", s.health.ReadinessHandler())
+	s.router.Get("/version", s.health.VersionHandler())
 
 	// Swagger UI route
 	s.router.Get("/swagger/*", httpSwagger.Handler(
@@ -156,59 +330,160 @@ func (s *Server) setupRoutes() {
 		httpSwagger.DomID("swagger-ui"),
 	))
 
-	// Metrics route
+	// Metrics route, on the admin listener when there is one
 	if s.config.Metrics.Enabled {
-		s.router.Get("/metrics", s.metrics.Handler().ServeHTTP)
+		metricsHandler := s.metrics.Handler()
+		if s.config.Metrics.RequireAuth {
+			if s.config.Metrics.Username != "" {
+				metricsHandler = auth.BasicAuthMiddleware("metrics", s.config.Metrics.Username, s.config.Metrics.Password)(metricsHandler)
+			} else {
+				metricsHandler = s.auth.JWTAuthMiddleware(nil)(metricsHandler)
+			}
+		}
+
+		if s.adminRouter != nil {
+			s.adminRouter.Get("/metrics", metricsHandler.ServeHTTP)
+		} else {
+			s.router.Get("/metrics", metricsHandler.ServeHTTP)
+		}
+	}
+
+	// Mount the API at the configured base path, and document it there
+	basePath := s.config.Server.BasePath
+	if basePath == "" {
+		basePath = defaultBasePath
+	}
+	docs.SwaggerInfo.BasePath = basePath
+
+	// Request validation against the generated OpenAPI spec
+	var validator func(http.Handler) http.Handler
+	if s.config.Server.OpenAPIValidation {
+		var err error
+		validator, err = appmiddleware.OpenAPIValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+		if err != nil {
+			return fmt.Errorf("failed to create OpenAPI validator: %w", err)
+		}
 	}
 
+	// OAuth2 authorization code flow
+	s.router.Route("/auth", func(r chi.Router) {
+		r.Get("/login", s.auth.OAuth2LoginHandler())
+		r.Get("/callback", s.auth.OAuth2CallbackHandler())
+		r.Post("/verify", s.auth.VerifyTokenHandler())
+
+		// Development-only client credentials token endpoint
+		if s.config.Server.DevRoutes {
+			r.Post("/oauth2/token", s.auth.OAuth2TokenHandler())
+		}
+	})
+
 	// API routes
-	s.router.Route("/api/v1", func(r chi.Router) {
+	s.router.Route(basePath, func(r chi.Router) {
+		if s.config.Server.MaxRequestTimeout > 0 {
+			r.Use(appmiddleware.ClientDeadline(s.config.Server.MaxRequestTimeout))
+		}
+
+		r.Use(appmiddleware.CacheControl(s.config.Server.CacheControl))
+
+		if validator != nil {
+			r.Use(validator)
+		}
+
+		if s.config.Server.IdempotencyTTL > 0 {
+			store := appmiddleware.NewMemoryIdempotencyStore(s.config.Server.IdempotencyTTL, s.config.Server.IdempotencyMaxEntries)
+			r.Use(appmiddleware.Idempotency(store, s.config.Server.IdempotencyMaxBodyBytes))
+		}
+
+		// Time the handlers, less any auth timed within them, for Server-Timing
+		r.Use(appmiddleware.TimePhase("handler"))
+
 		r.Get("/hello", handler.HelloHandler())
+		r.Get("/schemas/{model}", handler.SchemaHandler())
+
+		// Bodies must be declared as JSON; applied per route so unmatched
+		// methods still get a 405
+		requireJSON := appmiddleware.RequireJSON()
 
 		r.Route("/examples", func(r chi.Router) {
 			r.Get("/", handler.ListExamplesHandler())
-			r.Post("/", handler.CreateExampleHandler())
+			r.With(requireJSON).Post("/", handler.CreateExampleHandler())
+			r.With(appmiddleware.RequireFeature(s.config.IsEnabled, "bulkCreate"), requireJSON).Post("/bulk", handler.BulkCreateExamplesHandler())
+			r.With(requireJSON).Post("/batch-get", handler.BatchGetExamplesHandler())
+			r.With(requireJSON).Post("/archive", handler.ArchiveExamplesHandler())
+			r.Get("/search", handler.SearchExamplesHandler())
+			r.Get("/export", handler.ExportExamplesHandler())
+			r.With(appmiddleware.TimeMiddleware("auth", s.auth.JWTAuthMiddleware([]string{"read"}))).Get("/watch", handler.WatchExamplesHandler())
+			r.With(appmiddleware.TimeMiddleware("auth", s.auth.JWTAuthMiddleware([]string{"read"}))).Get("/events", handler.StreamExamplesHandler())
 			r.Get("/{id}", handler.GetExampleHandler())
-			r.Put("/{id}", handler.UpdateExampleHandler())
+			r.With(requireJSON).Put("/{id}", handler.UpdateExampleHandler())
 			r.Delete("/{id}", handler.DeleteExampleHandler())
+
+			// Development-only route for clearing all examples
+			if s.config.Server.DevRoutes {
+				r.Delete("/", handler.ResetExamplesHandler())
+			}
 		})
 
 		// JWT protected route
 		r.Route("/protected/jwt", func(r chi.Router) {
 			// Apply JWT authentication middleware with required 'read' scope
-			r.Use(s.auth.JWTAuthMiddleware([]string{"read"}))
+			r.Use(appmiddleware.TimeMiddleware("auth", s.auth.JWTAuthMiddleware([]string{"read"})))
+			r.Use(appmiddleware.UserLogFields())
 			r.Get("/", handler.JWTProtectedResourceHandler())
 		})
 
 		// OAuth2 protected route
 		r.Route("/protected/oauth2", func(r chi.Router) {
 			// Apply OAuth2 authentication middleware with required 'read' scope
-			r.Use(s.auth.OAuth2AuthMiddleware([]string{"read"}))
+			r.Use(appmiddleware.TimeMiddleware("auth", s.auth.OAuth2AuthMiddleware([]string{"read"})))
+			r.Use(appmiddleware.UserLogFields())
 			r.Get("/", handler.OAuth2ProtectedResourceHandler())
 		})
 
 		// User profile route (requires either JWT or OAuth2)
 		r.Route("/me", func(r chi.Router) {
 			// This demonstrates how to use different auth methods for the same endpoint
-			r.With(s.auth.JWTAuthMiddleware(nil)).Get("/", handler.UserProfileHandler())
-			r.With(s.auth.OAuth2AuthMiddleware(nil)).Get("/oauth2", handler.UserProfileHandler())
+			r.With(appmiddleware.TimeMiddleware("auth", s.auth.JWTAuthMiddleware(nil)), appmiddleware.UserLogFields()).Get("/", handler.UserProfileHandler())
+			r.With(appmiddleware.TimeMiddleware("auth", s.auth.OAuth2AuthMiddleware(nil)), appmiddleware.UserLogFields()).Get("/oauth2", handler.UserProfileHandler())
 		})
 	})
+
+	return nil
 }
 
-// Start starts the API server
+// Start starts the API server, and the admin server when configured. Both
+// listeners are opened before Start returns so address errors are reported.
 func (s *Server) Start() error {
-	// Start server in a goroutine
-	go func() {
-		s.log.Info("starting server", logger.String("address", s.httpServer.Addr))
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			s.log.Fatal("server failed", logger.Error(err))
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.httpServer.Addr, err)
+	}
+	s.listener = listener
+
+	if s.adminServer != nil {
+		adminListener, err := net.Listen("tcp", s.adminServer.Addr)
+		if err != nil {
+			_ = listener.Close()
+			return fmt.Errorf("failed to listen on %s: %w", s.adminServer.Addr, err)
 		}
-	}()
+		s.adminListener = adminListener
+
+		go s.serve("admin server", s.adminServer, adminListener)
+	}
+
+	go s.serve("server", s.httpServer, listener)
 
 	return nil
 }
 
+// serve serves srv on listener until it is shut down
+func (s *Server) serve(name string, srv *http.Server, listener net.Listener) {
+	s.log.Info("starting "+name, logger.String("address", listener.Addr().String()))
+	if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+		s.log.Fatal(name+" failed", logger.Error(err))
+	}
+}
+
 // Stop gracefully stops the API server
 func (s *Server) Stop() {
 	s.log.Info("stopping server")
@@ -217,11 +492,36 @@ func (s *Server) Stop() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// End the event streams first, as Shutdown would otherwise wait on them
+	// until the timeout
+	s.events.Close()
+
 	// Shutdown HTTP server
 	if err := s.httpServer.Shutdown(ctx); err != nil {
 		s.log.Error("server shutdown failed", logger.Error(err))
 	}
 
+	// Shutdown the admin server last so metrics stay scrapeable while
+	// requests drain
+	if s.adminServer != nil {
+		if err := s.adminServer.Shutdown(ctx); err != nil {
+			s.log.Error("admin server shutdown failed", logger.Error(err))
+		}
+	}
+
+	// Run cleanup hooks last in, first out, so a hook registered after a
+	// dependency is cleaned up before it
+	s.hooksMu.Lock()
+	hooks := s.shutdownHooks
+	s.shutdownHooks = nil
+	s.hooksMu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](ctx); err != nil {
+			s.log.Error("shutdown hook failed", logger.Error(err))
+		}
+	}
+
 	// Shutdown telemetry
 	if err := s.telemetry.Shutdown(ctx); err != nil {
 		s.log.Error("telemetry shutdown failed", logger.Error(err))
@@ -230,11 +530,39 @@ func (s *Server) Stop() {
 	s.log.Info("server stopped")
 }
 
+// OnShutdown registers fn to run during Stop, after the HTTP servers have
+// drained and before telemetry is flushed. Hooks run in reverse order of
+// registration and share the shutdown timeout through ctx; a failing hook is
+// logged and doesn't stop the rest from running.
+func (s *Server) OnShutdown(fn func(ctx context.Context) error) {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+
+	s.shutdownHooks = append(s.shutdownHooks, fn)
+}
+
 // GetRouter returns the router for testing
 func (s *Server) GetRouter() *chi.Mux {
 	return s.router
 }
 
+// Addr returns the address the API server listens on once started
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// AdminAddr returns the address the admin server listens on once started,
+// or "" when there is no admin listener
+func (s *Server) AdminAddr() string {
+	if s.adminListener == nil {
+		return ""
+	}
+	return s.adminListener.Addr().String()
+}
+
 // GetAuthenticator returns the authenticator for testing
 func (s *Server) GetAuthenticator() *auth.Authenticator {
 	return s.auth
@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/dBiTech/go-apiTemplate/internal/auth"
+	"github.com/dBiTech/go-apiTemplate/internal/middleware"
+	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+)
+
+// Audited actions
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionReset   = "reset"
+	ActionArchive = "archive"
+)
+
+// AuditEntry records who changed which resource and how
+type AuditEntry struct {
+	UserID       string    `json:"userId"`
+	Action       string    `json:"action"`
+	ResourceType string    `json:"resourceType"`
+	ResourceID   string    `json:"resourceId"`
+	Timestamp    time.Time `json:"timestamp"`
+	RequestID    string    `json:"requestId"`
+}
+
+// NewEntry creates an entry for action on the resource, taking the user ID
+// and request ID from ctx. Either is empty when ctx doesn't carry it.
+func NewEntry(ctx context.Context, action, resourceType, resourceID string) AuditEntry {
+	userID, _ := ctx.Value(auth.UserIDContextKey).(string)
+
+	return AuditEntry{
+		UserID:       userID,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Timestamp:    time.Now(),
+		RequestID:    middleware.RequestIDFromContext(ctx),
+	}
+}
+
+// Auditor records audit entries, e.g. to a log or an append-only store
+type Auditor interface {
+	Record(ctx context.Context, entry AuditEntry)
+}
+
+// NoopAuditor discards all entries
+type NoopAuditor struct{}
+
+// Record discards the entry
+func (NoopAuditor) Record(_ context.Context, _ AuditEntry) {}
+
+// LogAuditor writes each entry as a structured log line
+type LogAuditor struct {
+	log logger.Logger
+}
+
+// NewLogAuditor creates an auditor that logs entries to log at info level
+func NewLogAuditor(log logger.Logger) *LogAuditor {
+	return &LogAuditor{
+		log: log,
+	}
+}
+
+// Record logs the entry
+func (a *LogAuditor) Record(_ context.Context, entry AuditEntry) {
+	a.log.Info("audit",
+		logger.String("userId", entry.UserID),
+		logger.String("action", entry.Action),
+		logger.String("resourceType", entry.ResourceType),
+		logger.String("resourceId", entry.ResourceID),
+		logger.Any("timestamp", entry.Timestamp),
+		logger.String("requestId", entry.RequestID),
+	)
+}
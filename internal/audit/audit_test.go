@@ -0,0 +1,36 @@
+package audit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/dBiTech/go-apiTemplate/internal/audit"
+	"github.com/dBiTech/go-apiTemplate/internal/auth"
+	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+)
+
+func TestLogAuditor(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	auditor := audit.NewLogAuditor(logger.NewFromZap(zap.New(core)))
+
+	ctx := context.WithValue(context.Background(), auth.UserIDContextKey, "user-42")
+	auditor.Record(ctx, audit.NewEntry(ctx, audit.ActionUpdate, "example", "example-1"))
+
+	// Test the entry is logged with its fields
+	entries := logs.FilterMessage("audit").All()
+	require.Len(t, entries, 1)
+
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "user-42", fields["userId"])
+	assert.Equal(t, audit.ActionUpdate, fields["action"])
+	assert.Equal(t, "example", fields["resourceType"])
+	assert.Equal(t, "example-1", fields["resourceId"])
+	assert.Equal(t, "", fields["requestId"])
+	assert.Contains(t, fields, "timestamp")
+}
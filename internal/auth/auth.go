@@ -10,13 +10,16 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/oauth2"
 
 	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+	"github.com/dBiTech/go-apiTemplate/pkg/metrics"
 )
 
 // Standard errors
@@ -26,6 +29,10 @@ var (
 	ErrInsufficientScope    = errors.New("insufficient scope")
 	ErrMissingToken         = errors.New("missing token")
 	ErrAuthorizationPending = errors.New("authorization is pending")
+
+	// ErrInvalidIssuer is returned for a token from another issuer. It wraps
+	// ErrInvalidToken.
+	ErrInvalidIssuer = fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
 )
 
 // TokenType represents the type of token
@@ -42,20 +49,30 @@ const (
 // Config contains configuration for authentication
 type Config struct {
 	// JWT Configuration
-	JWTSecret         string          // Secret key for JWT signing (for HMAC algorithms)
-	JWTPrivateKey     *rsa.PrivateKey // Private key for JWT signing (for RSA algorithms)
-	JWTPublicKey      *rsa.PublicKey  // Public key for JWT verification (for RSA algorithms)
-	JWTSigningMethod  string          // Signing method (e.g., "HS256", "RS256")
-	JWTExpirationTime time.Duration   // Token expiration time
-	JWTIssuer         string          // Token issuer
+	JWTSecret          string          // Secret key for JWT signing (for HMAC algorithms)
+	JWTPrivateKey      *rsa.PrivateKey // Private key for JWT signing (for RSA algorithms)
+	JWTPublicKey       *rsa.PublicKey  // Public key for JWT verification (for RSA algorithms)
+	JWTSigningMethod   string          // Signing method (e.g., "HS256", "RS256")
+	JWTExpirationTime  time.Duration   // Token expiration time
+	JWTIssuer          string          // Token issuer
+	JWTSkipIssuerCheck bool            // Accept tokens from any issuer, for mixed-issuer setups
 
 	// OAuth2 Configuration
-	OAuth2ClientID     string   // OAuth2 client ID
-	OAuth2ClientSecret string   // OAuth2 client secret
-	OAuth2RedirectURL  string   // OAuth2 redirect URL
-	OAuth2AuthURL      string   // OAuth2 authorization URL
-	OAuth2TokenURL     string   // OAuth2 token URL
-	OAuth2Scopes       []string // OAuth2 scopes
+	OAuth2ClientID     string        // OAuth2 client ID
+	OAuth2ClientSecret string        // OAuth2 client secret
+	OAuth2RedirectURL  string        // OAuth2 redirect URL
+	OAuth2AuthURL      string        // OAuth2 authorization URL
+	OAuth2TokenURL     string        // OAuth2 token URL
+	OAuth2Scopes       []string      // OAuth2 scopes
+	OAuth2HTTPTimeout  time.Duration // Timeout for token endpoint requests (0 means no timeout)
+	OAuth2StateTTL     time.Duration // How long an issued authorization state stays valid (0 means 10 minutes)
+	OAuth2TokenCookie  string        // Cookie the callback stores the access token in (empty returns JSON)
+
+	OAuth2IntrospectionURL      string        // Token introspection endpoint (empty accepts any bearer token, for development)
+	OAuth2IntrospectionCacheTTL time.Duration // How long an active introspection result is reused (0 disables caching)
+
+	// Authorization Configuration
+	AdminScope string // Scope granting access to every scoped route (empty disables the bypass)
 }
 
 // Claims represents the JWT claims
@@ -74,13 +91,47 @@ type Authenticator struct {
 	jwtPublicKey     *rsa.PublicKey
 	jwtIssuer        string
 	jwtExpiration    time.Duration
+	jwtParser        *jwt.Parser
+
+	// verificationKeys holds additional keys selected by a token's kid header
+	keysMu           sync.RWMutex
+	verificationKeys map[string]interface{}
 
 	oauth2Config oauth2.Config
-	log          logger.Logger
+	httpClient   *http.Client
+	transport    http.RoundTripper
+	states       *stateStore
+	tokenCookie  string
+	adminScope   string
+
+	introspectionURL string
+	introspections   *introspectionCache
+
+	log logger.Logger
+
+	requests *prometheus.CounterVec
+}
+
+// Option configures optional dependencies of an Authenticator
+type Option func(*Authenticator)
+
+// WithMetrics counts authentication attempts by method and result against m
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(a *Authenticator) {
+		a.requests = m.NewCounter("auth_requests_total", "Total number of authentication attempts.", []string{"method", "result"})
+	}
+}
+
+// WithTransport sends the authenticator's requests to the OAuth2 provider,
+// such as token exchange and introspection, through rt
+func WithTransport(rt http.RoundTripper) Option {
+	return func(a *Authenticator) {
+		a.transport = rt
+	}
 }
 
 // NewAuthenticator creates a new authenticator instance
-func NewAuthenticator(config Config, log logger.Logger) (*Authenticator, error) {
+func NewAuthenticator(config Config, log logger.Logger, opts ...Option) (*Authenticator, error) {
 	var signingMethod jwt.SigningMethod
 
 	// Set JWT signing method based on configuration
@@ -113,16 +164,40 @@ func NewAuthenticator(config Config, log logger.Logger) (*Authenticator, error)
 		Scopes: config.OAuth2Scopes,
 	}
 
-	return &Authenticator{
+	a := &Authenticator{
 		jwtSigningMethod: signingMethod,
 		jwtSecret:        []byte(config.JWTSecret),
 		jwtPrivateKey:    config.JWTPrivateKey,
 		jwtPublicKey:     config.JWTPublicKey,
 		jwtIssuer:        config.JWTIssuer,
 		jwtExpiration:    config.JWTExpirationTime,
+		verificationKeys: make(map[string]interface{}),
 		oauth2Config:     oauth2Config,
+		states:           newStateStore(config.OAuth2StateTTL, maxOAuth2States),
+		tokenCookie:      config.OAuth2TokenCookie,
+		adminScope:       config.AdminScope,
+		introspectionURL: config.OAuth2IntrospectionURL,
+		introspections:   newIntrospectionCache(config.OAuth2IntrospectionCacheTTL),
 		log:              log,
-	}, nil
+	}
+
+	// Require tokens to come from this issuer unless configured otherwise
+	var parserOpts []jwt.ParserOption
+	if !config.JWTSkipIssuerCheck && config.JWTIssuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(config.JWTIssuer))
+	}
+	a.jwtParser = jwt.NewParser(parserOpts...)
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	if a.transport == nil {
+		a.transport = http.DefaultTransport
+	}
+	a.httpClient = newOAuth2HTTPClient(config.OAuth2HTTPTimeout, a.transport)
+
+	return a, nil
 }
 
 // GenerateJWTToken generates a new JWT token
@@ -166,10 +241,45 @@ func (a *Authenticator) GenerateJWTToken(userID string, roles, scopes []string)
 	return tokenString, nil
 }
 
-// VerifyJWTToken verifies a JWT token and returns the claims
+// AddVerificationKey registers a key used to verify tokens whose kid header
+// matches kid. HMAC keys are []byte (or string) and RSA keys are
+// *rsa.PublicKey. Registering keys for both the old and new kid lets signing
+// keys be rotated without invalidating tokens already issued.
+func (a *Authenticator) AddVerificationKey(kid string, key interface{}) {
+	if s, ok := key.(string); ok {
+		key = []byte(s)
+	}
+
+	a.keysMu.Lock()
+	defer a.keysMu.Unlock()
+
+	a.verificationKeys[kid] = key
+}
+
+// verificationKey returns the key registered for kid
+func (a *Authenticator) verificationKey(kid string) (interface{}, bool) {
+	a.keysMu.RLock()
+	defer a.keysMu.RUnlock()
+
+	key, ok := a.verificationKeys[kid]
+	return key, ok
+}
+
+// VerifyJWTToken verifies a JWT token and returns the claims. Unless the
+// issuer check is skipped, the token's iss claim must match the configured
+// issuer.
 func (a *Authenticator) VerifyJWTToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validate the signing method
+	token, err := a.jwtParser.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		// Select a registered key by the token's kid header
+		if kid, ok := token.Header["kid"].(string); ok && kid != "" {
+			key, found := a.verificationKey(kid)
+			if !found {
+				return nil, fmt.Errorf("unknown key id: %s", kid)
+			}
+			return key, nil
+		}
+
+		// Fall back to the configured key, validating the signing method
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
 			return a.jwtSecret, nil
 		}
@@ -183,6 +293,11 @@ func (a *Authenticator) VerifyJWTToken(tokenString string) (*Claims, error) {
 		if errors.Is(err, jwt.ErrTokenExpired) {
 			return nil, ErrExpiredToken
 		}
+		// iss is the only claim the parser requires, so a missing claim is a
+		// missing issuer
+		if errors.Is(err, jwt.ErrTokenInvalidIssuer) || errors.Is(err, jwt.ErrTokenRequiredClaimMissing) {
+			return nil, ErrInvalidIssuer
+		}
 		return nil, ErrInvalidToken
 	}
 
@@ -198,19 +313,37 @@ func (a *Authenticator) VerifyJWTToken(tokenString string) (*Claims, error) {
 	return claims, nil
 }
 
-// GetOAuth2AuthURL generates an OAuth2 authorization URL
+// GetOAuth2AuthURL generates an OAuth2 authorization URL and records state
+// so OAuth2CallbackHandler accepts it
 func (a *Authenticator) GetOAuth2AuthURL(state string) string {
+	a.states.add(state)
 	return a.oauth2Config.AuthCodeURL(state, oauth2.AccessTypeOnline)
 }
 
+// GetOAuth2AuthURLWithPKCE generates an OAuth2 authorization URL with a PKCE
+// S256 code challenge and records state like GetOAuth2AuthURL. The returned
+// verifier must be kept for the exchange.
+func (a *Authenticator) GetOAuth2AuthURLWithPKCE(state string) (authURL, verifier string) {
+	a.states.add(state)
+	verifier = oauth2.GenerateVerifier()
+	authURL = a.oauth2Config.AuthCodeURL(state, oauth2.AccessTypeOnline, oauth2.S256ChallengeOption(verifier))
+	return authURL, verifier
+}
+
+// ExchangeWithPKCE exchanges an authorization code for an OAuth2 token,
+// passing the PKCE code verifier
+func (a *Authenticator) ExchangeWithPKCE(ctx context.Context, code, verifier string) (*oauth2.Token, error) {
+	return a.oauth2Config.Exchange(a.oauth2Context(ctx), code, oauth2.VerifierOption(verifier))
+}
+
 // GetOAuth2Token exchanges an authorization code for an OAuth2 token
 func (a *Authenticator) GetOAuth2Token(ctx context.Context, code string) (*oauth2.Token, error) {
-	return a.oauth2Config.Exchange(ctx, code)
+	return a.oauth2Config.Exchange(a.oauth2Context(ctx), code)
 }
 
 // RefreshOAuth2Token refreshes an OAuth2 token
 func (a *Authenticator) RefreshOAuth2Token(ctx context.Context, token *oauth2.Token) (*oauth2.Token, error) {
-	source := a.oauth2Config.TokenSource(ctx, token)
+	source := a.oauth2Config.TokenSource(a.oauth2Context(ctx), token)
 	newToken, err := source.Token()
 	if err != nil {
 		return nil, err
@@ -221,7 +354,7 @@ func (a *Authenticator) RefreshOAuth2Token(ctx context.Context, token *oauth2.To
 
 // GetOAuth2Client returns an HTTP client with the OAuth2 token
 func (a *Authenticator) GetOAuth2Client(ctx context.Context, token *oauth2.Token) *http.Client {
-	return a.oauth2Config.Client(ctx, token)
+	return a.oauth2Config.Client(a.oauth2Context(ctx), token)
 }
 
 // ExtractBearerToken extracts a bearer token from the Authorization header
@@ -0,0 +1,787 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dBiTech/go-apiTemplate/internal/auth"
+	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+	"github.com/dBiTech/go-apiTemplate/pkg/metrics"
+)
+
+// newAuthenticator creates an HMAC authenticator for tests
+func newAuthenticator(t *testing.T) *auth.Authenticator {
+	t.Helper()
+
+	a, err := auth.NewAuthenticator(auth.Config{
+		JWTSecret:         "configured-secret",
+		JWTSigningMethod:  "HS256",
+		JWTExpirationTime: time.Hour,
+		JWTIssuer:         "test-issuer",
+	}, logger.Default())
+	require.NoError(t, err)
+
+	return a
+}
+
+// signToken signs a token for userID with the given method, key and kid,
+// issued by the issuer newAuthenticator configures
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, kid, userID string) string {
+	t.Helper()
+	return signTokenFrom(t, method, key, kid, userID, "test-issuer")
+}
+
+// signTokenFrom is like signToken but with the given issuer
+func signTokenFrom(t *testing.T, method jwt.SigningMethod, key interface{}, kid, userID, issuer string) string {
+	t.Helper()
+
+	now := time.Now()
+	token := jwt.NewWithClaims(method, auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    issuer,
+			Subject:   userID,
+		},
+		UserID: userID,
+	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func TestVerificationKeys(t *testing.T) {
+	// Test tokens signed under two different kids both validate
+	t.Run("TwoKids", func(t *testing.T) {
+		a := newAuthenticator(t)
+		a.AddVerificationKey("old", []byte("old-secret"))
+		a.AddVerificationKey("new", []byte("new-secret"))
+
+		claims, err := a.VerifyJWTToken(signToken(t, jwt.SigningMethodHS256, []byte("old-secret"), "old", "user-old"))
+		require.NoError(t, err)
+		assert.Equal(t, "user-old", claims.UserID)
+
+		claims, err = a.VerifyJWTToken(signToken(t, jwt.SigningMethodHS256, []byte("new-secret"), "new", "user-new"))
+		require.NoError(t, err)
+		assert.Equal(t, "user-new", claims.UserID)
+	})
+
+	// Test RSA keys can be registered by kid
+	t.Run("RSAKid", func(t *testing.T) {
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		a := newAuthenticator(t)
+		a.AddVerificationKey("rsa", &privateKey.PublicKey)
+
+		claims, err := a.VerifyJWTToken(signToken(t, jwt.SigningMethodRS256, privateKey, "rsa", "user-rsa"))
+		require.NoError(t, err)
+		assert.Equal(t, "user-rsa", claims.UserID)
+	})
+
+	// Test tokens without a kid fall back to the configured key
+	t.Run("NoKidFallback", func(t *testing.T) {
+		a := newAuthenticator(t)
+		a.AddVerificationKey("new", []byte("new-secret"))
+
+		token, err := a.GenerateJWTToken("user-1", nil, nil)
+		require.NoError(t, err)
+
+		claims, err := a.VerifyJWTToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", claims.UserID)
+	})
+
+	// Test an unknown kid is rejected
+	t.Run("UnknownKid", func(t *testing.T) {
+		a := newAuthenticator(t)
+		a.AddVerificationKey("new", []byte("new-secret"))
+
+		_, err := a.VerifyJWTToken(signToken(t, jwt.SigningMethodHS256, []byte("configured-secret"), "retired", "user-1"))
+		assert.ErrorIs(t, err, auth.ErrInvalidToken)
+	})
+
+	// Test a token signed with the wrong key for its kid is rejected
+	t.Run("WrongKeyForKid", func(t *testing.T) {
+		a := newAuthenticator(t)
+		a.AddVerificationKey("old", []byte("old-secret"))
+		a.AddVerificationKey("new", []byte("new-secret"))
+
+		_, err := a.VerifyJWTToken(signToken(t, jwt.SigningMethodHS256, []byte("old-secret"), "new", "user-1"))
+		assert.ErrorIs(t, err, auth.ErrInvalidToken)
+	})
+}
+
+func TestJWTIssuer(t *testing.T) {
+	secret := []byte("configured-secret")
+
+	// Test a token from the configured issuer is accepted
+	t.Run("Matching", func(t *testing.T) {
+		a := newAuthenticator(t)
+
+		claims, err := a.VerifyJWTToken(signTokenFrom(t, jwt.SigningMethodHS256, secret, "", "user-1", "test-issuer"))
+		require.NoError(t, err)
+		assert.Equal(t, "test-issuer", claims.Issuer)
+	})
+
+	// Test a token from another issuer, or none, is rejected even with the right key
+	t.Run("Mismatched", func(t *testing.T) {
+		a := newAuthenticator(t)
+
+		for _, issuer := range []string{"other-issuer", ""} {
+			_, err := a.VerifyJWTToken(signTokenFrom(t, jwt.SigningMethodHS256, secret, "", "user-1", issuer))
+			assert.ErrorIs(t, err, auth.ErrInvalidIssuer, issuer)
+			assert.ErrorIs(t, err, auth.ErrInvalidToken, issuer)
+		}
+	})
+
+	// Test the check can be skipped for mixed-issuer setups
+	t.Run("Skipped", func(t *testing.T) {
+		a, err := auth.NewAuthenticator(auth.Config{
+			JWTSecret:          string(secret),
+			JWTSigningMethod:   "HS256",
+			JWTExpirationTime:  time.Hour,
+			JWTIssuer:          "test-issuer",
+			JWTSkipIssuerCheck: true,
+		}, logger.Default())
+		require.NoError(t, err)
+
+		claims, err := a.VerifyJWTToken(signTokenFrom(t, jwt.SigningMethodHS256, secret, "", "user-1", "other-issuer"))
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", claims.UserID)
+	})
+}
+
+func TestPKCE(t *testing.T) {
+	// newOAuth2Authenticator creates an authenticator using the given token URL
+	newOAuth2Authenticator := func(t *testing.T, tokenURL string) *auth.Authenticator {
+		t.Helper()
+
+		a, err := auth.NewAuthenticator(auth.Config{
+			OAuth2ClientID:    "test-client-id",
+			OAuth2RedirectURL: "http://localhost:8080/auth/callback",
+			OAuth2AuthURL:     "https://auth.example.com/authorize",
+			OAuth2TokenURL:    tokenURL,
+			OAuth2Scopes:      []string{"read"},
+		}, logger.Default())
+		require.NoError(t, err)
+
+		return a
+	}
+
+	// Test the URL carries the S256 challenge of the verifier
+	t.Run("AuthURL", func(t *testing.T) {
+		a := newOAuth2Authenticator(t, "https://auth.example.com/token")
+
+		authURL, verifier := a.GetOAuth2AuthURLWithPKCE("test-state")
+		require.NotEmpty(t, verifier)
+
+		u, err := url.Parse(authURL)
+		require.NoError(t, err)
+
+		sum := sha256.Sum256([]byte(verifier))
+		query := u.Query()
+		assert.Equal(t, base64.RawURLEncoding.EncodeToString(sum[:]), query.Get("code_challenge"))
+		assert.Equal(t, "S256", query.Get("code_challenge_method"))
+		assert.Equal(t, "test-state", query.Get("state"))
+
+		// Each URL gets a fresh verifier
+		_, other := a.GetOAuth2AuthURLWithPKCE("test-state")
+		assert.NotEqual(t, verifier, other)
+	})
+
+	// Test the verifier is sent on exchange
+	t.Run("Exchange", func(t *testing.T) {
+		var gotVerifier string
+		tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = r.ParseForm()
+			gotVerifier = r.PostForm.Get("code_verifier")
+
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token":"test-token","token_type":"Bearer"}`))
+		}))
+		defer tokenServer.Close()
+
+		a := newOAuth2Authenticator(t, tokenServer.URL)
+
+		token, err := a.ExchangeWithPKCE(context.Background(), "test-code", "test-verifier")
+		require.NoError(t, err)
+		assert.Equal(t, "test-token", token.AccessToken)
+		assert.Equal(t, "test-verifier", gotVerifier)
+	})
+}
+
+func TestAdminScope(t *testing.T) {
+	// serveWithScopes runs a token with scopes through a route requiring "read"
+	serveWithScopes := func(t *testing.T, adminScope string, scopes []string) int {
+		t.Helper()
+
+		a, err := auth.NewAuthenticator(auth.Config{
+			JWTSecret:         "configured-secret",
+			JWTSigningMethod:  "HS256",
+			JWTExpirationTime: time.Hour,
+			AdminScope:        adminScope,
+		}, logger.Default())
+		require.NoError(t, err)
+
+		token, err := a.GenerateJWTToken("user-1", nil, scopes)
+		require.NoError(t, err)
+
+		handler := a.JWTAuthMiddleware([]string{"read"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		return w.Code
+	}
+
+	// Test the admin scope grants nothing when the bypass is disabled
+	t.Run("BypassDisabled", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, serveWithScopes(t, "", []string{"admin"}))
+		assert.Equal(t, http.StatusOK, serveWithScopes(t, "", []string{"read"}))
+	})
+
+	// Test a custom admin scope grants access and "admin" no longer does
+	t.Run("CustomScope", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serveWithScopes(t, "superuser", []string{"superuser"}))
+		assert.Equal(t, http.StatusForbidden, serveWithScopes(t, "superuser", []string{"admin"}))
+	})
+}
+
+func TestOAuth2HTTPClient(t *testing.T) {
+	// newOAuth2Authenticator creates an authenticator using tokenURL with timeout
+	newOAuth2Authenticator := func(t *testing.T, tokenURL string, timeout time.Duration) *auth.Authenticator {
+		t.Helper()
+
+		a, err := auth.NewAuthenticator(auth.Config{
+			JWTSecret:          "configured-secret",
+			OAuth2ClientID:     "client",
+			OAuth2ClientSecret: "secret",
+			OAuth2AuthURL:      tokenURL,
+			OAuth2TokenURL:     tokenURL,
+			OAuth2HTTPTimeout:  timeout,
+		}, logger.Default())
+		require.NoError(t, err)
+
+		return a
+	}
+
+	// Test a hung token endpoint fails the exchange with a timeout
+	t.Run("Timeout", func(t *testing.T) {
+		release := make(chan struct{})
+		tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer tokenServer.Close()
+		defer close(release)
+
+		a := newOAuth2Authenticator(t, tokenServer.URL, 100*time.Millisecond)
+
+		start := time.Now()
+		_, err := a.GetOAuth2Token(context.Background(), "code")
+		require.Error(t, err)
+		assert.Less(t, time.Since(start), 2*time.Second)
+
+		var netErr net.Error
+		require.ErrorAs(t, err, &netErr)
+		assert.True(t, netErr.Timeout())
+	})
+
+	// Test server errors from the token endpoint are retried
+	t.Run("RetryOn5xx", func(t *testing.T) {
+		var calls atomic.Int32
+		tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = r.ParseForm()
+			if calls.Add(1) == 1 || r.PostForm.Get("code") != "code" {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token":"token","token_type":"Bearer"}`))
+		}))
+		defer tokenServer.Close()
+
+		a := newOAuth2Authenticator(t, tokenServer.URL, 5*time.Second)
+
+		token, err := a.GetOAuth2Token(context.Background(), "code")
+		require.NoError(t, err)
+		assert.Equal(t, "token", token.AccessToken)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+}
+
+func TestOAuth2Introspection(t *testing.T) {
+	var calls atomic.Int32
+	introspectionServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_ = r.ParseForm()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.PostForm.Get("token") {
+		case "active-token":
+			_ = json.NewEncoder(w).Encode(auth.IntrospectionResult{
+				Active:    true,
+				Scope:     "read write",
+				Subject:   "introspected-user",
+				ExpiresAt: time.Now().Add(time.Hour).Unix(),
+			})
+		default:
+			_ = json.NewEncoder(w).Encode(auth.IntrospectionResult{Active: false})
+		}
+	}))
+	defer introspectionServer.Close()
+
+	// newIntrospectingAuthenticator creates an authenticator whose
+	// introspection results are cached for cacheTTL
+	newIntrospectingAuthenticator := func(t *testing.T, cacheTTL time.Duration) *auth.Authenticator {
+		t.Helper()
+
+		a, err := auth.NewAuthenticator(auth.Config{
+			JWTSecret:                   "configured-secret",
+			OAuth2ClientID:              "client",
+			OAuth2ClientSecret:          "secret",
+			OAuth2IntrospectionURL:      introspectionServer.URL,
+			OAuth2IntrospectionCacheTTL: cacheTTL,
+		}, logger.Default())
+		require.NoError(t, err)
+
+		return a
+	}
+
+	// serve sends a request with token through the OAuth2 middleware and
+	// returns the status and the user ID the handler saw
+	serve := func(a *auth.Authenticator, token string) (int, string) {
+		var userID string
+		handler := a.OAuth2AuthMiddleware([]string{"read"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, _ = auth.GetUserID(r.Context())
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code, userID
+	}
+
+	// Test a second request within the TTL is answered from the cache
+	t.Run("Cached", func(t *testing.T) {
+		calls.Store(0)
+		a := newIntrospectingAuthenticator(t, time.Minute)
+
+		status, userID := serve(a, "active-token")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "introspected-user", userID)
+
+		status, _ = serve(a, "active-token")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	// Test an expired cache entry is introspected again
+	t.Run("Expired", func(t *testing.T) {
+		calls.Store(0)
+		a := newIntrospectingAuthenticator(t, 50*time.Millisecond)
+
+		status, _ := serve(a, "active-token")
+		assert.Equal(t, http.StatusOK, status)
+
+		time.Sleep(100 * time.Millisecond)
+
+		status, _ = serve(a, "active-token")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	// Test inactive tokens are rejected and not cached
+	t.Run("Inactive", func(t *testing.T) {
+		calls.Store(0)
+		a := newIntrospectingAuthenticator(t, time.Minute)
+
+		status, _ := serve(a, "revoked-token")
+		assert.Equal(t, http.StatusUnauthorized, status)
+
+		status, _ = serve(a, "revoked-token")
+		assert.Equal(t, http.StatusUnauthorized, status)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	// Test an unreachable provider isn't reported as a bad token
+	t.Run("ProviderDown", func(t *testing.T) {
+		down := httptest.NewServer(http.NotFoundHandler())
+		down.Close()
+
+		a, err := auth.NewAuthenticator(auth.Config{
+			JWTSecret:              "configured-secret",
+			OAuth2IntrospectionURL: down.URL,
+		}, logger.Default())
+		require.NoError(t, err)
+
+		status, _ := serve(a, "active-token")
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+	})
+}
+
+func TestOAuth2Callback(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.PostForm.Get("code") != "good-code" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"access","token_type":"Bearer","expires_in":3600,"refresh_token":"refresh","scope":"read"}`))
+	}))
+	defer tokenServer.Close()
+
+	// newCallbackAuthenticator creates an authenticator exchanging codes with
+	// the test token server
+	newCallbackAuthenticator := func(t *testing.T, cookie string) *auth.Authenticator {
+		t.Helper()
+
+		a, err := auth.NewAuthenticator(auth.Config{
+			JWTSecret:         "configured-secret",
+			OAuth2ClientID:    "client",
+			OAuth2AuthURL:     tokenServer.URL + "/authorize",
+			OAuth2TokenURL:    tokenServer.URL + "/token",
+			OAuth2TokenCookie: cookie,
+		}, logger.Default())
+		require.NoError(t, err)
+
+		return a
+	}
+
+	// callback calls the callback handler with the given query
+	callback := func(a *auth.Authenticator, query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		a.OAuth2CallbackHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/callback?"+query, nil))
+		return w
+	}
+
+	// Test a callback with an issued state returns the token
+	t.Run("Valid", func(t *testing.T) {
+		a := newCallbackAuthenticator(t, "")
+		a.GetOAuth2AuthURL("state-1")
+
+		w := callback(a, "state=state-1&code=good-code")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var resp auth.OAuth2Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "access", resp.AccessToken)
+		assert.Equal(t, "refresh", resp.RefreshToken)
+		assert.Equal(t, "read", resp.Scope)
+		assert.InDelta(t, 3600, resp.ExpiresIn, 5)
+	})
+
+	// Test a state that wasn't issued is rejected
+	t.Run("StateMismatch", func(t *testing.T) {
+		a := newCallbackAuthenticator(t, "")
+		a.GetOAuth2AuthURL("state-1")
+
+		w := callback(a, "state=other&code=good-code")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	// Test a state can only be used once
+	t.Run("StateReused", func(t *testing.T) {
+		a := newCallbackAuthenticator(t, "")
+		a.GetOAuth2AuthURL("state-1")
+
+		require.Equal(t, http.StatusOK, callback(a, "state=state-1&code=good-code").Code)
+		assert.Equal(t, http.StatusBadRequest, callback(a, "state=state-1&code=good-code").Code)
+	})
+
+	// Test a code the provider rejects is unauthorized
+	t.Run("BadCode", func(t *testing.T) {
+		a := newCallbackAuthenticator(t, "")
+		a.GetOAuth2AuthURL("state-1")
+
+		w := callback(a, "state=state-1&code=bad-code")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	// Test the token is set as a secure cookie when configured
+	t.Run("Cookie", func(t *testing.T) {
+		a := newCallbackAuthenticator(t, "access_token")
+		a.GetOAuth2AuthURL("state-1")
+
+		w := callback(a, "state=state-1&code=good-code")
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		cookies := w.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, "access_token", cookies[0].Name)
+		assert.Equal(t, "access", cookies[0].Value)
+		assert.True(t, cookies[0].Secure)
+		assert.True(t, cookies[0].HttpOnly)
+	})
+
+	// Test a state issued with a PKCE authorization URL is accepted
+	t.Run("PKCEState", func(t *testing.T) {
+		a := newCallbackAuthenticator(t, "")
+		a.GetOAuth2AuthURLWithPKCE("state-1")
+
+		w := callback(a, "state=state-1&code=good-code")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	// Test the login handler redirects with a state the callback accepts
+	t.Run("Login", func(t *testing.T) {
+		a := newCallbackAuthenticator(t, "")
+
+		w := httptest.NewRecorder()
+		a.OAuth2LoginHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/login", nil))
+		require.Equal(t, http.StatusFound, w.Code)
+
+		location, err := url.Parse(w.Header().Get("Location"))
+		require.NoError(t, err)
+		state := location.Query().Get("state")
+		require.NotEmpty(t, state)
+
+		assert.Equal(t, http.StatusOK, callback(a, "state="+state+"&code=good-code").Code)
+	})
+}
+
+func TestVerifyTokenHandler(t *testing.T) {
+	a := newAuthenticator(t)
+
+	// verify posts to the handler with the given Authorization header
+	verify := func(authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/auth/verify", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		a.VerifyTokenHandler().ServeHTTP(w, req)
+		return w
+	}
+
+	// Test a valid token's claims are returned
+	t.Run("Valid", func(t *testing.T) {
+		token, err := a.GenerateJWTToken("user-1", []string{"user"}, []string{"read"})
+		require.NoError(t, err)
+
+		w := verify("Bearer " + token)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var claims auth.Claims
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &claims))
+		assert.Equal(t, "user-1", claims.UserID)
+		assert.Equal(t, []string{"user"}, claims.Roles)
+		assert.Equal(t, []string{"read"}, claims.Scopes)
+		assert.Equal(t, "test-issuer", claims.Issuer)
+	})
+
+	// Test an expired token is rejected as expired
+	t.Run("Expired", func(t *testing.T) {
+		expired := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+				Issuer:    "test-issuer",
+			},
+			UserID: "user-1",
+		})
+		token, err := expired.SignedString([]byte("configured-secret"))
+		require.NoError(t, err)
+
+		w := verify("Bearer " + token)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "Token expired")
+	})
+
+	// Test missing and malformed tokens are rejected
+	t.Run("Invalid", func(t *testing.T) {
+		w := verify("")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "Missing token")
+
+		w = verify("Bearer not-a-jwt")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid token")
+	})
+}
+
+func TestOAuth2TokenHandler(t *testing.T) {
+	a, err := auth.NewAuthenticator(auth.Config{
+		JWTSecret:          "configured-secret",
+		JWTExpirationTime:  time.Hour,
+		OAuth2ClientID:     "service",
+		OAuth2ClientSecret: "service-secret",
+		OAuth2Scopes:       []string{"read", "write"},
+	}, logger.Default())
+	require.NoError(t, err)
+
+	// token requests a token with the given form, authenticating with
+	// basic auth unless clientID is empty
+	token := func(form url.Values, clientID, clientSecret string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/auth/oauth2/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if clientID != "" {
+			req.SetBasicAuth(clientID, clientSecret)
+		}
+		w := httptest.NewRecorder()
+		a.OAuth2TokenHandler().ServeHTTP(w, req)
+		return w
+	}
+
+	grant := url.Values{"grant_type": {"client_credentials"}}
+
+	// Test valid client credentials receive a JWT with the configured scopes
+	t.Run("Valid", func(t *testing.T) {
+		w := token(grant, "service", "service-secret")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+		var resp auth.OAuth2Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "Bearer", resp.TokenType)
+		assert.Equal(t, 3600, resp.ExpiresIn)
+		assert.Equal(t, "read write", resp.Scope)
+
+		claims, err := a.VerifyJWTToken(resp.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, "service", claims.UserID)
+		assert.Equal(t, []string{"read", "write"}, claims.Scopes)
+	})
+
+	// Test credentials sent in the form are accepted and scopes can be narrowed
+	t.Run("FormCredentials", func(t *testing.T) {
+		form := url.Values{
+			"grant_type":    {"client_credentials"},
+			"client_id":     {"service"},
+			"client_secret": {"service-secret"},
+			"scope":         {"read"},
+		}
+		w := token(form, "", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp auth.OAuth2Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "read", resp.Scope)
+	})
+
+	// Test an invalid secret is rejected
+	t.Run("InvalidSecret", func(t *testing.T) {
+		w := token(grant, "service", "wrong-secret")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	// Test a scope beyond the configured ones is rejected
+	t.Run("InvalidScope", func(t *testing.T) {
+		form := url.Values{"grant_type": {"client_credentials"}, "scope": {"admin"}}
+		w := token(form, "service", "service-secret")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	// Test other grant types are rejected
+	t.Run("UnsupportedGrant", func(t *testing.T) {
+		w := token(url.Values{"grant_type": {"password"}}, "service", "service-secret")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestAuthMetrics(t *testing.T) {
+	m := metrics.NewMetrics("test")
+	a, err := auth.NewAuthenticator(auth.Config{
+		JWTSecret:         "configured-secret",
+		JWTSigningMethod:  "HS256",
+		JWTExpirationTime: time.Hour,
+	}, logger.Default(), auth.WithMetrics(m))
+	require.NoError(t, err)
+
+	// serve sends a request with the Authorization header through middleware
+	serve := func(middleware func(http.Handler) http.Handler, authorization string) int {
+		handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// scrape returns the metrics exposition text
+	scrape := func(t *testing.T) string {
+		t.Helper()
+		w := httptest.NewRecorder()
+		m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		return w.Body.String()
+	}
+
+	readToken, err := a.GenerateJWTToken("user-1", nil, []string{"read"})
+	require.NoError(t, err)
+
+	expired := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+		},
+		UserID: "user-1",
+	})
+	expiredToken, err := expired.SignedString([]byte("configured-secret"))
+	require.NoError(t, err)
+
+	jwtRead := a.JWTAuthMiddleware([]string{"read"})
+	jwtWrite := a.JWTAuthMiddleware([]string{"write"})
+	oauth2Read := a.OAuth2AuthMiddleware([]string{"read"})
+	oauth2Admin := a.OAuth2AuthMiddleware([]string{"admin"})
+
+	// Test each outcome is served and counted under its result label
+	tests := []struct {
+		name       string
+		middleware func(http.Handler) http.Handler
+		header     string
+		status     int
+		series     string
+	}{
+		{"JWTOK", jwtRead, "Bearer " + readToken, http.StatusOK, `test_auth_requests_total{method="jwt",result="ok"} 1`},
+		{"JWTMissing", jwtRead, "", http.StatusUnauthorized, `test_auth_requests_total{method="jwt",result="missing"} 1`},
+		{"JWTInvalid", jwtRead, "Bearer not-a-token", http.StatusUnauthorized, `test_auth_requests_total{method="jwt",result="invalid"} 1`},
+		{"JWTExpired", jwtRead, "Bearer " + expiredToken, http.StatusUnauthorized, `test_auth_requests_total{method="jwt",result="expired"} 1`},
+		{"JWTForbidden", jwtWrite, "Bearer " + readToken, http.StatusForbidden, `test_auth_requests_total{method="jwt",result="forbidden"} 1`},
+		{"OAuth2OK", oauth2Read, "Bearer opaque", http.StatusOK, `test_auth_requests_total{method="oauth2",result="ok"} 1`},
+		{"OAuth2Missing", oauth2Read, "", http.StatusUnauthorized, `test_auth_requests_total{method="oauth2",result="missing"} 1`},
+		{"OAuth2Invalid", oauth2Read, "Basic abc", http.StatusUnauthorized, `test_auth_requests_total{method="oauth2",result="invalid"} 1`},
+		{"OAuth2Forbidden", oauth2Admin, "Bearer opaque", http.StatusForbidden, `test_auth_requests_total{method="oauth2",result="forbidden"} 1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.status, serve(tt.middleware, tt.header))
+			assert.Contains(t, scrape(t), tt.series)
+		})
+	}
+}
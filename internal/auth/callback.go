@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+)
+
+// ConsumeOAuth2State reports whether state was issued by GetOAuth2AuthURL or
+// GetOAuth2AuthURLWithPKCE and hasn't expired. A state can only be consumed
+// once.
+func (a *Authenticator) ConsumeOAuth2State(state string) bool {
+	if state == "" {
+		return false
+	}
+	return a.states.consume(state)
+}
+
+// OAuth2LoginHandler starts the authorization code flow by redirecting to the
+// provider with a freshly issued state
+func (a *Authenticator) OAuth2LoginHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		state, err := newOAuth2State()
+		if err != nil {
+			a.log.Error("failed to generate OAuth2 state", logger.Error(err))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		http.Redirect(w, r, a.GetOAuth2AuthURL(state), http.StatusFound)
+	}
+}
+
+// OAuth2CallbackHandler completes the authorization code flow. It checks the
+// state against those issued with authorization URLs and exchanges the code for a
+// token. The token is set as a secure cookie when a cookie name is configured
+// and returned as JSON otherwise.
+func (a *Authenticator) OAuth2CallbackHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		if providerErr := query.Get("error"); providerErr != "" {
+			a.log.Debug("OAuth2 authorization denied", logger.String("error", providerErr))
+			http.Error(w, "Authorization failed: "+providerErr, http.StatusBadRequest)
+			return
+		}
+
+		if !a.ConsumeOAuth2State(query.Get("state")) {
+			a.log.Debug("OAuth2 callback state mismatch")
+			http.Error(w, "Invalid state", http.StatusBadRequest)
+			return
+		}
+
+		code := query.Get("code")
+		if code == "" {
+			http.Error(w, "Missing code", http.StatusBadRequest)
+			return
+		}
+
+		token, err := a.GetOAuth2Token(r.Context(), code)
+		if err != nil {
+			a.log.Debug("OAuth2 code exchange failed", logger.Error(err))
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		if a.tokenCookie != "" {
+			http.SetCookie(w, &http.Cookie{
+				Name:     a.tokenCookie,
+				Value:    token.AccessToken,
+				Path:     "/",
+				Expires:  token.Expiry,
+				Secure:   true,
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		response := OAuth2Response{
+			AccessToken:  token.AccessToken,
+			TokenType:    token.TokenType,
+			RefreshToken: token.RefreshToken,
+		}
+		if !token.Expiry.IsZero() {
+			response.ExpiresIn = int(time.Until(token.Expiry).Seconds())
+		}
+		if scope, ok := token.Extra("scope").(string); ok {
+			response.Scope = scope
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			a.log.Error("failed to write OAuth2 token response", logger.Error(err))
+		}
+	}
+}
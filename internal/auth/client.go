@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	// oauth2MaxRetries is how many times a token request is retried on a 5xx response
+	oauth2MaxRetries = 2
+
+	// oauth2RetryBackoff is the delay before the first retry, doubled for each retry after
+	oauth2RetryBackoff = 100 * time.Millisecond
+)
+
+// newOAuth2HTTPClient creates the client used for token endpoint requests,
+// sending each attempt through next. The timeout bounds the whole call,
+// retries included.
+func newOAuth2HTTPClient(timeout time.Duration, next http.RoundTripper) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &retryTransport{
+			next:       next,
+			maxRetries: oauth2MaxRetries,
+			backoff:    oauth2RetryBackoff,
+		},
+	}
+}
+
+// oauth2Context returns ctx carrying the authenticator's HTTP client, which
+// the oauth2 package uses for exchange and refresh requests. A client already
+// set on ctx by the caller is kept.
+func (a *Authenticator) oauth2Context(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(oauth2.HTTPClient).(*http.Client); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, oauth2.HTTPClient, a.httpClient)
+}
+
+// retryTransport retries requests that receive a 5xx response
+type retryTransport struct {
+	next       http.RoundTripper
+	maxRetries int
+	backoff    time.Duration
+}
+
+// RoundTrip sends the request, retrying server errors with exponential backoff
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	backoff := t.backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if err != nil || resp.StatusCode < http.StatusInternalServerError || attempt >= t.maxRetries {
+			return resp, err
+		}
+
+		// The body has been consumed, so only retry if it can be replayed
+		if req.Body != nil && req.GetBody == nil {
+			return resp, nil
+		}
+		_ = resp.Body.Close()
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IntrospectionResult is an OAuth2 token introspection response (RFC 7662)
+type IntrospectionResult struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+}
+
+// Scopes returns the result's space-separated scope as a slice
+func (r *IntrospectionResult) Scopes() []string {
+	return strings.Fields(r.Scope)
+}
+
+// IntrospectOAuth2Token asks the configured introspection endpoint whether
+// token is active. It returns ErrInvalidToken for an inactive token and
+// ErrExpiredToken for one whose exp has passed. Active results are cached
+// until the token expires or the cache TTL elapses, whichever is sooner.
+func (a *Authenticator) IntrospectOAuth2Token(ctx context.Context, token string) (*IntrospectionResult, error) {
+	if result, ok := a.introspections.get(token); ok {
+		return result, nil
+	}
+
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.introspectionURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create introspection request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(a.oauth2Config.ClientID), url.QueryEscape(a.oauth2Config.ClientSecret))
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("introspection request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspection endpoint returned %d", resp.StatusCode)
+	}
+
+	var result IntrospectionResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode introspection response: %w", err)
+	}
+
+	if !result.Active {
+		return nil, ErrInvalidToken
+	}
+	if result.ExpiresAt != 0 && !time.Now().Before(time.Unix(result.ExpiresAt, 0)) {
+		return nil, ErrExpiredToken
+	}
+
+	a.introspections.put(token, &result)
+	return &result, nil
+}
+
+// introspectionCache remembers active introspection results so the provider
+// isn't called on every request. Tokens are stored hashed.
+type introspectionCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]introspectionEntry
+}
+
+// introspectionEntry is a cached result and when it stops being valid
+type introspectionEntry struct {
+	result  *IntrospectionResult
+	expires time.Time
+}
+
+// newIntrospectionCache creates a cache whose entries live at most ttl; a
+// ttl of 0 or less disables caching
+func newIntrospectionCache(ttl time.Duration) *introspectionCache {
+	return &introspectionCache{
+		ttl:     ttl,
+		entries: make(map[string]introspectionEntry),
+	}
+}
+
+// get returns the cached result for token if it hasn't expired
+func (c *introspectionCache) get(token string) (*IntrospectionResult, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := hashToken(token)
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !time.Now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.result, true
+}
+
+// put caches result until the token's exp or the cache TTL, whichever is
+// sooner, dropping any entries that have expired
+func (c *introspectionCache) put(token string, result *IntrospectionResult) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	now := time.Now()
+	expires := now.Add(c.ttl)
+	if result.ExpiresAt != 0 {
+		if exp := time.Unix(result.ExpiresAt, 0); exp.Before(expires) {
+			expires = exp
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+
+	c.entries[hashToken(token)] = introspectionEntry{result: result, expires: expires}
+}
+
+// hashToken returns the SHA-256 of token, so cached tokens can't be read back
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
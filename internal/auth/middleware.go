@@ -2,6 +2,8 @@ package auth
 
 import (
 	"context"
+	"crypto/subtle"
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -22,6 +24,19 @@ const (
 	UserIDContextKey ContextKey = "user_id"
 )
 
+// Label values for the auth_requests_total counter
+const (
+	authMethodJWT    = "jwt"
+	authMethodOAuth2 = "oauth2"
+
+	authResultOK        = "ok"
+	authResultMissing   = "missing"
+	authResultInvalid   = "invalid"
+	authResultExpired   = "expired"
+	authResultForbidden = "forbidden"
+	authResultError     = "error"
+)
+
 // JWTAuthMiddleware creates a middleware that requires a valid JWT token
 func (a *Authenticator) JWTAuthMiddleware(requiredScopes []string) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -30,6 +45,7 @@ func (a *Authenticator) JWTAuthMiddleware(requiredScopes []string) func(next htt
 			token, err := ExtractBearerToken(r)
 			if err != nil {
 				a.log.Debug("JWT auth failed", logger.Error(err))
+				a.observe(authMethodJWT, extractFailureResult(err))
 				http.Error(w, "Unauthorized", http.StatusUnauthorized)
 				return
 			}
@@ -40,8 +56,10 @@ func (a *Authenticator) JWTAuthMiddleware(requiredScopes []string) func(next htt
 				a.log.Debug("JWT verification failed", logger.Error(err))
 
 				if err == ErrExpiredToken {
+					a.observe(authMethodJWT, authResultExpired)
 					http.Error(w, "Token expired", http.StatusUnauthorized)
 				} else {
+					a.observe(authMethodJWT, authResultInvalid)
 					http.Error(w, "Unauthorized", http.StatusUnauthorized)
 				}
 				return
@@ -49,24 +67,12 @@ func (a *Authenticator) JWTAuthMiddleware(requiredScopes []string) func(next htt
 
 			// Check scopes if required
 			if len(requiredScopes) > 0 {
-				hasScope := false
-				for _, requiredScope := range requiredScopes {
-					for _, tokenScope := range claims.Scopes {
-						if tokenScope == requiredScope || tokenScope == "admin" {
-							hasScope = true
-							break
-						}
-					}
-					if hasScope {
-						break
-					}
-				}
-
-				if !hasScope {
+				if !a.hasAnyScope(claims.Scopes, requiredScopes) {
 					a.log.Debug("Insufficient scope",
 						logger.String("required", strings.Join(requiredScopes, ",")),
 						logger.String("provided", strings.Join(claims.Scopes, ",")),
 					)
+					a.observe(authMethodJWT, authResultForbidden)
 					http.Error(w, "Forbidden: insufficient scope", http.StatusForbidden)
 					return
 				}
@@ -76,6 +82,7 @@ func (a *Authenticator) JWTAuthMiddleware(requiredScopes []string) func(next htt
 			ctx := context.WithValue(r.Context(), ClaimsContextKey, claims)
 			ctx = context.WithValue(ctx, ScopesContextKey, claims.Scopes)
 			ctx = context.WithValue(ctx, UserIDContextKey, claims.UserID)
+			a.observe(authMethodJWT, authResultOK)
 
 			// Proceed with the next handler
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -83,54 +90,79 @@ func (a *Authenticator) JWTAuthMiddleware(requiredScopes []string) func(next htt
 	}
 }
 
+// BasicAuthMiddleware creates a middleware that requires the given basic auth
+// credentials, challenging clients with realm when they are missing or wrong
+func BasicAuthMiddleware(realm, username, password string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			basic, err := ExtractBasicAuth(r)
+			if err != nil ||
+				subtle.ConstantTimeCompare([]byte(basic.Username), []byte(username))&
+					subtle.ConstantTimeCompare([]byte(basic.Password), []byte(password)) != 1 {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q", realm))
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // OAuth2AuthMiddleware creates a middleware that requires a valid OAuth2 token
 func (a *Authenticator) OAuth2AuthMiddleware(requiredScopes []string) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Extract token from Authorization header
-			_, err := ExtractBearerToken(r)
+			token, err := ExtractBearerToken(r)
 			if err != nil {
 				a.log.Debug("OAuth2 auth failed", logger.Error(err))
+				a.observe(authMethodOAuth2, extractFailureResult(err))
 				http.Error(w, "Unauthorized", http.StatusUnauthorized)
 				return
 			}
 
-			// In a real application, you would validate the token with the OAuth2 provider
-			// For this template, we'll use a simple introspection simulation
-
 			// Get the request context
 			ctx := r.Context()
 
-			// Here you would make a request to the OAuth2 introspection endpoint using a client
-			// For example: httpClient := &http.Client{}
-			// response, err := httpClient.Post(introspectionURL, "application/x-www-form-urlencoded", formData)
-
-			// For the template, we'll assume the token is valid and has certain scopes
-
-			// Example scopes (in a real app, these would come from the introspection response)
+			// Without an introspection endpoint any token is accepted with
+			// example scopes, so the template works without a provider
 			scopes := []string{"read", "write"} // Example scopes
 			userID := "oauth2-user-123"         // Example user ID
 
-			// Check required scopes
-			if len(requiredScopes) > 0 {
-				hasScope := false
-				for _, requiredScope := range requiredScopes {
-					for _, tokenScope := range scopes {
-						if tokenScope == requiredScope || tokenScope == "admin" {
-							hasScope = true
-							break
-						}
-					}
-					if hasScope {
-						break
+			if a.introspectionURL != "" {
+				result, err := a.IntrospectOAuth2Token(ctx, token)
+				if err != nil {
+					a.log.Debug("OAuth2 introspection failed", logger.Error(err))
+
+					switch err {
+					case ErrExpiredToken:
+						a.observe(authMethodOAuth2, authResultExpired)
+						http.Error(w, "Token expired", http.StatusUnauthorized)
+					case ErrInvalidToken:
+						a.observe(authMethodOAuth2, authResultInvalid)
+						http.Error(w, "Unauthorized", http.StatusUnauthorized)
+					default:
+						// The provider couldn't answer, which says nothing
+						// about the token itself
+						a.observe(authMethodOAuth2, authResultError)
+						http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
 					}
+					return
 				}
 
-				if !hasScope {
+				scopes = result.Scopes()
+				userID = result.Subject
+			}
+
+			// Check required scopes
+			if len(requiredScopes) > 0 {
+				if !a.hasAnyScope(scopes, requiredScopes) {
 					a.log.Debug("Insufficient OAuth2 scope",
 						logger.String("required", strings.Join(requiredScopes, ",")),
 						logger.String("provided", strings.Join(scopes, ",")),
 					)
+					a.observe(authMethodOAuth2, authResultForbidden)
 					http.Error(w, "Forbidden: insufficient scope", http.StatusForbidden)
 					return
 				}
@@ -139,6 +171,7 @@ func (a *Authenticator) OAuth2AuthMiddleware(requiredScopes []string) func(next
 			// Store scopes and user ID in request context
 			ctx = context.WithValue(ctx, ScopesContextKey, scopes)
 			ctx = context.WithValue(ctx, UserIDContextKey, userID)
+			a.observe(authMethodOAuth2, authResultOK)
 
 			// Proceed with the next handler
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -146,6 +179,37 @@ func (a *Authenticator) OAuth2AuthMiddleware(requiredScopes []string) func(next
 	}
 }
 
+// extractFailureResult maps an ExtractBearerToken error to a metrics result
+func extractFailureResult(err error) string {
+	if err == ErrMissingToken {
+		return authResultMissing
+	}
+	return authResultInvalid
+}
+
+// observe counts an authentication attempt by method and result
+func (a *Authenticator) observe(method, result string) {
+	if a.requests != nil {
+		a.requests.WithLabelValues(method, result).Inc()
+	}
+}
+
+// hasAnyScope reports whether the provided scopes include at least one of the
+// required scopes, or the configured admin scope if one is set
+func (a *Authenticator) hasAnyScope(provided, required []string) bool {
+	for _, scope := range provided {
+		if a.adminScope != "" && scope == a.adminScope {
+			return true
+		}
+		for _, requiredScope := range required {
+			if scope == requiredScope {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // GetUserID returns the user ID from the context
 func GetUserID(ctx context.Context) (string, bool) {
 	userID, ok := ctx.Value(UserIDContextKey).(string)
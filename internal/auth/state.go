@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"container/list"
+	"crypto/rand"
+	"encoding/base64"
+	"sync"
+	"time"
+)
+
+// defaultOAuth2StateTTL is how long an authorization state stays valid when
+// no TTL is configured
+const defaultOAuth2StateTTL = 10 * time.Minute
+
+// maxOAuth2States caps how many outstanding authorization states are kept.
+// Login is unauthenticated, so the oldest states are evicted past the cap.
+const maxOAuth2States = 10000
+
+// stateStore remembers the OAuth2 states handed out with authorization URLs
+// so the callback can reject states it didn't issue. Each state is valid once.
+type stateStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	maxStates int
+	order     *list.List
+	states    map[string]*list.Element
+}
+
+// stateEntry is an issued state and its expiry
+type stateEntry struct {
+	state     string
+	expiresAt time.Time
+}
+
+// newStateStore creates a state store whose entries expire after ttl, holding
+// at most maxStates of them
+func newStateStore(ttl time.Duration, maxStates int) *stateStore {
+	if ttl <= 0 {
+		ttl = defaultOAuth2StateTTL
+	}
+
+	return &stateStore{
+		ttl:       ttl,
+		maxStates: maxStates,
+		order:     list.New(),
+		states:    make(map[string]*list.Element),
+	}
+}
+
+// add records state, dropping any entries that have expired and then the
+// oldest ones while the store is full. Every entry lives for the same TTL, so
+// the list stays in expiry order.
+func (s *stateStore) add(state string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for elem := s.order.Front(); elem != nil; elem = s.order.Front() {
+		if entry := elem.Value.(*stateEntry); now.Before(entry.expiresAt) && s.order.Len() < s.maxStates {
+			break
+		}
+		s.remove(elem)
+	}
+
+	if elem, ok := s.states[state]; ok {
+		s.remove(elem)
+	}
+	s.states[state] = s.order.PushBack(&stateEntry{state: state, expiresAt: now.Add(s.ttl)})
+}
+
+// consume removes state and reports whether it was issued and hasn't expired
+func (s *stateStore) consume(state string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.states[state]
+	if !ok {
+		return false
+	}
+
+	s.remove(elem)
+	return time.Now().Before(elem.Value.(*stateEntry).expiresAt)
+}
+
+// remove drops the state held by elem. The caller must hold s.mu.
+func (s *stateStore) remove(elem *list.Element) {
+	s.order.Remove(elem)
+	delete(s.states, elem.Value.(*stateEntry).state)
+}
+
+// newOAuth2State returns a random, URL-safe authorization state
+func newOAuth2State() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
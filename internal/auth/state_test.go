@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateStore(t *testing.T) {
+	// Test the oldest state is evicted when the store is full
+	t.Run("MaxStates", func(t *testing.T) {
+		s := newStateStore(time.Minute, 2)
+		s.add("a")
+		s.add("b")
+		s.add("c")
+
+		assert.False(t, s.consume("a"))
+		assert.True(t, s.consume("b"))
+		assert.True(t, s.consume("c"))
+	})
+
+	// Test expired states are dropped when another is added
+	t.Run("Expiry", func(t *testing.T) {
+		s := newStateStore(10*time.Millisecond, 10)
+		s.add("a")
+		s.add("b")
+
+		time.Sleep(20 * time.Millisecond)
+		s.add("c")
+
+		assert.Equal(t, 1, s.order.Len())
+		assert.Len(t, s.states, 1)
+	})
+}
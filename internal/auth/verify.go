@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+)
+
+// VerifyTokenHandler checks the request's bearer token with VerifyJWTToken
+// and responds with its decoded claims, or 401 saying why it was rejected. No
+// scope is required, so gateways can use it for out-of-band token checks.
+func (a *Authenticator) VerifyTokenHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, err := ExtractBearerToken(r)
+		if err != nil {
+			a.log.Debug("token verification failed", logger.Error(err))
+			if errors.Is(err, ErrMissingToken) {
+				http.Error(w, "Missing token", http.StatusUnauthorized)
+			} else {
+				http.Error(w, "Invalid token", http.StatusUnauthorized)
+			}
+			return
+		}
+
+		claims, err := a.VerifyJWTToken(token)
+		if err != nil {
+			a.log.Debug("token verification failed", logger.Error(err))
+			switch {
+			case errors.Is(err, ErrExpiredToken):
+				http.Error(w, "Token expired", http.StatusUnauthorized)
+			case errors.Is(err, ErrInvalidIssuer):
+				http.Error(w, "Invalid token issuer", http.StatusUnauthorized)
+			default:
+				http.Error(w, "Invalid token", http.StatusUnauthorized)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if err := json.NewEncoder(w).Encode(claims); err != nil {
+			a.log.Error("failed to write token claims", logger.Error(err))
+		}
+	}
+}
@@ -3,7 +3,12 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -13,157 +18,523 @@ import (
 
 // Config represents the application configuration
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Logging  LoggingConfig  `mapstructure:"logging"`
-	Metrics  MetricsConfig  `mapstructure:"metrics"`
-	Tracing  TracingConfig  `mapstructure:"tracing"`
-	Auth     AuthConfig     `mapstructure:"auth"`
+	Environment   string              `mapstructure:"environment" json:"environment"`
+	Watch         bool                `mapstructure:"watch" json:"watch"`
+	Server        ServerConfig        `mapstructure:"server" json:"server"`
+	Database      DatabaseConfig      `mapstructure:"database" json:"database"`
+	Logging       LoggingConfig       `mapstructure:"logging" json:"logging"`
+	Metrics       MetricsConfig       `mapstructure:"metrics" json:"metrics"`
+	Tracing       TracingConfig       `mapstructure:"tracing" json:"tracing"`
+	Auth          AuthConfig          `mapstructure:"auth" json:"auth"`
+	Cache         CacheConfig         `mapstructure:"cache" json:"cache"`
+	Examples      ExamplesConfig      `mapstructure:"examples" json:"examples"`
+	Observability ObservabilityConfig `mapstructure:"observability" json:"observability"`
+
+	// Features switches experimental routes on and off by name
+	Features map[string]bool `mapstructure:"features" json:"features"`
+}
+
+// IsEnabled reports whether the named feature is switched on. Names are
+// matched case-insensitively, since configuration keys are; unknown features
+// are off.
+func (c *Config) IsEnabled(name string) bool {
+	if enabled, ok := c.Features[name]; ok {
+		return enabled
+	}
+	for feature, enabled := range c.Features {
+		if strings.EqualFold(feature, name) {
+			return enabled
+		}
+	}
+	return false
 }
 
 // ServerConfig holds all server related configuration
 type ServerConfig struct {
-	Host         string        `mapstructure:"host"`
-	Port         int           `mapstructure:"port"`
-	ReadTimeout  time.Duration `mapstructure:"readTimeout"`
-	WriteTimeout time.Duration `mapstructure:"writeTimeout"`
-	IdleTimeout  time.Duration `mapstructure:"idleTimeout"`
+	Host         string        `mapstructure:"host" json:"host"`
+	Port         int           `mapstructure:"port" json:"port"`
+	ReadTimeout  time.Duration `mapstructure:"readTimeout" json:"readTimeout"`
+	WriteTimeout time.Duration `mapstructure:"writeTimeout" json:"writeTimeout"`
+	IdleTimeout  time.Duration `mapstructure:"idleTimeout" json:"idleTimeout"`
+
+	// BasePath is where the API routes are mounted, e.g. /myservice/v1 behind
+	// a gateway. Health, metrics, auth and Swagger routes stay at the root.
+	BasePath string `mapstructure:"basePath" json:"basePath"`
+
+	// IdempotencyTTL is how long responses are kept for Idempotency-Key replay (0 disables)
+	IdempotencyTTL time.Duration `mapstructure:"idempotencyTTL" json:"idempotencyTTL"`
+
+	// IdempotencyMaxEntries caps how many responses are kept for replay; the
+	// oldest are evicted first (0 means no cap)
+	IdempotencyMaxEntries int `mapstructure:"idempotencyMaxEntries" json:"idempotencyMaxEntries"`
+
+	// IdempotencyMaxBodyBytes caps the size of request bodies sent with an
+	// Idempotency-Key; larger ones are rejected with 413 (0 means no cap)
+	IdempotencyMaxBodyBytes int64 `mapstructure:"idempotencyMaxBodyBytes" json:"idempotencyMaxBodyBytes"`
+
+	// DebugErrors includes stack traces in 500 responses; never enable in production
+	DebugErrors bool `mapstructure:"debugErrors" json:"debugErrors"`
+
+	// DevRoutes enables development-only routes such as DELETE /api/v1/examples
+	DevRoutes bool `mapstructure:"devRoutes" json:"devRoutes"`
+
+	// MaxRequestTimeout caps the X-Request-Timeout a client may ask for (0 ignores the header)
+	MaxRequestTimeout time.Duration `mapstructure:"maxRequestTimeout" json:"maxRequestTimeout"`
+
+	// Compression gzips responses for clients that accept it
+	Compression bool `mapstructure:"compression" json:"compression"`
+
+	// MinCompressBytes is the smallest response body that is compressed
+	MinCompressBytes int `mapstructure:"minCompressBytes" json:"minCompressBytes"`
+
+	// MaxPageSize caps the limit accepted by list endpoints
+	MaxPageSize int `mapstructure:"maxPageSize" json:"maxPageSize"`
+
+	// MaxConcurrent is how many requests may be handled at once; requests
+	// beyond it are rejected with 503 (0 disables the limit)
+	MaxConcurrent int `mapstructure:"maxConcurrent" json:"maxConcurrent"`
+
+	// ServerTiming sends a Server-Timing header breaking down the time spent
+	// in auth, the handler and in total, for frontend performance debugging
+	ServerTiming bool `mapstructure:"serverTiming" json:"serverTiming"`
+
+	// OpenAPIValidation validates API requests against the generated OpenAPI spec
+	OpenAPIValidation bool `mapstructure:"openAPIValidation" json:"openAPIValidation"`
+
+	// RedirectHTTPS redirects plain HTTP requests to HTTPS and sets HSTS; enable
+	// only when the server is reached over TLS or behind a TLS-terminating proxy
+	RedirectHTTPS bool `mapstructure:"redirectHTTPS" json:"redirectHTTPS"`
+
+	// HSTSMaxAge is the Strict-Transport-Security max-age (0 omits the header)
+	HSTSMaxAge time.Duration `mapstructure:"hstsMaxAge" json:"hstsMaxAge"`
+
+	// HTTPSExcludePaths are served over plain HTTP, e.g. load balancer health checks
+	HTTPSExcludePaths []string `mapstructure:"httpsExcludePaths" json:"httpsExcludePaths"`
+
+	// CacheControl maps route patterns to the Cache-Control sent on their
+	// successful GET responses; other API GETs get no-store
+	CacheControl map[string]string `mapstructure:"cacheControl" json:"cacheControl"`
+
+	// TrustedProxies are the CIDRs or IPs whose X-Forwarded-For and X-Real-IP
+	// headers are honored when resolving the client IP
+	TrustedProxies []string `mapstructure:"trustedProxies" json:"trustedProxies"`
 }
 
 // DatabaseConfig holds all database related configuration
 type DatabaseConfig struct {
-	Driver   string `mapstructure:"driver"`
-	Host     string `mapstructure:"host"`
-	Port     int    `mapstructure:"port"`
-	User     string `mapstructure:"user"`
-	Password string `mapstructure:"password"`
-	Name     string `mapstructure:"name"`
-	SSLMode  string `mapstructure:"sslMode"`
+	Driver   string `mapstructure:"driver" json:"driver"`
+	Host     string `mapstructure:"host" json:"host"`
+	Port     int    `mapstructure:"port" json:"port"`
+	User     string `mapstructure:"user" json:"user"`
+	Password string `mapstructure:"password" json:"password"`
+	Name     string `mapstructure:"name" json:"name"`
+	SSLMode  string `mapstructure:"sslMode" json:"sslMode"`
+
+	// BreakerThreshold is how many consecutive failures open the repository
+	// circuit breaker (0 disables it); BreakerCooldown is how long it stays open
+	BreakerThreshold int           `mapstructure:"breakerThreshold" json:"breakerThreshold"`
+	BreakerCooldown  time.Duration `mapstructure:"breakerCooldown" json:"breakerCooldown"`
 }
 
 // LoggingConfig holds all logging related configuration
 type LoggingConfig struct {
-	Level  string `mapstructure:"level"`
-	Format string `mapstructure:"format"`
+	Level  string `mapstructure:"level" json:"level"`
+	Format string `mapstructure:"format" json:"format"`
+
+	// AccessLog logs each request as a single combined entry on completion
+	AccessLog bool `mapstructure:"accessLog" json:"accessLog"`
+
+	// MaxBodyLogBytes caps how much of each JSON request and response body is
+	// logged at debug level (0 disables body logging)
+	MaxBodyLogBytes int `mapstructure:"maxBodyLogBytes" json:"maxBodyLogBytes"`
 }
 
 // MetricsConfig holds all metrics related configuration
 type MetricsConfig struct {
-	Enabled bool   `mapstructure:"enabled"`
-	Host    string `mapstructure:"host"`
-	Port    int    `mapstructure:"port"`
+	Enabled    bool             `mapstructure:"enabled" json:"enabled"`
+	Host       string           `mapstructure:"host" json:"host"`
+	Port       int              `mapstructure:"port" json:"port"`
+	Collectors CollectorsConfig `mapstructure:"collectors" json:"collectors"`
+
+	// AdminListener serves /metrics on Host:Port instead of the API port
+	AdminListener bool `mapstructure:"adminListener" json:"adminListener"`
+
+	// Buckets overrides the HTTP histogram buckets; empty lists keep the defaults
+	Buckets BucketsConfig `mapstructure:"buckets" json:"buckets"`
+
+	// RequireAuth protects /metrics with basic auth when Username is set, or
+	// with a JWT bearer token otherwise
+	RequireAuth bool   `mapstructure:"requireAuth" json:"requireAuth"`
+	Username    string `mapstructure:"username" json:"username"`
+	Password    string `mapstructure:"password" json:"password"`
+}
+
+// BucketsConfig holds the bucket boundaries of the HTTP histograms
+type BucketsConfig struct {
+	Duration     []float64 `mapstructure:"duration" json:"duration"`
+	RequestSize  []float64 `mapstructure:"requestSize" json:"requestSize"`
+	ResponseSize []float64 `mapstructure:"responseSize" json:"responseSize"`
+}
+
+// CollectorsConfig selects which default Prometheus collectors are registered
+type CollectorsConfig struct {
+	Go      bool `mapstructure:"go" json:"go"`
+	Process bool `mapstructure:"process" json:"process"`
 }
 
 // TracingConfig holds all tracing related configuration
 type TracingConfig struct {
-	Enabled     bool   `mapstructure:"enabled"`
-	Endpoint    string `mapstructure:"endpoint"`
-	ServiceName string `mapstructure:"serviceName"`
+	Enabled        bool   `mapstructure:"enabled" json:"enabled"`
+	Endpoint       string `mapstructure:"endpoint" json:"endpoint"`
+	ServiceName    string `mapstructure:"serviceName" json:"serviceName"`
+	MetricsEnabled bool   `mapstructure:"metricsEnabled" json:"metricsEnabled"`
+	Sampler        string `mapstructure:"sampler" json:"sampler"`
+	Protocol       string `mapstructure:"protocol" json:"protocol"`
+	Insecure       bool   `mapstructure:"insecure" json:"insecure"`
 }
 
 // AuthConfig holds all authentication related configuration
 type AuthConfig struct {
-	Enabled            bool          `mapstructure:"enabled"`
-	JWTSecret          string        `mapstructure:"jwtSecret"`
-	JWTSigningMethod   string        `mapstructure:"jwtSigningMethod"`
-	JWTExpirationTime  time.Duration `mapstructure:"jwtExpirationTime"`
-	JWTIssuer          string        `mapstructure:"jwtIssuer"`
-	OAuth2ClientID     string        `mapstructure:"oauth2ClientID"`
-	OAuth2ClientSecret string        `mapstructure:"oauth2ClientSecret"`
-	OAuth2RedirectURL  string        `mapstructure:"oauth2RedirectURL"`
-	OAuth2AuthURL      string        `mapstructure:"oauth2AuthURL"`
-	OAuth2TokenURL     string        `mapstructure:"oauth2TokenURL"`
-	OAuth2Scopes       []string      `mapstructure:"oauth2Scopes"`
-}
-
-// Load loads the configuration from environment variables, config file, and command line flags
+	Enabled            bool          `mapstructure:"enabled" json:"enabled"`
+	JWTSecret          string        `mapstructure:"jwtSecret" json:"jwtSecret"`
+	JWTSigningMethod   string        `mapstructure:"jwtSigningMethod" json:"jwtSigningMethod"`
+	JWTExpirationTime  time.Duration `mapstructure:"jwtExpirationTime" json:"jwtExpirationTime"`
+	JWTIssuer          string        `mapstructure:"jwtIssuer" json:"jwtIssuer"`
+	OAuth2ClientID     string        `mapstructure:"oauth2ClientID" json:"oauth2ClientID"`
+	OAuth2ClientSecret string        `mapstructure:"oauth2ClientSecret" json:"oauth2ClientSecret"`
+	OAuth2RedirectURL  string        `mapstructure:"oauth2RedirectURL" json:"oauth2RedirectURL"`
+	OAuth2AuthURL      string        `mapstructure:"oauth2AuthURL" json:"oauth2AuthURL"`
+	OAuth2TokenURL     string        `mapstructure:"oauth2TokenURL" json:"oauth2TokenURL"`
+	OAuth2Scopes       []string      `mapstructure:"oauth2Scopes" json:"oauth2Scopes"`
+	OAuth2HTTPTimeout  time.Duration `mapstructure:"oauth2HTTPTimeout" json:"oauth2HTTPTimeout"`
+	OAuth2StateTTL     time.Duration `mapstructure:"oauth2StateTTL" json:"oauth2StateTTL"`
+
+	// OAuth2TokenCookie is the cookie /auth/callback stores the access token
+	// in; empty returns the token as JSON instead
+	OAuth2TokenCookie string `mapstructure:"oauth2TokenCookie" json:"oauth2TokenCookie"`
+
+	// OAuth2IntrospectionURL is the provider's token introspection endpoint;
+	// empty accepts any bearer token, which is only suitable for development
+	OAuth2IntrospectionURL string `mapstructure:"oauth2IntrospectionURL" json:"oauth2IntrospectionURL"`
+
+	// OAuth2HealthURL is the provider URL the health check pings, such as its
+	// JWKS endpoint; empty pings OAuth2IntrospectionURL
+	OAuth2HealthURL string `mapstructure:"oauth2HealthURL" json:"oauth2HealthURL"`
+
+	// OAuth2IntrospectionCacheTTL caps how long an active introspection result
+	// is reused (0 disables caching)
+	OAuth2IntrospectionCacheTTL time.Duration `mapstructure:"oauth2IntrospectionCacheTTL" json:"oauth2IntrospectionCacheTTL"`
+
+	// AdminScope grants access to every scoped route; empty disables the bypass
+	AdminScope string `mapstructure:"adminScope" json:"adminScope"`
+
+	// JWTSkipIssuerCheck accepts JWTs whose iss claim doesn't match JWTIssuer,
+	// for setups where several issuers share a signing key
+	JWTSkipIssuerCheck bool `mapstructure:"jwtSkipIssuerCheck" json:"jwtSkipIssuerCheck"`
+}
+
+// CacheConfig holds all caching related configuration
+type CacheConfig struct {
+	ListTTL     time.Duration `mapstructure:"listTTL" json:"listTTL"`
+	ExampleTTL  time.Duration `mapstructure:"exampleTTL" json:"exampleTTL"`
+	ExampleSize int           `mapstructure:"exampleSize" json:"exampleSize"`
+}
+
+// ExamplesConfig holds configuration for the examples resource
+type ExamplesConfig struct {
+	// UniqueNames rejects creating or renaming an example to a name that's
+	// already taken. It is checked before each write, not atomically with it.
+	UniqueNames bool `mapstructure:"uniqueNames" json:"uniqueNames"`
+
+	// CreateOnPut makes PUT /examples/{id} create the example if the ID
+	// doesn't exist instead of responding 404
+	CreateOnPut bool `mapstructure:"createOnPut" json:"createOnPut"`
+}
+
+// ObservabilityConfig holds configuration shared by logging and metrics middleware
+type ObservabilityConfig struct {
+	ExcludePaths []string `mapstructure:"excludePaths" json:"excludePaths"`
+
+	// DegradedIsReady keeps readiness at 200 while a health component is
+	// DEGRADED; when false readiness responds 503
+	DegradedIsReady bool `mapstructure:"degradedIsReady" json:"degradedIsReady"`
+
+	// BaggageHeaders are request headers copied into OpenTelemetry baggage
+	// and span attributes, e.g. X-Tenant-ID
+	BaggageHeaders []string `mapstructure:"baggageHeaders" json:"baggageHeaders"`
+}
+
+// DefaultEnvPrefix is the prefix of the environment variables Load reads,
+// e.g. APP_SERVER_PORT for server.port
+const DefaultEnvPrefix = "APP"
+
+// Options controls the sources LoadFrom reads configuration from
+type Options struct {
+	// ConfigFile is the config file to read. When empty, the file named by
+	// the --config flag is read, or else the first one found in the default
+	// search paths; running without a config file is fine.
+	ConfigFile string
+
+	// EnvPrefix is the prefix of the environment variables to read, e.g. APP
+	// for APP_SERVER_PORT. When empty, environment variables are ignored.
+	EnvPrefix string
+
+	// Args are the command line arguments to parse as flags, without the
+	// program name
+	Args []string
+}
+
+// Load loads the configuration from the command line flags, APP_ environment
+// variables and config file of the process. See LoadFrom for the precedence.
 func Load() (*Config, error) {
-	// Set default config
-	viper.SetDefault("server.host", "0.0.0.0")
-	viper.SetDefault("server.port", 8080)
-	viper.SetDefault("server.readTimeout", 10*time.Second)
-	viper.SetDefault("server.writeTimeout", 10*time.Second)
-	viper.SetDefault("server.idleTimeout", 60*time.Second)
-	viper.SetDefault("logging.level", "info")
-	viper.SetDefault("logging.format", "json")
-	viper.SetDefault("metrics.enabled", true)
-	viper.SetDefault("metrics.host", "0.0.0.0")
-	viper.SetDefault("metrics.port", 9090)
-	viper.SetDefault("tracing.enabled", true)
-	viper.SetDefault("tracing.endpoint", "localhost:4317")
-	viper.SetDefault("tracing.serviceName", "api-service")
-	viper.SetDefault("auth.enabled", true)
-	viper.SetDefault("auth.jwtSecret", "your-secret-key-change-me-in-production")
-	viper.SetDefault("auth.jwtSigningMethod", "HS256")
-	viper.SetDefault("auth.jwtExpirationTime", 24*time.Hour)
-	viper.SetDefault("auth.jwtIssuer", "api-template")
-	viper.SetDefault("auth.oauth2ClientID", "example-client-id")
-	viper.SetDefault("auth.oauth2ClientSecret", "example-client-secret")
-	viper.SetDefault("auth.oauth2RedirectURL", "http://localhost:8080/auth/callback")
-	viper.SetDefault("auth.oauth2AuthURL", "https://example.com/oauth/authorize")
-	viper.SetDefault("auth.oauth2TokenURL", "https://example.com/oauth/token")
-	viper.SetDefault("auth.oauth2Scopes", []string{"read", "write"})
-
-	// Environment variables
-	viper.SetEnvPrefix("APP")
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	viper.AutomaticEnv()
-
-	// Config file
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(".")
-	viper.AddConfigPath("./config")
-	viper.AddConfigPath("/etc/app")
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return nil, fmt.Errorf("failed to read config file: %w", err)
+	return load(viper.GetViper(), Options{
+		EnvPrefix: DefaultEnvPrefix,
+		Args:      os.Args[1:],
+	}, pflag.ExitOnError)
+}
+
+// LoadFrom loads the configuration from the sources in opts. Each value comes
+// from the first source that sets it: flags, then environment variables,
+// then the config file, then the defaults. Unlike Load it starts from fresh
+// state on every call and returns flag errors rather than exiting.
+func LoadFrom(opts Options) (*Config, error) {
+	return load(viper.New(), opts, pflag.ContinueOnError)
+}
+
+// load loads the configuration from the sources in opts into v, layering them
+// from lowest to highest precedence
+func load(v *viper.Viper, opts Options, flagErrors pflag.ErrorHandling) (*Config, error) {
+	// 1. Defaults
+	setDefaults(v)
+
+	// Parse flags first, since --config decides which file to read, but bind
+	// them last; their defaults are only shown in the usage
+	flags := pflag.NewFlagSet("api", flagErrors)
+	flags.String("config", "", "Path to config file")
+	flags.String("server.host", v.GetString("server.host"), "Server host")
+	flags.Int("server.port", v.GetInt("server.port"), "Server port")
+	flags.String("logging.level", v.GetString("logging.level"), "Logging level")
+	flags.String("logging.format", v.GetString("logging.format"), "Logging format (json, logfmt or text)")
+	flags.Bool("metrics.enabled", v.GetBool("metrics.enabled"), "Enable Prometheus metrics")
+	flags.Bool("tracing.enabled", v.GetBool("tracing.enabled"), "Enable OpenTelemetry tracing")
+	if err := flags.Parse(opts.Args); err != nil {
+		return nil, fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	// 2. Config file, in any supported format
+	path := opts.ConfigFile
+	if path == "" {
+		path, _ = flags.GetString("config")
+	}
+	if path == "" {
+		path = findConfigFile()
+	}
+	if path != "" {
+		if err := readConfigFile(v, path); err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
 		}
-		// Config file not found, continue with defaults and env vars
 	}
 
-	// Command line flags
-	pflag.String("config", "", "Path to config file")
-	pflag.String("server.host", viper.GetString("server.host"), "Server host")
-	pflag.Int("server.port", viper.GetInt("server.port"), "Server port")
-	pflag.String("logging.level", viper.GetString("logging.level"), "Logging level")
-	pflag.String("logging.format", viper.GetString("logging.format"), "Logging format (json or text)")
-	pflag.Bool("metrics.enabled", viper.GetBool("metrics.enabled"), "Enable Prometheus metrics")
-	pflag.Bool("tracing.enabled", viper.GetBool("tracing.enabled"), "Enable OpenTelemetry tracing")
-	pflag.Parse()
+	// 3. Environment variables
+	if opts.EnvPrefix != "" {
+		v.SetEnvPrefix(opts.EnvPrefix)
+		v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+		v.AutomaticEnv()
+	}
 
-	// Bind flags to viper
-	if err := viper.BindPFlags(pflag.CommandLine); err != nil {
+	// 4. Command line flags, only those actually set overriding the layers above
+	if err := v.BindPFlags(flags); err != nil {
 		return nil, fmt.Errorf("failed to bind flags: %w", err)
 	}
 
-	// Check for custom config file specified via flag
-	if configFile := viper.GetString("config"); configFile != "" {
-		viper.SetConfigFile(configFile)
-		if err := viper.ReadInConfig(); err != nil {
-			return nil, fmt.Errorf("failed to read config file %s: %w", configFile, err)
+	var config Config
+	if err := v.Unmarshal(&config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	// Reload on config file changes
+	if config.Watch && v.ConfigFileUsed() != "" {
+		watch(v)
+	}
+
+	return &config, nil
+}
+
+// setDefaults sets the default of every configuration key on v
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("environment", "development")
+	v.SetDefault("watch", false)
+	v.SetDefault("server.host", "0.0.0.0")
+	v.SetDefault("server.port", 8080)
+	v.SetDefault("server.basePath", "/api/v1")
+	v.SetDefault("server.readTimeout", 10*time.Second)
+	v.SetDefault("server.writeTimeout", 10*time.Second)
+	v.SetDefault("server.idleTimeout", 60*time.Second)
+	v.SetDefault("server.idempotencyTTL", 24*time.Hour)
+	v.SetDefault("server.idempotencyMaxEntries", 10000)
+	v.SetDefault("server.idempotencyMaxBodyBytes", 1<<20)
+	v.SetDefault("server.debugErrors", false)
+	v.SetDefault("server.devRoutes", false)
+	v.SetDefault("server.openAPIValidation", false)
+	v.SetDefault("server.compression", true)
+	v.SetDefault("server.minCompressBytes", 1024)
+	v.SetDefault("server.maxPageSize", 100)
+	v.SetDefault("server.maxConcurrent", 0)
+	v.SetDefault("server.serverTiming", false)
+	v.SetDefault("server.maxRequestTimeout", 10*time.Second)
+	v.SetDefault("server.redirectHTTPS", false)
+	v.SetDefault("server.hstsMaxAge", 365*24*time.Hour)
+	v.SetDefault("server.trustedProxies", []string{})
+	v.SetDefault("server.cacheControl", map[string]string{})
+	v.SetDefault("server.httpsExcludePaths", []string{"/health", "/health/liveness", "/health/readiness"})
+	v.SetDefault("database.breakerThreshold", 5)
+	v.SetDefault("database.breakerCooldown", 30*time.Second)
+	v.SetDefault("logging.level", "info")
+	v.SetDefault("logging.format", "json")
+	v.SetDefault("logging.accessLog", false)
+	v.SetDefault("logging.maxBodyLogBytes", 2048)
+	v.SetDefault("metrics.enabled", true)
+	v.SetDefault("metrics.host", "0.0.0.0")
+	v.SetDefault("metrics.port", 9090)
+	v.SetDefault("metrics.collectors.go", true)
+	v.SetDefault("metrics.collectors.process", true)
+	v.SetDefault("metrics.adminListener", false)
+	v.SetDefault("metrics.buckets.duration", []float64{})
+	v.SetDefault("metrics.buckets.requestSize", []float64{})
+	v.SetDefault("metrics.buckets.responseSize", []float64{})
+	v.SetDefault("metrics.requireAuth", false)
+	v.SetDefault("metrics.username", "")
+	v.SetDefault("metrics.password", "")
+	v.SetDefault("tracing.enabled", true)
+	v.SetDefault("tracing.endpoint", "localhost:4317")
+	v.SetDefault("tracing.serviceName", "api-service")
+	v.SetDefault("tracing.metricsEnabled", false)
+	v.SetDefault("tracing.sampler", "always")
+	v.SetDefault("tracing.protocol", "grpc")
+	v.SetDefault("tracing.insecure", true)
+	v.SetDefault("auth.enabled", true)
+	v.SetDefault("auth.jwtSecret", DefaultJWTSecret)
+	v.SetDefault("auth.jwtSigningMethod", "HS256")
+	v.SetDefault("auth.jwtExpirationTime", 24*time.Hour)
+	v.SetDefault("auth.jwtIssuer", "api-template")
+	v.SetDefault("auth.jwtSkipIssuerCheck", false)
+	v.SetDefault("auth.oauth2ClientID", "example-client-id")
+	v.SetDefault("auth.oauth2ClientSecret", "example-client-secret")
+	v.SetDefault("auth.oauth2RedirectURL", "http://localhost:8080/auth/callback")
+	v.SetDefault("auth.oauth2AuthURL", "https://example.com/oauth/authorize")
+	v.SetDefault("auth.oauth2TokenURL", "https://example.com/oauth/token")
+	v.SetDefault("auth.oauth2Scopes", []string{"read", "write"})
+	v.SetDefault("auth.oauth2HTTPTimeout", 10*time.Second)
+	v.SetDefault("auth.oauth2StateTTL", 10*time.Minute)
+	v.SetDefault("auth.oauth2TokenCookie", "")
+	v.SetDefault("auth.oauth2IntrospectionURL", "")
+	v.SetDefault("auth.oauth2HealthURL", "")
+	v.SetDefault("auth.oauth2IntrospectionCacheTTL", 5*time.Minute)
+	v.SetDefault("auth.adminScope", "")
+	v.SetDefault("cache.listTTL", time.Second)
+	v.SetDefault("cache.exampleTTL", 30*time.Second)
+	v.SetDefault("cache.exampleSize", 1000)
+	v.SetDefault("examples.uniqueNames", true)
+	v.SetDefault("examples.createOnPut", false)
+	v.SetDefault("features.bulkCreate", true)
+	v.SetDefault("observability.excludePaths", []string{})
+	v.SetDefault("observability.degradedIsReady", true)
+	v.SetDefault("observability.baggageHeaders", []string{})
+}
+
+// configTypes are the supported config file extensions, in the order they
+// are tried when discovering a config file
+var configTypes = []string{"yaml", "yml", "json", "toml"}
+
+// configSearchPaths are the directories searched for a config file
+var configSearchPaths = []string{".", "./config", "/etc/app"}
+
+// findConfigFile returns the first config file found in the search paths,
+// trying each supported extension in turn, or "" if there is none
+func findConfigFile() string {
+	for _, dir := range configSearchPaths {
+		for _, ext := range configTypes {
+			path := filepath.Join(dir, "config."+ext)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
 		}
 	}
+	return ""
+}
+
+// readConfigFile reads the config file at path into v, parsing it according
+// to its extension
+func readConfigFile(v *viper.Viper, path string) error {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	if !slices.Contains(configTypes, ext) {
+		return fmt.Errorf("unsupported config file type %q, must be one of %v", ext, configTypes)
+	}
+
+	v.SetConfigFile(path)
+	v.SetConfigType(ext)
+	return v.ReadInConfig()
+}
+
+// ReadFile reads a single config file in any supported format. Unlike Load it
+// applies no defaults, environment variables or flags, and doesn't validate.
+func ReadFile(path string) (*Config, error) {
+	v := viper.New()
+	if err := readConfigFile(v, path); err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
 
 	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
+	if err := v.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
 	return &config, nil
 }
 
-// String returns a string representation of the config for logging
+// redactedValue replaces secrets in Redacted configs
+const redactedValue = "****"
+
+// Redacted returns a deep copy of the config with secrets masked
+func (c *Config) Redacted() *Config {
+	redacted := *c
+
+	redacted.Server.HTTPSExcludePaths = slices.Clone(c.Server.HTTPSExcludePaths)
+	redacted.Server.CacheControl = maps.Clone(c.Server.CacheControl)
+	redacted.Server.TrustedProxies = slices.Clone(c.Server.TrustedProxies)
+	redacted.Metrics.Buckets.Duration = slices.Clone(c.Metrics.Buckets.Duration)
+	redacted.Metrics.Buckets.RequestSize = slices.Clone(c.Metrics.Buckets.RequestSize)
+	redacted.Metrics.Buckets.ResponseSize = slices.Clone(c.Metrics.Buckets.ResponseSize)
+	redacted.Auth.OAuth2Scopes = slices.Clone(c.Auth.OAuth2Scopes)
+	redacted.Observability.ExcludePaths = slices.Clone(c.Observability.ExcludePaths)
+	redacted.Observability.BaggageHeaders = slices.Clone(c.Observability.BaggageHeaders)
+	redacted.Features = maps.Clone(c.Features)
+
+	redacted.Auth.JWTSecret = redact(c.Auth.JWTSecret)
+	redacted.Auth.OAuth2ClientSecret = redact(c.Auth.OAuth2ClientSecret)
+	redacted.Database.Password = redact(c.Database.Password)
+	redacted.Metrics.Password = redact(c.Metrics.Password)
+
+	return &redacted
+}
+
+// redact masks a secret, leaving unset secrets empty
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redactedValue
+}
+
+// String returns the redacted config as compact JSON for logging
 func (c *Config) String() string {
-	// Hide sensitive information
-	return fmt.Sprintf(
-		"Server: %s:%d, Logging: level=%s format=%s, Metrics: enabled=%t port=%d, Tracing: enabled=%t",
-		c.Server.Host,
-		c.Server.Port,
-		c.Logging.Level,
-		c.Logging.Format,
-		c.Metrics.Enabled,
-		c.Metrics.Port,
-		c.Tracing.Enabled,
-	)
+	data, err := json.Marshal(c.Redacted())
+	if err != nil {
+		return fmt.Sprintf("failed to marshal config: %v", err)
+	}
+	return string(data)
 }
@@ -0,0 +1,468 @@
+package config_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dBiTech/go-apiTemplate/internal/config"
+)
+
+// validConfig returns a configuration that passes validation
+func validConfig() *config.Config {
+	return &config.Config{
+		Environment: "production",
+		Server:      config.ServerConfig{Host: "0.0.0.0", Port: 8080},
+		Logging:     config.LoggingConfig{Level: "info", Format: "json"},
+		Metrics:     config.MetricsConfig{Enabled: true, Port: 9090},
+		Tracing:     config.TracingConfig{Enabled: true, Protocol: "grpc"},
+		Auth: config.AuthConfig{
+			Enabled:           true,
+			JWTSecret:         "a-real-secret",
+			OAuth2AuthURL:     "https://auth.example.com/authorize",
+			OAuth2TokenURL:    "https://auth.example.com/token",
+			OAuth2RedirectURL: "https://api.example.com/auth/callback",
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	// Test a valid config passes
+	t.Run("Valid", func(t *testing.T) {
+		require.NoError(t, validConfig().Validate())
+	})
+
+	tests := []struct {
+		name   string
+		modify func(c *config.Config)
+		field  string
+	}{
+		{
+			name:   "NegativePort",
+			modify: func(c *config.Config) { c.Server.Port = -1 },
+			field:  "server.port",
+		},
+		{
+			name:   "PortTooLarge",
+			modify: func(c *config.Config) { c.Server.Port = 70000 },
+			field:  "server.port",
+		},
+		{
+			name: "AdminListenerSharesPort",
+			modify: func(c *config.Config) {
+				c.Metrics.AdminListener = true
+				c.Metrics.Port = c.Server.Port
+			},
+			field: "metrics.port",
+		},
+		{
+			name:   "BasePathTrailingSlash",
+			modify: func(c *config.Config) { c.Server.BasePath = "/myservice/v1/" },
+			field:  "server.basePath",
+		},
+		{
+			name:   "NegativeMinCompressBytes",
+			modify: func(c *config.Config) { c.Server.MinCompressBytes = -1 },
+			field:  "server.minCompressBytes",
+		},
+		{
+			name:   "NegativeMaxPageSize",
+			modify: func(c *config.Config) { c.Server.MaxPageSize = -1 },
+			field:  "server.maxPageSize",
+		},
+		{
+			name:   "NegativeIdempotencyMaxEntries",
+			modify: func(c *config.Config) { c.Server.IdempotencyMaxEntries = -1 },
+			field:  "server.idempotencyMaxEntries",
+		},
+		{
+			name:   "NegativeIdempotencyMaxBodyBytes",
+			modify: func(c *config.Config) { c.Server.IdempotencyMaxBodyBytes = -1 },
+			field:  "server.idempotencyMaxBodyBytes",
+		},
+		{
+			name:   "NegativeMaxConcurrent",
+			modify: func(c *config.Config) { c.Server.MaxConcurrent = -1 },
+			field:  "server.maxConcurrent",
+		},
+		{
+			name:   "InvalidTrustedProxy",
+			modify: func(c *config.Config) { c.Server.TrustedProxies = []string{"10.0.0.0/8", "proxy.local"} },
+			field:  "server.trustedProxies",
+		},
+		{
+			name:   "DebugErrorsInProduction",
+			modify: func(c *config.Config) { c.Server.DebugErrors = true },
+			field:  "server.debugErrors",
+		},
+		{
+			name:   "DevRoutesInProduction",
+			modify: func(c *config.Config) { c.Server.DevRoutes = true },
+			field:  "server.devRoutes",
+		},
+		{
+			name:   "MetricsPort",
+			modify: func(c *config.Config) { c.Metrics.Port = 0 },
+			field:  "metrics.port",
+		},
+		{
+			name: "MetricsUsernameWithoutPassword",
+			modify: func(c *config.Config) {
+				c.Metrics.RequireAuth = true
+				c.Metrics.Username = "prometheus"
+			},
+			field: "metrics.password",
+		},
+		{
+			name:   "UnorderedDurationBuckets",
+			modify: func(c *config.Config) { c.Metrics.Buckets.Duration = []float64{0.1, 0.05} },
+			field:  "metrics.buckets.duration",
+		},
+		{
+			name:   "UnknownLogLevel",
+			modify: func(c *config.Config) { c.Logging.Level = "verbose" },
+			field:  "logging.level",
+		},
+		{
+			name:   "UnknownLogFormat",
+			modify: func(c *config.Config) { c.Logging.Format = "jsonn" },
+			field:  "logging.format",
+		},
+		{
+			name:   "NegativeMaxBodyLogBytes",
+			modify: func(c *config.Config) { c.Logging.MaxBodyLogBytes = -1 },
+			field:  "logging.maxBodyLogBytes",
+		},
+		{
+			name:   "UnknownTracingProtocol",
+			modify: func(c *config.Config) { c.Tracing.Protocol = "udp" },
+			field:  "tracing.protocol",
+		},
+		{
+			name:   "DefaultJWTSecretInProduction",
+			modify: func(c *config.Config) { c.Auth.JWTSecret = config.DefaultJWTSecret },
+			field:  "auth.jwtSecret",
+		},
+		{
+			name:   "EmptyJWTSecretInProduction",
+			modify: func(c *config.Config) { c.Auth.JWTSecret = "" },
+			field:  "auth.jwtSecret",
+		},
+		{
+			name:   "MissingOAuth2AuthURL",
+			modify: func(c *config.Config) { c.Auth.OAuth2AuthURL = "" },
+			field:  "auth.oauth2AuthURL",
+		},
+		{
+			name:   "RelativeOAuth2TokenURL",
+			modify: func(c *config.Config) { c.Auth.OAuth2TokenURL = "/token" },
+			field:  "auth.oauth2TokenURL",
+		},
+		{
+			name:   "RelativeOAuth2IntrospectionURL",
+			modify: func(c *config.Config) { c.Auth.OAuth2IntrospectionURL = "/introspect" },
+			field:  "auth.oauth2IntrospectionURL",
+		},
+		{
+			name:   "MissingOAuth2RedirectURL",
+			modify: func(c *config.Config) { c.Auth.OAuth2RedirectURL = "" },
+			field:  "auth.oauth2RedirectURL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := validConfig()
+			tt.modify(c)
+
+			err := c.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.field)
+		})
+	}
+
+	// Test the default JWT secret is allowed outside production
+	t.Run("DefaultJWTSecretInDevelopment", func(t *testing.T) {
+		c := validConfig()
+		c.Environment = "development"
+		c.Auth.JWTSecret = config.DefaultJWTSecret
+		require.NoError(t, c.Validate())
+	})
+
+	// Test auth settings are ignored when auth is disabled
+	t.Run("AuthDisabled", func(t *testing.T) {
+		c := validConfig()
+		c.Auth = config.AuthConfig{Enabled: false}
+		require.NoError(t, c.Validate())
+	})
+
+	// Test every problem is reported
+	t.Run("MultipleErrors", func(t *testing.T) {
+		c := validConfig()
+		c.Server.Port = 0
+		c.Logging.Format = "jsonn"
+
+		err := c.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "server.port")
+		assert.Contains(t, err.Error(), "logging.format")
+	})
+}
+
+func TestIsEnabled(t *testing.T) {
+	c := validConfig()
+	c.Features = map[string]bool{"bulkCreate": true, "search": false, "lowercased": true}
+
+	// Test configured features report their setting
+	t.Run("Configured", func(t *testing.T) {
+		assert.True(t, c.IsEnabled("bulkCreate"))
+		assert.False(t, c.IsEnabled("search"))
+	})
+
+	// Test names match regardless of case, as viper lowercases keys
+	t.Run("CaseInsensitive", func(t *testing.T) {
+		assert.True(t, c.IsEnabled("lowerCased"))
+	})
+
+	// Test unknown features are off
+	t.Run("Unknown", func(t *testing.T) {
+		assert.False(t, c.IsEnabled("unknown"))
+		assert.False(t, (&config.Config{}).IsEnabled("bulkCreate"))
+	})
+}
+
+func TestRedacted(t *testing.T) {
+	c := validConfig()
+	c.Database = config.DatabaseConfig{Host: "db.example.com", User: "app", Password: "db-password"}
+	c.Auth.OAuth2ClientID = "client-id"
+	c.Auth.OAuth2ClientSecret = "client-secret"
+	c.Auth.OAuth2Scopes = []string{"read"}
+	c.Metrics.Password = "metrics-password"
+	c.Server.HTTPSExcludePaths = []string{"/health"}
+	c.Server.CacheControl = map[string]string{"/api": "no-store"}
+	c.Server.TrustedProxies = []string{"10.0.0.0/8"}
+	c.Metrics.Buckets.Duration = []float64{0.1}
+	c.Metrics.Buckets.RequestSize = []float64{100}
+	c.Metrics.Buckets.ResponseSize = []float64{1000}
+	c.Observability.ExcludePaths = []string{"/metrics"}
+	c.Observability.BaggageHeaders = []string{"X-Tenant"}
+	c.Features = map[string]bool{"bulkCreate": true}
+
+	// Test secrets are masked and other fields survive
+	t.Run("Masked", func(t *testing.T) {
+		redacted := c.Redacted()
+
+		assert.Equal(t, "****", redacted.Auth.JWTSecret)
+		assert.Equal(t, "****", redacted.Auth.OAuth2ClientSecret)
+		assert.Equal(t, "****", redacted.Database.Password)
+		assert.Equal(t, "****", redacted.Metrics.Password)
+
+		assert.Equal(t, "client-id", redacted.Auth.OAuth2ClientID)
+		assert.Equal(t, "db.example.com", redacted.Database.Host)
+		assert.Equal(t, "app", redacted.Database.User)
+		assert.Equal(t, 8080, redacted.Server.Port)
+		assert.Equal(t, []string{"read"}, redacted.Auth.OAuth2Scopes)
+	})
+
+	// Test the original config is left untouched
+	t.Run("DeepCopy", func(t *testing.T) {
+		redacted := c.Redacted()
+		redacted.Auth.OAuth2Scopes[0] = "write"
+		redacted.Server.HTTPSExcludePaths[0] = "/changed"
+		redacted.Server.CacheControl["/api"] = "changed"
+		redacted.Server.TrustedProxies[0] = "changed"
+		redacted.Metrics.Buckets.Duration[0] = 9
+		redacted.Metrics.Buckets.RequestSize[0] = 9
+		redacted.Metrics.Buckets.ResponseSize[0] = 9
+		redacted.Observability.ExcludePaths[0] = "/changed"
+		redacted.Observability.BaggageHeaders[0] = "changed"
+		redacted.Features["bulkCreate"] = false
+
+		assert.Equal(t, "a-real-secret", c.Auth.JWTSecret)
+		assert.Equal(t, "db-password", c.Database.Password)
+		assert.Equal(t, []string{"read"}, c.Auth.OAuth2Scopes)
+		assert.Equal(t, []string{"/health"}, c.Server.HTTPSExcludePaths)
+		assert.Equal(t, map[string]string{"/api": "no-store"}, c.Server.CacheControl)
+		assert.Equal(t, []string{"10.0.0.0/8"}, c.Server.TrustedProxies)
+		assert.Equal(t, []float64{0.1}, c.Metrics.Buckets.Duration)
+		assert.Equal(t, []float64{100}, c.Metrics.Buckets.RequestSize)
+		assert.Equal(t, []float64{1000}, c.Metrics.Buckets.ResponseSize)
+		assert.Equal(t, []string{"/metrics"}, c.Observability.ExcludePaths)
+		assert.Equal(t, []string{"X-Tenant"}, c.Observability.BaggageHeaders)
+		assert.Equal(t, map[string]bool{"bulkCreate": true}, c.Features)
+	})
+
+	// Test String serializes every section without secrets
+	t.Run("String", func(t *testing.T) {
+		s := c.String()
+
+		assert.NotContains(t, s, "a-real-secret")
+		assert.NotContains(t, s, "client-secret")
+		assert.NotContains(t, s, "db-password")
+
+		var dump map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(s), &dump))
+		for _, section := range []string{"server", "database", "logging", "metrics", "tracing", "auth", "cache", "observability"} {
+			assert.Contains(t, dump, section)
+		}
+		assert.Equal(t, "client-id", dump["auth"].(map[string]interface{})["oauth2ClientID"])
+	})
+}
+
+// Equivalent configs in each supported file format
+const (
+	formatYAML = `
+environment: staging
+server:
+  port: 9000
+  readTimeout: 5s
+logging:
+  level: debug
+  format: text
+auth:
+  oauth2Scopes: [read, write]
+observability:
+  excludePaths: ["/health"]
+`
+
+	formatJSON = `{
+  "environment": "staging",
+  "server": {"port": 9000, "readTimeout": "5s"},
+  "logging": {"level": "debug", "format": "text"},
+  "auth": {"oauth2Scopes": ["read", "write"]},
+  "observability": {"excludePaths": ["/health"]}
+}`
+
+	formatTOML = `
+environment = "staging"
+
+[server]
+port = 9000
+readTimeout = "5s"
+
+[logging]
+level = "debug"
+format = "text"
+
+[auth]
+oauth2Scopes = ["read", "write"]
+
+[observability]
+excludePaths = ["/health"]
+`
+)
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+
+	// read writes content to a file named name and reads it back
+	read := func(t *testing.T, name, content string) (*config.Config, error) {
+		t.Helper()
+
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return config.ReadFile(path)
+	}
+
+	expected, err := read(t, "config.yaml", formatYAML)
+	require.NoError(t, err)
+
+	// Test the YAML file was read
+	t.Run("YAML", func(t *testing.T) {
+		assert.Equal(t, "staging", expected.Environment)
+		assert.Equal(t, 9000, expected.Server.Port)
+		assert.Equal(t, 5*time.Second, expected.Server.ReadTimeout)
+		assert.Equal(t, []string{"read", "write"}, expected.Auth.OAuth2Scopes)
+	})
+
+	// Test every supported format produces the same config
+	for _, tc := range []struct{ name, file, content string }{
+		{"YML", "config.yml", formatYAML},
+		{"JSON", "config.json", formatJSON},
+		{"TOML", "config.toml", formatTOML},
+	} {
+		// Test the format is parsed by its extension
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := read(t, tc.file, tc.content)
+			require.NoError(t, err)
+			assert.Equal(t, expected, cfg)
+		})
+	}
+
+	// Test an unsupported extension is rejected
+	t.Run("Unsupported", func(t *testing.T) {
+		_, err := read(t, "config.ini", "environment=staging")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported config file type")
+	})
+}
+
+func TestLoadFrom(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+server:
+  port: 9000
+logging:
+  level: debug
+  format: text
+`), 0o600))
+
+	t.Setenv("PRECEDENCE_SERVER_PORT", "9100")
+	t.Setenv("PRECEDENCE_LOGGING_LEVEL", "warn")
+
+	cfg, err := config.LoadFrom(config.Options{
+		ConfigFile: path,
+		EnvPrefix:  "PRECEDENCE",
+		Args:       []string{"--server.port=9200"},
+	})
+	require.NoError(t, err)
+
+	// Test a flag overrides the env var, which overrides the file
+	t.Run("FlagOverEnvOverFile", func(t *testing.T) {
+		assert.Equal(t, 9200, cfg.Server.Port)
+	})
+
+	// Test an env var overrides the file
+	t.Run("EnvOverFile", func(t *testing.T) {
+		assert.Equal(t, "warn", cfg.Logging.Level)
+	})
+
+	// Test the file overrides the default
+	t.Run("FileOverDefault", func(t *testing.T) {
+		assert.Equal(t, "text", cfg.Logging.Format)
+	})
+
+	// Test a key set nowhere keeps its default
+	t.Run("Default", func(t *testing.T) {
+		assert.Equal(t, "0.0.0.0", cfg.Server.Host)
+	})
+
+	// Test env vars are ignored without a prefix
+	t.Run("NoEnvPrefix", func(t *testing.T) {
+		cfg, err := config.LoadFrom(config.Options{ConfigFile: path})
+		require.NoError(t, err)
+		assert.Equal(t, 9000, cfg.Server.Port)
+		assert.Equal(t, "debug", cfg.Logging.Level)
+	})
+
+	// Test the --config flag names the file when none is given
+	t.Run("ConfigFlag", func(t *testing.T) {
+		cfg, err := config.LoadFrom(config.Options{Args: []string{"--config", path}})
+		require.NoError(t, err)
+		assert.Equal(t, 9000, cfg.Server.Port)
+	})
+
+	// Test a missing config file and an unknown flag fail
+	t.Run("Errors", func(t *testing.T) {
+		_, err := config.LoadFrom(config.Options{ConfigFile: filepath.Join(t.TempDir(), "missing.yaml")})
+		require.Error(t, err)
+
+		_, err = config.LoadFrom(config.Options{ConfigFile: path, Args: []string{"--unknown"}})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// DefaultJWTSecret is the placeholder JWT secret used when none is configured
+const DefaultJWTSecret = "your-secret-key-change-me-in-production"
+
+var (
+	// logLevels are the supported logging levels
+	logLevels = []string{"debug", "info", "warn", "error", "fatal"}
+
+	// logFormats are the supported logging formats
+	logFormats = []string{"json", "logfmt", "text"}
+
+	// tracingProtocols are the supported OTLP trace and metric exporter protocols
+	tracingProtocols = []string{"grpc", "http"}
+)
+
+// Validate checks the configuration for values that would produce a broken
+// server. It reports every problem found, each naming the offending field.
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(field, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+	}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		fail("server.port", "must be between 1 and 65535, got %d", c.Server.Port)
+	}
+
+	if c.Server.BasePath != "" && (!strings.HasPrefix(c.Server.BasePath, "/") || strings.HasSuffix(c.Server.BasePath, "/")) {
+		fail("server.basePath", "must start and not end with /, got %q", c.Server.BasePath)
+	}
+
+	if c.Server.MinCompressBytes < 0 {
+		fail("server.minCompressBytes", "must not be negative, got %d", c.Server.MinCompressBytes)
+	}
+
+	if c.Server.MaxPageSize < 0 {
+		fail("server.maxPageSize", "must not be negative, got %d", c.Server.MaxPageSize)
+	}
+
+	if c.Server.IdempotencyMaxEntries < 0 {
+		fail("server.idempotencyMaxEntries", "must not be negative, got %d", c.Server.IdempotencyMaxEntries)
+	}
+
+	if c.Server.IdempotencyMaxBodyBytes < 0 {
+		fail("server.idempotencyMaxBodyBytes", "must not be negative, got %d", c.Server.IdempotencyMaxBodyBytes)
+	}
+
+	if c.Server.MaxConcurrent < 0 {
+		fail("server.maxConcurrent", "must not be negative, got %d", c.Server.MaxConcurrent)
+	}
+
+	for _, proxy := range c.Server.TrustedProxies {
+		if !validProxy(proxy) {
+			fail("server.trustedProxies", "must be IPs or CIDRs, got %q", proxy)
+		}
+	}
+
+	if c.Server.DebugErrors && c.Environment == "production" {
+		fail("server.debugErrors", "must be disabled in production")
+	}
+
+	if c.Server.DevRoutes && c.Environment == "production" {
+		fail("server.devRoutes", "must be disabled in production")
+	}
+
+	if c.Metrics.Enabled && (c.Metrics.Port < 1 || c.Metrics.Port > 65535) {
+		fail("metrics.port", "must be between 1 and 65535, got %d", c.Metrics.Port)
+	}
+
+	if c.Metrics.Enabled && c.Metrics.AdminListener && c.Metrics.Port == c.Server.Port {
+		fail("metrics.port", "must differ from server.port when adminListener is enabled")
+	}
+
+	if c.Metrics.Enabled && c.Metrics.RequireAuth && c.Metrics.Username != "" && c.Metrics.Password == "" {
+		fail("metrics.password", "must be set when metrics.username is")
+	}
+
+	for _, b := range []struct {
+		field   string
+		buckets []float64
+	}{
+		{"metrics.buckets.duration", c.Metrics.Buckets.Duration},
+		{"metrics.buckets.requestSize", c.Metrics.Buckets.RequestSize},
+		{"metrics.buckets.responseSize", c.Metrics.Buckets.ResponseSize},
+	} {
+		if !increasing(b.buckets) {
+			fail(b.field, "must be in increasing order, got %v", b.buckets)
+		}
+	}
+
+	if !slices.Contains(logLevels, c.Logging.Level) {
+		fail("logging.level", "must be one of %v, got %q", logLevels, c.Logging.Level)
+	}
+
+	if !slices.Contains(logFormats, c.Logging.Format) {
+		fail("logging.format", "must be one of %v, got %q", logFormats, c.Logging.Format)
+	}
+
+	if c.Logging.MaxBodyLogBytes < 0 {
+		fail("logging.maxBodyLogBytes", "must not be negative, got %d", c.Logging.MaxBodyLogBytes)
+	}
+
+	if (c.Tracing.Enabled || c.Tracing.MetricsEnabled) && !slices.Contains(tracingProtocols, c.Tracing.Protocol) {
+		fail("tracing.protocol", "must be one of %v, got %q", tracingProtocols, c.Tracing.Protocol)
+	}
+
+	if c.Auth.Enabled {
+		if c.Environment == "production" && (c.Auth.JWTSecret == "" || c.Auth.JWTSecret == DefaultJWTSecret) {
+			fail("auth.jwtSecret", "must be changed from the default in production")
+		}
+
+		for _, u := range []struct{ field, value string }{
+			{"auth.oauth2AuthURL", c.Auth.OAuth2AuthURL},
+			{"auth.oauth2TokenURL", c.Auth.OAuth2TokenURL},
+			{"auth.oauth2RedirectURL", c.Auth.OAuth2RedirectURL},
+		} {
+			if err := validateURL(u.value); err != nil {
+				fail(u.field, "%v", err)
+			}
+		}
+
+		if c.Auth.OAuth2IntrospectionURL != "" {
+			if err := validateURL(c.Auth.OAuth2IntrospectionURL); err != nil {
+				fail("auth.oauth2IntrospectionURL", "%v", err)
+			}
+		}
+
+		if c.Auth.OAuth2HealthURL != "" {
+			if err := validateURL(c.Auth.OAuth2HealthURL); err != nil {
+				fail("auth.oauth2HealthURL", "%v", err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateURL checks that value is an absolute URL
+func validateURL(value string) error {
+	if value == "" {
+		return errors.New("is required when auth is enabled")
+	}
+
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("must be a valid URL: %w", err)
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("must be an absolute URL, got %q", value)
+	}
+
+	return nil
+}
+
+// validProxy reports whether s is an IP or a CIDR
+func validProxy(s string) bool {
+	if _, _, err := net.ParseCIDR(s); err == nil {
+		return true
+	}
+	return net.ParseIP(s) != nil
+}
+
+// increasing reports whether values are in strictly increasing order
+func increasing(values []float64) bool {
+	for i := 1; i < len(values); i++ {
+		if values[i] <= values[i-1] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+
+	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+)
+
+var (
+	subscribersMu sync.Mutex
+	subscribers   []*subscriber
+)
+
+// subscriber wraps a registered callback so it can be found again when
+// unsubscribing
+type subscriber struct {
+	fn func(*Config)
+}
+
+// OnChange registers fn to be called with the new configuration whenever the
+// watched config file changes and the new configuration is valid. Calling
+// the returned function removes the registration; it is safe to call more
+// than once.
+func OnChange(fn func(*Config)) (unsubscribe func()) {
+	sub := &subscriber{fn: fn}
+
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+
+	subscribers = append(subscribers, sub)
+
+	return func() {
+		subscribersMu.Lock()
+		defer subscribersMu.Unlock()
+
+		for i, s := range subscribers {
+			if s == sub {
+				subscribers = append(subscribers[:i:i], subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Watch watches the loaded config file and notifies OnChange subscribers of
+// every valid change. Invalid changes are logged and the previous
+// configuration is kept.
+func Watch() {
+	watch(viper.GetViper())
+}
+
+// watch watches the config file loaded into v
+func watch(v *viper.Viper) {
+	v.OnConfigChange(func(_ fsnotify.Event) {
+		reload(v)
+	})
+	v.WatchConfig()
+}
+
+// reload unmarshals and validates the current configuration of v and
+// notifies subscribers if it is valid
+func reload(v *viper.Viper) {
+	log := logger.Default()
+
+	var config Config
+	if err := v.Unmarshal(&config); err != nil {
+		log.Warn("ignoring config reload", logger.Error(err))
+		return
+	}
+
+	if err := config.Validate(); err != nil {
+		log.Warn("ignoring invalid config reload", logger.Error(err))
+		return
+	}
+
+	log.Info("config reloaded", logger.String("file", v.ConfigFileUsed()))
+
+	subscribersMu.Lock()
+	subs := append([]*subscriber{}, subscribers...)
+	subscribersMu.Unlock()
+
+	for _, sub := range subs {
+		sub.fn(&config)
+	}
+}
@@ -0,0 +1,102 @@
+package config_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dBiTech/go-apiTemplate/internal/config"
+)
+
+// watchConfigYAML is a minimal valid config file with a placeholder log level
+const watchConfigYAML = `
+server:
+  port: 8080
+logging:
+  level: %s
+  format: json
+metrics:
+  enabled: false
+tracing:
+  enabled: false
+auth:
+  enabled: false
+`
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(level string) {
+		data := []byte(fmt.Sprintf(watchConfigYAML, level))
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+	}
+
+	write("info")
+	viper.SetConfigFile(path)
+	require.NoError(t, viper.ReadInConfig())
+
+	changes := make(chan *config.Config, 10)
+	unsubscribe := config.OnChange(func(c *config.Config) {
+		changes <- c
+	})
+	t.Cleanup(unsubscribe)
+	config.Watch()
+
+	// waitFor waits for a reloaded config with the given log level, failing
+	// if a config with a rejected level is applied first. A single write can
+	// fire several file events, so earlier levels may be seen again.
+	waitFor := func(t *testing.T, level, rejected string) *config.Config {
+		t.Helper()
+
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case c := <-changes:
+				require.NotEqual(t, rejected, c.Logging.Level, "invalid config applied")
+				if c.Logging.Level == level {
+					return c
+				}
+			case <-timeout:
+				require.FailNow(t, "config change not observed", "level %q", level)
+				return nil
+			}
+		}
+	}
+
+	// Test a valid change notifies subscribers with the new values
+	t.Run("ValidChange", func(t *testing.T) {
+		write("debug")
+
+		c := waitFor(t, "debug", "")
+		assert.Equal(t, 8080, c.Server.Port)
+	})
+
+	// Test an invalid change is ignored and a later valid one still applies
+	t.Run("InvalidChange", func(t *testing.T) {
+		write("verbose")
+		time.Sleep(200 * time.Millisecond)
+		write("warn")
+
+		waitFor(t, "warn", "verbose")
+	})
+
+	// Test an unsubscribed callback is no longer notified
+	t.Run("Unsubscribe", func(t *testing.T) {
+		removed := make(chan *config.Config, 10)
+		unsubscribeRemoved := config.OnChange(func(c *config.Config) {
+			removed <- c
+		})
+		unsubscribeRemoved()
+		unsubscribeRemoved()
+
+		write("error")
+
+		waitFor(t, "error", "")
+		assert.Empty(t, removed)
+	})
+}
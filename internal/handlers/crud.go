@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dBiTech/go-apiTemplate/internal/models"
+	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+)
+
+// CRUDService holds the service functions behind a resource's CRUD handlers,
+// where T is the resource and R is the request body used to create and
+// update it
+type CRUDService[T any, R any] struct {
+	Get    func(ctx context.Context, id string) (*T, error)
+	List   func(ctx context.Context, limit, offset int) ([]*T, error)
+	Create func(ctx context.Context, req *R) (*T, error)
+	Update func(ctx context.Context, id string, req *R) (*T, error)
+	Delete func(ctx context.Context, id string) error
+
+	// Count returns the number of resources across all pages, sent as the
+	// total of list pages (optional)
+	Count func(ctx context.Context) (int, error)
+
+	// Validate checks a decoded request body before it's passed to Create
+	// or Update; an error is returned to the client as a 400 (optional)
+	Validate func(req *R) error
+}
+
+// CRUDHandlers are the handlers of a resource's CRUD routes. The item routes
+// read the resource ID from the "id" URL parameter.
+type CRUDHandlers struct {
+	Get    http.HandlerFunc
+	List   http.HandlerFunc
+	Create http.HandlerFunc
+	Update http.HandlerFunc
+	Delete http.HandlerFunc
+}
+
+// CRUDHandler builds the CRUD handlers of the resource called name, such as
+// "Example", from its service functions. The handlers decode request bodies
+// strictly, paginate lists, tag spans with the handler and resource ID, and
+// map service errors to status codes the same way the example handlers do.
+func CRUDHandler[T any, R any](name string, svc CRUDService[T, R], opts ...HandlerOption) CRUDHandlers {
+	h := NewHandler(nil, nil, opts...)
+	c := crud[T, R]{
+		name:        name,
+		lower:       strings.ToLower(name[:1]) + name[1:],
+		idAttribute: strings.ToLower(name) + ".id",
+		svc:         svc,
+		maxPageSize: h.maxPageSize,
+	}
+
+	return CRUDHandlers{
+		Get:    c.get,
+		List:   c.list,
+		Create: c.create,
+		Update: c.update,
+		Delete: c.delete,
+	}
+}
+
+// crud implements the handlers built by CRUDHandler
+type crud[T any, R any] struct {
+	name        string
+	lower       string
+	idAttribute string
+	svc         CRUDService[T, R]
+	maxPageSize int
+}
+
+// start tags the request's span with the handler and returns its logger
+func (c *crud[T, R]) start(r *http.Request, op string) (logger.Logger, trace.Span) {
+	span := trace.SpanFromContext(r.Context())
+	span.SetAttributes(attribute.String("handler", op+c.name))
+	return logger.FromContext(r.Context()), span
+}
+
+// id reads the resource ID from the URL and adds it to the span
+func (c *crud[T, R]) id(r *http.Request, span trace.Span) string {
+	id := chi.URLParam(r, "id")
+	span.SetAttributes(attribute.String(c.idAttribute, id))
+	return id
+}
+
+// decode reads and validates a request body, responding 400 if it's invalid
+func (c *crud[T, R]) decode(w http.ResponseWriter, r *http.Request, log logger.Logger) (*R, bool) {
+	var req R
+	if err := decodeJSON(r, &req); err != nil {
+		log.Error("failed to decode request", logger.Error(err))
+		RespondError(w, r, http.StatusBadRequest, "Invalid request", err)
+		return nil, false
+	}
+
+	if c.svc.Validate != nil {
+		if err := c.svc.Validate(&req); err != nil {
+			RespondError(w, r, http.StatusBadRequest, "Invalid request", err)
+			return nil, false
+		}
+	}
+
+	return &req, true
+}
+
+// fail logs a service error and responds with the status it maps to
+func (c *crud[T, R]) fail(w http.ResponseWriter, r *http.Request, log logger.Logger, op string, err error) {
+	log.Error("failed to "+op+" "+c.lower, logger.Error(err))
+	respondServiceError(w, r, err, c.name, op)
+}
+
+// get handles GET /{resources}/{id}
+func (c *crud[T, R]) get(w http.ResponseWriter, r *http.Request) {
+	log, span := c.start(r, "get")
+	id := c.id(r, span)
+
+	item, err := c.svc.Get(r.Context(), id)
+	if err != nil {
+		c.fail(w, r, log, "get", err)
+		return
+	}
+
+	Respond(w, r, http.StatusOK, item)
+}
+
+// list handles GET /{resources}
+func (c *crud[T, R]) list(w http.ResponseWriter, r *http.Request) {
+	log, span := c.start(r, "list")
+
+	limit, offset, err := parsePagination(r, c.maxPageSize)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid pagination parameter", err)
+		return
+	}
+	w.Header().Set(PageLimitHeader, strconv.Itoa(limit))
+	span.SetAttributes(
+		attribute.Int("limit", limit),
+		attribute.Int("offset", offset),
+	)
+
+	items, err := c.svc.List(r.Context(), limit, offset)
+	if err != nil {
+		c.fail(w, r, log, "list", err)
+		return
+	}
+
+	page := models.NewPage(items, limit, offset)
+	if c.svc.Count != nil {
+		if page.Total, err = c.svc.Count(r.Context()); err != nil {
+			c.fail(w, r, log, "count", err)
+			return
+		}
+	}
+
+	Respond(w, r, http.StatusOK, page)
+}
+
+// create handles POST /{resources}
+func (c *crud[T, R]) create(w http.ResponseWriter, r *http.Request) {
+	log, _ := c.start(r, "create")
+
+	req, ok := c.decode(w, r, log)
+	if !ok {
+		return
+	}
+
+	item, err := c.svc.Create(r.Context(), req)
+	if err != nil {
+		c.fail(w, r, log, "create", err)
+		return
+	}
+
+	Respond(w, r, http.StatusCreated, item)
+}
+
+// update handles PUT /{resources}/{id}
+func (c *crud[T, R]) update(w http.ResponseWriter, r *http.Request) {
+	log, span := c.start(r, "update")
+	id := c.id(r, span)
+
+	req, ok := c.decode(w, r, log)
+	if !ok {
+		return
+	}
+
+	item, err := c.svc.Update(r.Context(), id, req)
+	if err != nil {
+		c.fail(w, r, log, "update", err)
+		return
+	}
+
+	Respond(w, r, http.StatusOK, item)
+}
+
+// delete handles DELETE /{resources}/{id}
+func (c *crud[T, R]) delete(w http.ResponseWriter, r *http.Request) {
+	log, span := c.start(r, "delete")
+	id := c.id(r, span)
+
+	if err := c.svc.Delete(r.Context(), id); err != nil {
+		c.fail(w, r, log, "delete", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,160 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dBiTech/go-apiTemplate/internal/handlers"
+	"github.com/dBiTech/go-apiTemplate/internal/models"
+	"github.com/dBiTech/go-apiTemplate/internal/service"
+)
+
+func TestCRUDHandler(t *testing.T) {
+	mockService := new(MockService)
+	crud := handlers.CRUDHandler("Example", handlers.CRUDService[models.Example, models.ExampleRequest]{
+		Get:    mockService.GetExample,
+		List:   mockService.ListExamples,
+		Count:  mockService.CountExamples,
+		Create: mockService.CreateExample,
+		Update: mockService.UpdateExample,
+		Delete: mockService.DeleteExample,
+		Validate: func(req *models.ExampleRequest) error {
+			if req.Name == "" {
+				return errors.New("name is required")
+			}
+			return nil
+		},
+	}, handlers.WithMaxPageSize(50))
+
+	router := chi.NewRouter()
+	router.Get("/examples", crud.List)
+	router.Post("/examples", crud.Create)
+	router.Get("/examples/{id}", crud.Get)
+	router.Put("/examples/{id}", crud.Update)
+	router.Delete("/examples/{id}", crud.Delete)
+
+	// serve sends a request with an optional JSON body through the router
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	example := models.NewExample("crud-1", "Example", "Created by the CRUD handlers")
+
+	// Test Get returns the resource
+	t.Run("Get", func(t *testing.T) {
+		mockService.On("GetExample", mock.Anything, "crud-1").Return(example, nil).Once()
+
+		w := serve(http.MethodGet, "/examples/crud-1", "")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp models.Example
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "crud-1", resp.ID)
+	})
+
+	// Test Get maps a missing resource to 404
+	t.Run("GetNotFound", func(t *testing.T) {
+		mockService.On("GetExample", mock.Anything, "missing").Return(nil, service.ErrNotFound).Once()
+
+		w := serve(http.MethodGet, "/examples/missing", "")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "Example not found")
+	})
+
+	// Test List returns a page clamped to the max page size
+	t.Run("List", func(t *testing.T) {
+		mockService.On("ListExamples", mock.Anything, 50, 5).Return([]*models.Example{example}, nil).Once()
+		mockService.On("CountExamples", mock.Anything).Return(6, nil).Once()
+
+		w := serve(http.MethodGet, "/examples?limit=500&offset=5", "")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "50", w.Header().Get(handlers.PageLimitHeader))
+		var resp models.Page[*models.Example]
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Len(t, resp.Data, 1)
+		assert.Equal(t, 50, resp.Limit)
+		assert.Equal(t, 5, resp.Offset)
+		assert.Equal(t, 6, resp.Total)
+	})
+
+	// Test Create decodes the body and responds 201
+	t.Run("Create", func(t *testing.T) {
+		mockService.On("CreateExample", mock.Anything, &models.ExampleRequest{Name: "Example"}).Return(example, nil).Once()
+
+		w := serve(http.MethodPost, "/examples", `{"name":"Example"}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	// Test Create maps a conflict to 409
+	t.Run("CreateConflict", func(t *testing.T) {
+		mockService.On("CreateExample", mock.Anything, &models.ExampleRequest{Name: "Taken"}).Return(nil, service.ErrConflict).Once()
+
+		w := serve(http.MethodPost, "/examples", `{"name":"Taken"}`)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	// Test Create rejects a body that fails validation
+	t.Run("CreateInvalid", func(t *testing.T) {
+		w := serve(http.MethodPost, "/examples", `{"description":"no name"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "name is required")
+	})
+
+	// Test Create rejects unknown fields
+	t.Run("CreateUnknownField", func(t *testing.T) {
+		w := serve(http.MethodPost, "/examples", `{"name":"Example","colour":"red"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `unknown field \"colour\"`)
+	})
+
+	// Test Update passes the ID and body to the service
+	t.Run("Update", func(t *testing.T) {
+		mockService.On("UpdateExample", mock.Anything, "crud-1", &models.ExampleRequest{Name: "Renamed"}).Return(example, nil).Once()
+
+		w := serve(http.MethodPut, "/examples/crud-1", `{"name":"Renamed"}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	// Test Delete responds 204
+	t.Run("Delete", func(t *testing.T) {
+		mockService.On("DeleteExample", mock.Anything, "crud-1").Return(nil).Once()
+
+		w := serve(http.MethodDelete, "/examples/crud-1", "")
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	// Test unexpected errors map to 500
+	t.Run("DeleteFailed", func(t *testing.T) {
+		mockService.On("DeleteExample", mock.Anything, "broken").Return(errors.New("boom")).Once()
+
+		w := serve(http.MethodDelete, "/examples/broken", "")
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Body.String(), "Failed to delete example")
+	})
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// decodeJSON decodes a request body holding exactly one JSON value into v.
+// Unknown fields and trailing data are rejected, and the returned error names
+// the offending field where there is one so it can be shown to the client.
+func decodeJSON(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		return describeDecodeError(err)
+	}
+
+	// Anything but EOF after the value is trailing data
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("request body must contain a single JSON value")
+	}
+
+	return nil
+}
+
+// describeDecodeError turns a json decoding error into a message for clients
+func describeDecodeError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return errors.New("request body is empty")
+
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return errors.New("request body is truncated JSON")
+
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("malformed JSON at offset %d", syntaxErr.Offset)
+
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return fmt.Errorf("request body must be a JSON %s", jsonKind(typeErr.Type.Kind()))
+		}
+		return fmt.Errorf("field %q must be a %s", typeErr.Field, jsonKind(typeErr.Type.Kind()))
+
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no typed error for unknown fields
+		return fmt.Errorf("unknown field %s", strings.TrimPrefix(err.Error(), "json: unknown field "))
+
+	default:
+		return err
+	}
+}
+
+// jsonKind names the JSON type that decodes into a Go kind
+func jsonKind(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/dBiTech/go-apiTemplate/internal/models"
+)
+
+// exampleETag computes a strong ETag from the serialized example, its last
+// modification time and the content type it is sent as, so JSON and XML
+// representations have different ETags
+func exampleETag(example *models.Example, contentType string) (string, error) {
+	data, err := json.Marshal(example)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	hash.Write(data)
+	hash.Write([]byte(example.UpdatedAt.UTC().Format(time.RFC3339Nano)))
+	hash.Write([]byte(contentType))
+
+	return `"` + hex.EncodeToString(hash.Sum(nil)) + `"`, nil
+}
+
+// ifMatchesExample reports whether an If-Match header value matches the ETag
+// of any representation of example, as the client may have read it in
+// either content type
+func ifMatchesExample(header string, example *models.Example) (bool, error) {
+	for _, contentType := range supportedContentTypes {
+		etag, err := exampleETag(example, contentType)
+		if err != nil {
+			return false, err
+		}
+		if etagMatchesStrong(header, etag) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// etagMatchesWeak reports whether an If-None-Match header value matches the
+// given ETag using weak comparison, which ignores W/ prefixes. The header may
+// be "*" or a comma-separated list of ETags.
+func etagMatchesWeak(header, etag string) bool {
+	return etagMatches(header, etag, false)
+}
+
+// etagMatchesStrong reports whether an If-Match header value matches the
+// given ETag using strong comparison, under which a weak ETag never matches.
+// The header may be "*" or a comma-separated list of ETags.
+func etagMatchesStrong(header, etag string) bool {
+	return etagMatches(header, etag, true)
+}
+
+// etagMatches compares a conditional header value against etag as described
+// in RFC 9110 section 8.8.3.2
+func etagMatches(header, etag string, strong bool) bool {
+	if header == "" {
+		return false
+	}
+
+	if strong && strings.HasPrefix(etag, "W/") {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if strong {
+				continue
+			}
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
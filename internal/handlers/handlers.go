@@ -3,10 +3,14 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"go.opentelemetry.io/otel/attribute"
@@ -21,39 +25,136 @@ import (
 
 // Handler provides HTTP handlers
 type Handler struct {
-	log     logger.Logger
-	service service.Interface
+	log         logger.Logger
+	service     service.Interface
+	maxPageSize int
+	createOnPut bool
+	events      EventSubscriber
+}
+
+// defaultMaxPageSize is the largest page a list endpoint returns unless
+// overridden with WithMaxPageSize
+const defaultMaxPageSize = 100
+
+// PageLimitHeader reports the effective page size of list responses
+const PageLimitHeader = "X-Page-Limit"
+
+// HandlerOption configures optional settings of a Handler
+type HandlerOption func(*Handler)
+
+// WithMaxPageSize caps the limit list endpoints accept; larger limits are clamped
+func WithMaxPageSize(n int) HandlerOption {
+	return func(h *Handler) {
+		if n > 0 {
+			h.maxPageSize = n
+		}
+	}
+}
+
+// WithCreateOnPut makes PUT /examples/{id} create the example when the ID
+// doesn't exist, responding 201, instead of responding 404
+func WithCreateOnPut(enabled bool) HandlerOption {
+	return func(h *Handler) {
+		h.createOnPut = enabled
+	}
+}
+
+// WithEventSubscriber streams the events of subscriber to clients of
+// GET /examples/watch
+func WithEventSubscriber(subscriber EventSubscriber) HandlerOption {
+	return func(h *Handler) {
+		h.events = subscriber
+	}
 }
 
 // NewHandler creates a new handler instance
-func NewHandler(log logger.Logger, service service.Interface) *Handler {
-	return &Handler{
-		log:     log,
-		service: service,
+func NewHandler(log logger.Logger, service service.Interface, opts ...HandlerOption) *Handler {
+	h := &Handler{
+		log:         log,
+		service:     service,
+		maxPageSize: defaultMaxPageSize,
+	}
+
+	for _, opt := range opts {
+		opt(h)
 	}
+
+	return h
 }
 
 // ErrorResponse represents an error response
 type ErrorResponse struct {
-	Status  int    `json:"status"`
-	Message string `json:"message"`
-	Error   string `json:"error,omitempty"`
+	XMLName   xml.Name `json:"-" xml:"error"`
+	Status    int      `json:"status" xml:"status"`
+	Message   string   `json:"message" xml:"message"`
+	Error     string   `json:"error,omitempty" xml:"error,omitempty"`
+	RequestID string   `json:"requestId,omitempty" xml:"requestId,omitempty"`
+	TraceID   string   `json:"traceId,omitempty" xml:"traceId,omitempty"`
+
+	// Fields lists per-field problems of a request that failed validation
+	Fields []FieldError `json:"fields,omitempty" xml:"fields>field,omitempty"`
+}
+
+// FieldError describes why a single request field is invalid
+type FieldError struct {
+	Field   string `json:"field" xml:"field"`
+	Message string `json:"message" xml:"message"`
+}
+
+// Respond sends a response encoded as JSON or XML according to the request's
+// Accept header, defaulting to JSON. It responds 406 if neither is acceptable.
+func Respond(w http.ResponseWriter, r *http.Request, status int, payload interface{}) {
+	w.Header().Add("Vary", "Accept")
+
+	contentType, ok := negotiateContentType(r.Header.Get("Accept"))
+	if !ok {
+		RespondError(w, r, http.StatusNotAcceptable, "Not Acceptable", nil)
+		return
+	}
+
+	var response []byte
+	var err error
+	if contentType == contentTypeXML {
+		response, err = marshalXML(payload)
+	} else {
+		response, err = json.Marshal(payload)
+	}
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeResponse(w, status, contentType, response)
 }
 
-// RespondJSON sends a JSON response
-func RespondJSON(w http.ResponseWriter, status int, payload interface{}) {
+// RespondJSON sends a JSON response. The payload is encoded before anything is
+// written, so a payload that can't be encoded produces a single 500 instead.
+func RespondJSON(w http.ResponseWriter, r *http.Request, status int, payload interface{}) {
 	response, err := json.Marshal(payload)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		_, writeErr := w.Write([]byte(`{"status":500,"message":"Internal Server Error"}`))
-		if writeErr != nil {
-			// Just log to stdout if the logger isn't available
-			fmt.Printf("Failed to write error response: %v\n", writeErr)
-		}
+		writeInternalError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
+	writeResponse(w, status, contentTypeJSON, response)
+}
+
+// writeInternalError logs why a payload couldn't be encoded and sends a 500
+// error response in its place
+func writeInternalError(w http.ResponseWriter, r *http.Request, err error) {
+	logger.FromContext(r.Context()).Error("failed to encode response", logger.Error(err))
+
+	response, marshalErr := json.Marshal(newErrorResponse(r, http.StatusInternalServerError, "Internal Server Error"))
+	if marshalErr != nil {
+		response = []byte(`{"status":500,"message":"Internal Server Error"}`)
+	}
+
+	writeResponse(w, http.StatusInternalServerError, contentTypeJSON, response)
+}
+
+// writeResponse writes an encoded response body
+func writeResponse(w http.ResponseWriter, status int, contentType string, response []byte) {
+	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(status)
 	_, writeErr := w.Write(response)
 	if writeErr != nil {
@@ -62,20 +163,117 @@ func RespondJSON(w http.ResponseWriter, status int, payload interface{}) {
 	}
 }
 
-// RespondError sends an error response
-func RespondError(w http.ResponseWriter, status int, message string, err error) {
-	errorMsg := ""
+// RespondError sends an error response carrying the request and trace IDs so
+// clients can report them
+func RespondError(w http.ResponseWriter, r *http.Request, status int, message string, err error) {
+	response := newErrorResponse(r, status, message)
 	if err != nil {
-		errorMsg = err.Error()
+		response.Error = err.Error()
 	}
 
+	RespondJSON(w, r, status, response)
+}
+
+// statusForError maps an error from the service to the HTTP status it is
+// reported with
+func statusForError(err error) int {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, service.ErrInternal):
+		return http.StatusInternalServerError
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// respondServiceError responds to a failed service call with the status
+// statusForError maps err to. name is the resource, such as "Example", and op
+// the operation, used to describe internal errors without exposing them.
+func respondServiceError(w http.ResponseWriter, r *http.Request, err error, name, op string) {
+	switch status := statusForError(err); status {
+	case http.StatusNotFound:
+		RespondError(w, r, status, name+" not found", nil)
+	case http.StatusConflict:
+		RespondError(w, r, status, name+" already exists", nil)
+	case http.StatusBadRequest:
+		RespondError(w, r, status, "Invalid request", err)
+	case http.StatusPreconditionFailed:
+		RespondError(w, r, status, name+" has been modified", nil)
+	case http.StatusGatewayTimeout:
+		RespondError(w, r, status, "Request timed out", nil)
+	default:
+		RespondError(w, r, status, "Failed to "+op+" "+strings.ToLower(name[:1])+name[1:], nil)
+	}
+}
+
+// RespondValidationError sends a 400 response listing every invalid field
+func RespondValidationError(w http.ResponseWriter, r *http.Request, fields []FieldError) {
+	response := newErrorResponse(r, http.StatusBadRequest, "Validation failed")
+	response.Fields = fields
+
+	RespondJSON(w, r, http.StatusBadRequest, response)
+}
+
+// newErrorResponse builds an error response with the request and trace IDs
+func newErrorResponse(r *http.Request, status int, message string) ErrorResponse {
 	response := ErrorResponse{
-		Status:  status,
-		Message: message,
-		Error:   errorMsg,
+		Status:    status,
+		Message:   message,
+		RequestID: r.Header.Get("X-Request-ID"),
+	}
+
+	if spanCtx := trace.SpanContextFromContext(r.Context()); spanCtx.HasTraceID() {
+		response.TraceID = spanCtx.TraceID().String()
 	}
 
-	RespondJSON(w, status, response)
+	return response
+}
+
+// allowedMethods lists the methods routed for the request's path
+var allowedMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+// NotFoundHandler responds to unmatched routes with a JSON 404
+func NotFoundHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		RespondError(w, r, http.StatusNotFound, "Not Found", nil)
+	}
+}
+
+// MethodNotAllowedHandler responds to routes matched with an unsupported
+// method with a JSON 405 and an Allow header listing the supported methods
+func MethodNotAllowedHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.Routes != nil {
+			var allow []string
+			for _, method := range allowedMethods {
+				if rctx.Routes.Match(chi.NewRouteContext(), method, r.URL.Path) {
+					allow = append(allow, method)
+				}
+			}
+			if len(allow) > 0 {
+				w.Header().Set("Allow", strings.Join(allow, ", "))
+			}
+		}
+
+		RespondError(w, r, http.StatusMethodNotAllowed, "Method Not Allowed", nil)
+	}
 }
 
 // HelloHandler is a simple example handler
@@ -101,7 +299,7 @@ func (h *Handler) HelloHandler() http.HandlerFunc {
 			"message": "Hello, World!",
 		}
 
-		RespondJSON(w, http.StatusOK, response)
+		RespondJSON(w, r, http.StatusOK, response)
 	}
 }
 
@@ -110,9 +308,11 @@ func (h *Handler) HelloHandler() http.HandlerFunc {
 // @Description Retrieves a single example by its ID
 // @Tags examples
 // @Accept json
-// @Produce json
+// @Produce json,xml
 // @Param id path string true "Example ID"
+// @Param If-None-Match header string false "ETag of a cached representation"
 // @Success 200 {object} models.Example "Successfully retrieved example"
+// @Success 304 "Example not modified"
 // @Failure 404 {object} ErrorResponse "Example not found"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /examples/{id} [get]
@@ -133,29 +333,114 @@ func (h *Handler) GetExampleHandler() http.HandlerFunc {
 		example, err := h.service.GetExample(ctx, id)
 		if err != nil {
 			log.Error("failed to get example", logger.String("id", id), logger.Error(err))
+			respondServiceError(w, r, err, "Example", "get")
+			return
+		}
 
-			if err == repository.ErrNotFound {
-				RespondError(w, http.StatusNotFound, "Example not found", nil)
-			} else {
-				RespondError(w, http.StatusInternalServerError, "Failed to get example", nil)
-			}
+		// Set the ETag of the negotiated representation and honor
+		// conditional requests
+		contentType, _ := negotiateContentType(r.Header.Get("Accept"))
+		etag, err := exampleETag(example, contentType)
+		if err != nil {
+			log.Error("failed to compute etag", logger.String("id", id), logger.Error(err))
+			RespondError(w, r, http.StatusInternalServerError, "Failed to get example", nil)
+			return
+		}
+		w.Header().Set("ETag", etag)
+
+		if etagMatchesWeak(r.Header.Get("If-None-Match"), etag) {
+			// Respond sets Vary otherwise
+			w.Header().Add("Vary", "Accept")
+			w.WriteHeader(http.StatusNotModified)
 			return
 		}
 
 		// Respond with example
-		RespondJSON(w, http.StatusOK, example)
+		Respond(w, r, http.StatusOK, example)
+	}
+}
+
+// BatchGetExamplesHandler handles POST /examples/batch-get
+// @Summary Get examples by ID
+// @Description Retrieves many examples by ID in one request. Found examples are returned in request order and unknown IDs are listed as missing.
+// @Tags examples
+// @Accept json
+// @Produce json,xml
+// @Param request body models.BatchGetRequest true "IDs to get, at most the server's max page size"
+// @Success 200 {object} models.BatchGetResponse "Found examples and missing IDs"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 415 {object} ErrorResponse "Content-Type must be application/json"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /examples/batch-get [post]
+func (h *Handler) BatchGetExamplesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		log := logger.FromContext(ctx)
+
+		// Get span and add attributes
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(attribute.String("handler", "batchGetExamples"))
+
+		// Parse request body
+		var req models.BatchGetRequest
+		if err := decodeJSON(r, &req); err != nil {
+			log.Error("failed to decode request", logger.Error(err))
+			RespondError(w, r, http.StatusBadRequest, "Invalid request", err)
+			return
+		}
+
+		// Drop repeated IDs, keeping the first occurrence's position
+		ids := make([]string, 0, len(req.IDs))
+		seen := make(map[string]bool, len(req.IDs))
+		for _, id := range req.IDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+
+		if len(ids) > h.maxPageSize {
+			RespondError(w, r, http.StatusBadRequest, fmt.Sprintf("At most %d IDs may be requested", h.maxPageSize), nil)
+			return
+		}
+		span.SetAttributes(attribute.Int("count", len(ids)))
+
+		// Get examples
+		found, err := h.service.GetExamplesByIDs(ctx, ids)
+		if err != nil {
+			log.Error("failed to get examples by IDs", logger.Error(err))
+			respondServiceError(w, r, err, "Example", "get")
+			return
+		}
+
+		// Respond in request order, listing the IDs that weren't found
+		response := models.BatchGetResponse{
+			Examples: make([]*models.Example, 0, len(found)),
+			Missing:  []string{},
+		}
+		for _, id := range ids {
+			if example, ok := found[id]; ok {
+				response.Examples = append(response.Examples, example)
+			} else {
+				response.Missing = append(response.Missing, id)
+			}
+		}
+
+		Respond(w, r, http.StatusOK, response)
 	}
 }
 
 // ListExamplesHandler handles GET /examples
 // @Summary List examples
-// @Description Returns a list of examples with optional pagination
+// @Description Returns a list of examples ordered by creation time, then ID, with optional pagination
 // @Tags examples
 // @Accept json
-// @Produce json
-// @Param limit query int false "Maximum number of results to return" default(10)
+// @Produce json,xml
+// @Param limit query int false "Maximum number of results to return, clamped to the server's max page size" default(10)
 // @Param offset query int false "Number of items to skip" default(0)
-// @Success 200 {array} models.Example "Successfully retrieved examples"
+// @Param cursor query string false "Opaque cursor from a previous page's next_cursor; an empty value starts from the first page"
+// @Success 200 {object} models.Page[models.Example] "Successfully retrieved a page of examples"
+// @Failure 400 {object} ErrorResponse "Invalid cursor or pagination parameter"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /examples [get]
 func (h *Handler) ListExamplesHandler() http.HandlerFunc {
@@ -168,19 +453,37 @@ func (h *Handler) ListExamplesHandler() http.HandlerFunc {
 		span.SetAttributes(attribute.String("handler", "listExamples"))
 
 		// Parse query parameters
-		limit := 10
-		offset := 0
-
-		if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-				limit = l
-			}
+		limit, offset, err := h.parsePagination(r)
+		if err != nil {
+			RespondError(w, r, http.StatusBadRequest, "Invalid pagination parameter", err)
+			return
 		}
-
-		if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
-			if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-				offset = o
+		w.Header().Set(PageLimitHeader, strconv.Itoa(limit))
+
+		// Use cursor pagination when a cursor is given, even an empty one
+		if query := r.URL.Query(); query.Has("cursor") {
+			cursor := query.Get("cursor")
+			span.SetAttributes(
+				attribute.Int("limit", limit),
+				attribute.String("cursor", cursor),
+			)
+
+			examples, nextCursor, err := h.service.ListExamplesAfter(ctx, cursor, limit)
+			if err != nil {
+				log.Error("failed to list examples", logger.Error(err))
+
+				if statusForError(err) == http.StatusBadRequest {
+					RespondError(w, r, http.StatusBadRequest, "Invalid cursor", nil)
+				} else {
+					respondServiceError(w, r, err, "Examples", "list")
+				}
+				return
 			}
+
+			page := models.NewPage(examples, limit, 0)
+			page.NextCursor = nextCursor
+			Respond(w, r, http.StatusOK, page)
+			return
 		}
 
 		span.SetAttributes(
@@ -192,12 +495,129 @@ func (h *Handler) ListExamplesHandler() http.HandlerFunc {
 		examples, err := h.service.ListExamples(ctx, limit, offset)
 		if err != nil {
 			log.Error("failed to list examples", logger.Error(err))
-			RespondError(w, http.StatusInternalServerError, "Failed to list examples", nil)
+			respondServiceError(w, r, err, "Examples", "list")
+			return
+		}
+		total, err := h.service.CountExamples(ctx)
+		if err != nil {
+			log.Error("failed to count examples", logger.Error(err))
+			respondServiceError(w, r, err, "Examples", "count")
 			return
 		}
 
-		// Respond with examples
-		RespondJSON(w, http.StatusOK, examples)
+		// Respond with a page of examples
+		page := models.NewPage(examples, limit, offset)
+		page.Total = total
+		Respond(w, r, http.StatusOK, page)
+	}
+}
+
+// SearchExamplesHandler handles GET /examples/search
+// @Summary Search examples
+// @Description Returns the examples whose name or description contains the search term, ignoring case, most relevant first
+// @Tags examples
+// @Accept json
+// @Produce json,xml
+// @Param q query string true "Search term"
+// @Param limit query int false "Maximum number of results to return, clamped to the server's max page size" default(10)
+// @Success 200 {object} models.Page[models.Example] "Matching examples, most relevant first"
+// @Failure 400 {object} ErrorResponse "Missing search term or invalid limit"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /examples/search [get]
+func (h *Handler) SearchExamplesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		log := logger.FromContext(ctx)
+
+		// Get span and add attributes
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(attribute.String("handler", "searchExamples"))
+
+		query := r.URL.Query()
+		term := strings.TrimSpace(query.Get("q"))
+		if term == "" {
+			RespondError(w, r, http.StatusBadRequest, "Missing search term", nil)
+			return
+		}
+
+		limit, err := parsePaginationParam(query.Get("limit"), "limit", 10, 1)
+		if err != nil {
+			RespondError(w, r, http.StatusBadRequest, "Invalid pagination parameter", err)
+			return
+		}
+		if limit > h.maxPageSize {
+			limit = h.maxPageSize
+		}
+		w.Header().Set(PageLimitHeader, strconv.Itoa(limit))
+
+		span.SetAttributes(
+			attribute.String("query", term),
+			attribute.Int("limit", limit),
+		)
+
+		examples, err := h.service.SearchExamples(ctx, term, limit)
+		if err != nil {
+			log.Error("failed to search examples", logger.Error(err))
+			respondServiceError(w, r, err, "Example", "search")
+			return
+		}
+
+		Respond(w, r, http.StatusOK, models.NewPage(examples, limit, 0))
+	}
+}
+
+// ExportExamplesHandler handles GET /examples/export
+// @Summary Export examples
+// @Description Streams every example as newline-delimited JSON
+// @Tags examples
+// @Produce application/x-ndjson
+// @Success 200 {object} models.Example "One example per line"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /examples/export [get]
+func (h *Handler) ExportExamplesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		log := logger.FromContext(ctx)
+
+		// Get span and add attributes
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(attribute.String("handler", "exportExamples"))
+
+		flusher, _ := w.(http.Flusher)
+		encoder := json.NewEncoder(w)
+		started := false
+
+		// Stream each example as its own line, flushing so clients see
+		// records as they're written
+		err := h.service.ExportExamples(ctx, func(example *models.Example) error {
+			if !started {
+				w.Header().Set("Content-Type", contentTypeNDJSON)
+				w.WriteHeader(http.StatusOK)
+				started = true
+			}
+			if err := encoder.Encode(example); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+			return nil
+		})
+		if err != nil {
+			log.Error("failed to export examples", logger.Error(err))
+
+			// Once streaming has started the status can't change, so just stop
+			if !started {
+				respondServiceError(w, r, err, "Examples", "export")
+			}
+			return
+		}
+
+		// An empty export is still a valid, empty stream
+		if !started {
+			w.Header().Set("Content-Type", contentTypeNDJSON)
+			w.WriteHeader(http.StatusOK)
+		}
 	}
 }
 
@@ -206,11 +626,12 @@ func (h *Handler) ListExamplesHandler() http.HandlerFunc {
 // @Description Creates a new example resource
 // @Tags examples
 // @Accept json
-// @Produce json
+// @Produce json,xml
 // @Param example body models.ExampleRequest true "Example data"
 // @Success 201 {object} models.Example "Successfully created example"
 // @Failure 400 {object} ErrorResponse "Invalid request"
 // @Failure 409 {object} ErrorResponse "Example already exists"
+// @Failure 415 {object} ErrorResponse "Content-Type must be application/json"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /examples [post]
 func (h *Handler) CreateExampleHandler() http.HandlerFunc {
@@ -224,15 +645,15 @@ func (h *Handler) CreateExampleHandler() http.HandlerFunc {
 
 		// Parse request body
 		var req models.ExampleRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if err := decodeJSON(r, &req); err != nil {
 			log.Error("failed to decode request", logger.Error(err))
-			RespondError(w, http.StatusBadRequest, "Invalid request", err)
+			RespondError(w, r, http.StatusBadRequest, "Invalid request", err)
 			return
 		}
 
 		// Validate request
 		if req.Name == "" {
-			RespondError(w, http.StatusBadRequest, "Name is required", nil)
+			RespondError(w, r, http.StatusBadRequest, "Name is required", nil)
 			return
 		}
 
@@ -240,31 +661,166 @@ func (h *Handler) CreateExampleHandler() http.HandlerFunc {
 		example, err := h.service.CreateExample(ctx, &req)
 		if err != nil {
 			log.Error("failed to create example", logger.Error(err))
+			respondServiceError(w, r, err, "Example", "create")
+			return
+		}
 
-			if err == repository.ErrAlreadyExists {
-				RespondError(w, http.StatusConflict, "Example already exists", nil)
-			} else {
-				RespondError(w, http.StatusInternalServerError, "Failed to create example", nil)
+		// Respond with created example
+		Respond(w, r, http.StatusCreated, example)
+	}
+}
+
+// BulkCreateExamplesHandler handles POST /examples/bulk
+// @Summary Bulk create examples
+// @Description Creates many examples in one request and reports a result per item. With atomic=true any failure rolls back the whole batch.
+// @Tags examples
+// @Accept json
+// @Produce json,xml
+// @Param examples body []models.ExampleRequest true "Examples to create, at most the server's max page size"
+// @Param atomic query bool false "Roll back all items if any item fails"
+// @Success 207 {array} models.BulkCreateItemResult "Per-item results"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 415 {object} ErrorResponse "Content-Type must be application/json"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /examples/bulk [post]
+func (h *Handler) BulkCreateExamplesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		log := logger.FromContext(ctx)
+
+		// Get span and add attributes
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(attribute.String("handler", "bulkCreateExamples"))
+
+		// Parse atomic flag
+		atomic := false
+		if atomicStr := r.URL.Query().Get("atomic"); atomicStr != "" {
+			var err error
+			atomic, err = strconv.ParseBool(atomicStr)
+			if err != nil {
+				RespondError(w, r, http.StatusBadRequest, "Invalid atomic parameter", err)
+				return
 			}
+		}
+
+		// Parse request body
+		var reqs []*models.ExampleRequest
+		if err := decodeJSON(r, &reqs); err != nil {
+			log.Error("failed to decode request", logger.Error(err))
+			RespondError(w, r, http.StatusBadRequest, "Invalid request", err)
 			return
 		}
 
-		// Respond with created example
-		RespondJSON(w, http.StatusCreated, example)
+		if len(reqs) == 0 {
+			RespondError(w, r, http.StatusBadRequest, "At least one example is required", nil)
+			return
+		}
+		if len(reqs) > h.maxPageSize {
+			RespondError(w, r, http.StatusBadRequest, fmt.Sprintf("At most %d examples may be created at once", h.maxPageSize), nil)
+			return
+		}
+
+		// Create examples
+		results, err := h.service.BulkCreateExamples(ctx, reqs, atomic)
+		if err != nil {
+			log.Error("failed to bulk create examples", logger.Error(err))
+			respondServiceError(w, r, err, "Examples", "create")
+			return
+		}
+
+		// Respond with per-item results
+		items := make([]models.BulkCreateItemResult, len(results))
+		for i, result := range results {
+			items[i] = bulkCreateItemResult(i, result)
+		}
+
+		Respond(w, r, http.StatusMultiStatus, items)
+	}
+}
+
+// bulkCreateItemResult maps a service bulk result to its response item
+func bulkCreateItemResult(index int, result service.BulkResult) models.BulkCreateItemResult {
+	item := models.BulkCreateItemResult{Index: index}
+
+	switch {
+	case result.Err == nil:
+		item.Status = http.StatusCreated
+		item.Example = result.Example
+	case errors.Is(result.Err, service.ErrRolledBack):
+		item.Status = http.StatusFailedDependency
+		item.Error = "Rolled back because another item failed"
+	default:
+		item.Status = statusForError(result.Err)
+		switch item.Status {
+		case http.StatusBadRequest:
+			item.Error = result.Err.Error()
+		case http.StatusConflict:
+			item.Error = "Example already exists"
+		default:
+			item.Error = "Failed to create example"
+		}
+	}
+
+	return item
+}
+
+// ArchiveExamplesHandler handles POST /examples/archive
+// @Summary Archive examples
+// @Description Sets the status of every example matching the filter to archived and reports how many changed. The filter needs a status (active or inactive), an olderThan creation cutoff, or both.
+// @Tags examples
+// @Accept json
+// @Produce json,xml
+// @Param filter body models.ArchiveFilter true "Examples to archive"
+// @Success 200 {object} models.ArchiveResponse "Number of examples archived"
+// @Failure 400 {object} ErrorResponse "Invalid filter"
+// @Failure 415 {object} ErrorResponse "Content-Type must be application/json"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /examples/archive [post]
+func (h *Handler) ArchiveExamplesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		log := logger.FromContext(ctx)
+
+		// Get span and add attributes
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(attribute.String("handler", "archiveExamples"))
+
+		// Parse request body
+		var filter models.ArchiveFilter
+		if err := decodeJSON(r, &filter); err != nil {
+			log.Error("failed to decode request", logger.Error(err))
+			RespondError(w, r, http.StatusBadRequest, "Invalid request", err)
+			return
+		}
+
+		count, err := h.service.ArchiveExamples(ctx, filter)
+		if err != nil {
+			log.Error("failed to archive examples", logger.Error(err))
+			respondServiceError(w, r, err, "Example", "archive")
+			return
+		}
+
+		span.SetAttributes(attribute.Int("archived", count))
+		Respond(w, r, http.StatusOK, models.ArchiveResponse{Archived: count})
 	}
 }
 
 // UpdateExampleHandler handles PUT /examples/{id}
 // @Summary Update example
-// @Description Updates an existing example by ID
+// @Description Updates an existing example by ID, or creates it if create-on-PUT is enabled
 // @Tags examples
 // @Accept json
-// @Produce json
+// @Produce json,xml
 // @Param id path string true "Example ID"
 // @Param example body models.ExampleRequest true "Example data"
+// @Param If-Match header string false "ETag the update is conditional on"
 // @Success 200 {object} models.Example "Successfully updated example"
+// @Success 201 {object} models.Example "Successfully created example"
 // @Failure 400 {object} ErrorResponse "Invalid request"
 // @Failure 404 {object} ErrorResponse "Example not found"
+// @Failure 409 {object} ErrorResponse "Example already exists"
+// @Failure 412 {object} ErrorResponse "Example has been modified"
+// @Failure 415 {object} ErrorResponse "Content-Type must be application/json"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /examples/{id} [put]
 func (h *Handler) UpdateExampleHandler() http.HandlerFunc {
@@ -282,33 +838,70 @@ func (h *Handler) UpdateExampleHandler() http.HandlerFunc {
 
 		// Parse request body
 		var req models.ExampleRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if err := decodeJSON(r, &req); err != nil {
 			log.Error("failed to decode request", logger.Error(err))
-			RespondError(w, http.StatusBadRequest, "Invalid request", err)
+			RespondError(w, r, http.StatusBadRequest, "Invalid request", err)
 			return
 		}
 
 		// Validate request
 		if req.Name == "" {
-			RespondError(w, http.StatusBadRequest, "Name is required", nil)
+			RespondError(w, r, http.StatusBadRequest, "Name is required", nil)
 			return
 		}
 
-		// Update example
-		example, err := h.service.UpdateExample(ctx, id, &req)
-		if err != nil {
-			log.Error("failed to update example", logger.String("id", id), logger.Error(err))
+		// Update example, conditionally on the version the client has or
+		// creating it if allowed
+		var (
+			example *models.Example
+			created bool
+			err     error
+		)
+		if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+			current, getErr := h.service.GetExample(ctx, id)
+			if getErr != nil {
+				log.Error("failed to get example for update", logger.String("id", id), logger.Error(getErr))
+				respondServiceError(w, r, getErr, "Example", "update")
+				return
+			}
 
-			if err == repository.ErrNotFound {
-				RespondError(w, http.StatusNotFound, "Example not found", nil)
-			} else {
-				RespondError(w, http.StatusInternalServerError, "Failed to update example", nil)
+			matches, etagErr := ifMatchesExample(ifMatch, current)
+			if etagErr != nil {
+				log.Error("failed to compute etag", logger.String("id", id), logger.Error(etagErr))
+				RespondError(w, r, http.StatusInternalServerError, "Failed to update example", nil)
+				return
+			}
+			if !matches {
+				RespondError(w, r, http.StatusPreconditionFailed, "Example has been modified", nil)
+				return
 			}
+
+			// The service checks the version again as it writes, in case
+			// the example changed since it was read
+			example, err = h.service.UpdateExampleIfUnmodified(ctx, id, &req, current.UpdatedAt)
+		} else if h.createOnPut {
+			example, created, err = h.service.UpsertExample(ctx, id, &req)
+		} else {
+			example, err = h.service.UpdateExample(ctx, id, &req)
+		}
+		if err != nil {
+			log.Error("failed to update example", logger.String("id", id), logger.Error(err))
+			respondServiceError(w, r, err, "Example", "update")
 			return
 		}
 
+		// Set the ETag of the updated representation
+		contentType, _ := negotiateContentType(r.Header.Get("Accept"))
+		if etag, err := exampleETag(example, contentType); err == nil {
+			w.Header().Set("ETag", etag)
+		}
+
 		// Respond with updated example
-		RespondJSON(w, http.StatusOK, example)
+		status := http.StatusOK
+		if created {
+			status = http.StatusCreated
+		}
+		Respond(w, r, status, example)
 	}
 }
 
@@ -340,11 +933,41 @@ func (h *Handler) DeleteExampleHandler() http.HandlerFunc {
 		err := h.service.DeleteExample(ctx, id)
 		if err != nil {
 			log.Error("failed to delete example", logger.String("id", id), logger.Error(err))
+			respondServiceError(w, r, err, "Example", "delete")
+			return
+		}
+
+		// Respond with no content
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
 
-			if err == repository.ErrNotFound {
-				RespondError(w, http.StatusNotFound, "Example not found", nil)
+// ResetExamplesHandler handles DELETE /examples
+// @Summary Delete all examples
+// @Description Deletes every example. Only registered when server.devRoutes is enabled.
+// @Tags examples
+// @Produce json
+// @Success 204 "Successfully deleted all examples"
+// @Failure 501 {object} ErrorResponse "Repository does not support reset"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /examples [delete]
+func (h *Handler) ResetExamplesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		log := logger.FromContext(ctx)
+
+		// Get span and add attributes
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(attribute.String("handler", "resetExamples"))
+
+		// Reset examples
+		if err := h.service.ResetExamples(ctx); err != nil {
+			log.Error("failed to reset examples", logger.Error(err))
+
+			if errors.Is(err, repository.ErrNotResettable) {
+				RespondError(w, r, http.StatusNotImplemented, "Reset not supported", nil)
 			} else {
-				RespondError(w, http.StatusInternalServerError, "Failed to delete example", nil)
+				RespondError(w, r, http.StatusInternalServerError, "Failed to reset examples", nil)
 			}
 			return
 		}
@@ -354,14 +977,68 @@ func (h *Handler) DeleteExampleHandler() http.HandlerFunc {
 	}
 }
 
+// parsePagination reads the limit and offset query parameters, clamping the
+// limit to the handler's maximum page size
+func (h *Handler) parsePagination(r *http.Request) (limit, offset int, err error) {
+	return parsePagination(r, h.maxPageSize)
+}
+
+// parsePagination reads the limit and offset query parameters, defaulting to
+// a limit of 10 and an offset of 0 when they're missing. The limit is clamped
+// to maxPageSize. Values that aren't integers, are out of range, or are below
+// the minimum (1 for limit, 0 for offset) are an error.
+func parsePagination(r *http.Request, maxPageSize int) (limit, offset int, err error) {
+	query := r.URL.Query()
+
+	limit, err = parsePaginationParam(query.Get("limit"), "limit", 10, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	offset, err = parsePaginationParam(query.Get("offset"), "offset", 0, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	return limit, offset, nil
+}
+
+// parsePaginationParam parses a pagination query value, returning def when
+// it's empty
+func parsePaginationParam(value, name string, def, minimum int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	switch {
+	case errors.Is(err, strconv.ErrRange):
+		return 0, fmt.Errorf("%s is out of range: %q", name, value)
+	case err != nil:
+		return 0, fmt.Errorf("%s must be an integer, got %q", name, value)
+	case n < minimum:
+		return 0, fmt.Errorf("%s must be at least %d, got %d", name, minimum, n)
+	}
+
+	return n, nil
+}
+
 // JWTProtectedResourceHandler handles GET /protected/jwt
 // @Summary Get JWT protected resources
-// @Description Returns a list of resources that require JWT authentication
+// @Description Returns the resources that require JWT authentication, limited to those the token's scopes grant
 // @Tags protected
 // @Accept json
-// @Produce json
+// @Produce json,xml
 // @Security BearerAuth
-// @Success 200 {array} models.ProtectedResource "Successfully retrieved protected resources"
+// @Param limit query int false "Limit, clamped to the server's max page size" default(10)
+// @Param offset query int false "Offset" default(0)
+// @Param ownerId query string false "Only return resources owned by this user"
+// @Success 200 {object} models.Page[models.ProtectedResource] "Successfully retrieved protected resources"
+// @Failure 400 {object} ErrorResponse "Invalid pagination parameter"
 // @Failure 401 {string} string "Unauthorized"
 // @Failure 403 {string} string "Forbidden: insufficient scope"
 // @Failure 500 {object} ErrorResponse "Internal server error"
@@ -377,27 +1054,40 @@ func (h *Handler) JWTProtectedResourceHandler() http.HandlerFunc {
 		span := trace.SpanFromContext(ctx)
 		span.SetAttributes(attribute.String("handler", "jwtProtectedResource"))
 
+		// Parse query parameters
+		limit, offset, err := h.parsePagination(r)
+		if err != nil {
+			RespondError(w, r, http.StatusBadRequest, "Invalid pagination parameter", err)
+			return
+		}
+		ownerID := r.URL.Query().Get("ownerId")
+		w.Header().Set(PageLimitHeader, strconv.Itoa(limit))
+
 		// Get resources
-		resources, err := h.service.ListProtectedResources(ctx)
+		resources, err := h.service.ListProtectedResources(ctx, limit, offset, ownerID)
 		if err != nil {
 			log.Error("failed to list protected resources", logger.Error(err))
-			RespondError(w, http.StatusInternalServerError, "Failed to list protected resources", nil)
+			respondServiceError(w, r, err, "Protected resources", "list")
 			return
 		}
 
-		// Respond with resources
-		RespondJSON(w, http.StatusOK, resources)
+		// Respond with a page of resources
+		Respond(w, r, http.StatusOK, models.NewPage(resources, limit, offset))
 	}
 }
 
 // OAuth2ProtectedResourceHandler handles GET /protected/oauth2
 // @Summary Get OAuth2 protected resources
-// @Description Returns a list of resources that require OAuth2 authentication
+// @Description Returns the resources that require OAuth2 authentication, limited to those the token's scopes grant
 // @Tags protected
 // @Accept json
-// @Produce json
+// @Produce json,xml
 // @Security BearerAuth
-// @Success 200 {array} models.ProtectedResource "Successfully retrieved protected resources"
+// @Param limit query int false "Limit, clamped to the server's max page size" default(10)
+// @Param offset query int false "Offset" default(0)
+// @Param ownerId query string false "Only return resources owned by this user"
+// @Success 200 {object} models.Page[models.ProtectedResource] "Successfully retrieved protected resources"
+// @Failure 400 {object} ErrorResponse "Invalid pagination parameter"
 // @Failure 401 {string} string "Unauthorized"
 // @Failure 403 {string} string "Forbidden: insufficient scope"
 // @Failure 500 {object} ErrorResponse "Internal server error"
@@ -413,16 +1103,25 @@ func (h *Handler) OAuth2ProtectedResourceHandler() http.HandlerFunc {
 		span := trace.SpanFromContext(ctx)
 		span.SetAttributes(attribute.String("handler", "oauth2ProtectedResource"))
 
+		// Parse query parameters
+		limit, offset, err := h.parsePagination(r)
+		if err != nil {
+			RespondError(w, r, http.StatusBadRequest, "Invalid pagination parameter", err)
+			return
+		}
+		ownerID := r.URL.Query().Get("ownerId")
+		w.Header().Set(PageLimitHeader, strconv.Itoa(limit))
+
 		// Get resources
-		resources, err := h.service.ListProtectedResources(ctx)
+		resources, err := h.service.ListProtectedResources(ctx, limit, offset, ownerID)
 		if err != nil {
 			log.Error("failed to list protected resources", logger.Error(err))
-			RespondError(w, http.StatusInternalServerError, "Failed to list protected resources", nil)
+			respondServiceError(w, r, err, "Protected resources", "list")
 			return
 		}
 
-		// Respond with resources
-		RespondJSON(w, http.StatusOK, resources)
+		// Respond with a page of resources
+		Respond(w, r, http.StatusOK, models.NewPage(resources, limit, offset))
 	}
 }
 
@@ -431,10 +1130,10 @@ func (h *Handler) OAuth2ProtectedResourceHandler() http.HandlerFunc {
 // @Description Returns the authenticated user's profile
 // @Tags user
 // @Accept json
-// @Produce json
+// @Produce json,xml
 // @Security BearerAuth
 // @Success 200 {object} models.UserProfile "Successfully retrieved user profile"
-// @Failure 401 {string} string "Unauthorized"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /me [get]
 func (h *Handler) UserProfileHandler() http.HandlerFunc {
@@ -448,23 +1147,65 @@ func (h *Handler) UserProfileHandler() http.HandlerFunc {
 		span := trace.SpanFromContext(ctx)
 		span.SetAttributes(attribute.String("handler", "userProfile"))
 
-		// Get user ID from context (set by auth middleware)
-		userID, ok := auth.GetUserID(ctx)
+		// Get claims from context (set by auth middleware). The OAuth2
+		// middleware only stores the user ID and scopes, so build claims from those
+		claims, ok := auth.GetClaims(ctx)
 		if !ok {
-			log.Error("user ID not found in context")
-			RespondError(w, http.StatusInternalServerError, "User ID not found", nil)
+			userID, ok := auth.GetUserID(ctx)
+			if !ok {
+				log.Warn("user ID not found in context")
+				RespondError(w, r, http.StatusUnauthorized, "Unauthorized", nil)
+				return
+			}
+			scopes, _ := auth.GetScopes(ctx)
+			claims = &auth.Claims{UserID: userID, Scopes: scopes}
+		}
+		if claims.UserID == "" {
+			log.Warn("user ID missing from token")
+			RespondError(w, r, http.StatusUnauthorized, "Unauthorized", nil)
 			return
 		}
 
 		// Get user profile
-		profile, err := h.service.GetUserProfile(ctx, userID)
+		profile, err := h.service.GetUserProfile(ctx, claims)
 		if err != nil {
-			log.Error("failed to get user profile", logger.String("userID", userID), logger.Error(err))
-			RespondError(w, http.StatusInternalServerError, "Failed to get user profile", nil)
+			log.Error("failed to get user profile", logger.String("userID", claims.UserID), logger.Error(err))
+			RespondError(w, r, http.StatusInternalServerError, "Failed to get user profile", nil)
 			return
 		}
 
 		// Respond with profile
-		RespondJSON(w, http.StatusOK, profile)
+		Respond(w, r, http.StatusOK, profile)
+	}
+}
+
+// SchemaHandler handles GET /schemas/{model}
+// @Summary Get model JSON Schema
+// @Description Returns the JSON Schema of a model, generated from its Go type and validate tags
+// @Tags schemas
+// @Produce json
+// @Param model path string true "Model name" Enums(Example, ExampleRequest, ProtectedResource, UserProfile)
+// @Success 200 {object} models.JSONSchema "Successfully generated schema"
+// @Failure 404 {object} ErrorResponse "Schema not found"
+// @Router /schemas/{model} [get]
+func (h *Handler) SchemaHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.FromContext(r.Context())
+
+		// Get span and add attributes
+		span := trace.SpanFromContext(r.Context())
+		span.SetAttributes(attribute.String("handler", "schema"))
+
+		name := chi.URLParam(r, "model")
+		span.SetAttributes(attribute.String("schema.model", name))
+
+		schema, ok := models.SchemaFor(name)
+		if !ok {
+			log.Debug("unknown schema model", logger.String("model", name))
+			RespondError(w, r, http.StatusNotFound, "Schema not found", nil)
+			return
+		}
+
+		RespondJSON(w, r, http.StatusOK, schema)
 	}
 }
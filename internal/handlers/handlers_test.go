@@ -4,21 +4,29 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/dBiTech/go-apiTemplate/internal/auth"
 	"github.com/dBiTech/go-apiTemplate/internal/handlers"
 	"github.com/dBiTech/go-apiTemplate/internal/models"
 	"github.com/dBiTech/go-apiTemplate/internal/repository"
 	"github.com/dBiTech/go-apiTemplate/internal/service"
 	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+	"github.com/dBiTech/go-apiTemplate/pkg/telemetry"
 )
 
 // MockService mocks the service layer for testing handlers
@@ -37,6 +45,27 @@ func (m *MockService) GetExample(ctx context.Context, id string) (*models.Exampl
 	return args.Get(0).(*models.Example), args.Error(1)
 }
 
+func (m *MockService) GetExamplesByIDs(ctx context.Context, ids []string) (map[string]*models.Example, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*models.Example), args.Error(1)
+}
+
+func (m *MockService) ArchiveExamples(ctx context.Context, filter models.ArchiveFilter) (int, error) {
+	args := m.Called(ctx, filter)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockService) SearchExamples(ctx context.Context, query string, limit int) ([]*models.Example, error) {
+	args := m.Called(ctx, query, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Example), args.Error(1)
+}
+
 func (m *MockService) ListExamples(ctx context.Context, limit, offset int) ([]*models.Example, error) {
 	args := m.Called(ctx, limit, offset)
 	if args.Get(0) == nil {
@@ -45,6 +74,19 @@ func (m *MockService) ListExamples(ctx context.Context, limit, offset int) ([]*m
 	return args.Get(0).([]*models.Example), args.Error(1)
 }
 
+func (m *MockService) CountExamples(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockService) ListExamplesAfter(ctx context.Context, cursor string, limit int) ([]*models.Example, string, error) {
+	args := m.Called(ctx, cursor, limit)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).([]*models.Example), args.String(1), args.Error(2)
+}
+
 func (m *MockService) CreateExample(ctx context.Context, req *models.ExampleRequest) (*models.Example, error) {
 	args := m.Called(ctx, req)
 	if args.Get(0) == nil {
@@ -61,21 +103,62 @@ func (m *MockService) UpdateExample(ctx context.Context, id string, req *models.
 	return args.Get(0).(*models.Example), args.Error(1)
 }
 
+func (m *MockService) UpdateExampleIfUnmodified(ctx context.Context, id string, req *models.ExampleRequest, version time.Time) (*models.Example, error) {
+	args := m.Called(ctx, id, req, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Example), args.Error(1)
+}
+
+func (m *MockService) UpsertExample(ctx context.Context, id string, req *models.ExampleRequest) (*models.Example, bool, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, false, args.Error(2)
+	}
+	return args.Get(0).(*models.Example), args.Bool(1), args.Error(2)
+}
+
 func (m *MockService) DeleteExample(ctx context.Context, id string) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *MockService) ListProtectedResources(ctx context.Context) ([]*models.ProtectedResource, error) {
-	args := m.Called(ctx)
+func (m *MockService) BulkCreateExamples(ctx context.Context, reqs []*models.ExampleRequest, atomic bool) ([]service.BulkResult, error) {
+	args := m.Called(ctx, reqs, atomic)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]service.BulkResult), args.Error(1)
+}
+
+func (m *MockService) ListProtectedResources(ctx context.Context, limit, offset int, ownerID string) ([]*models.ProtectedResource, error) {
+	args := m.Called(ctx, limit, offset, ownerID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*models.ProtectedResource), args.Error(1)
 }
 
-func (m *MockService) GetUserProfile(ctx context.Context, userID string) (*models.UserProfile, error) {
-	args := m.Called(ctx, userID)
+func (m *MockService) ExportExamples(ctx context.Context, fn func(*models.Example) error) error {
+	args := m.Called(ctx, mock.Anything)
+	if examples, ok := args.Get(0).([]*models.Example); ok {
+		for _, example := range examples {
+			if err := fn(example); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockService) ResetExamples(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockService) GetUserProfile(ctx context.Context, claims *auth.Claims) (*models.UserProfile, error) {
+	args := m.Called(ctx, claims)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -146,16 +229,41 @@ func TestHandlers(t *testing.T) {
 
 		// Set up mock expectations
 		mockService.On("ListExamples", mock.Anything, 10, 0).Return(examples, nil)
+		mockService.On("CountExamples", mock.Anything).Return(12, nil)
 
 		handler.ListExamplesHandler().ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusOK, w.Code)
-		var resp []*models.Example
+		var resp models.Page[*models.Example]
 		err := json.Unmarshal(w.Body.Bytes(), &resp)
 		require.NoError(t, err)
-		assert.Len(t, resp, 2)
-		assert.Equal(t, examples[0].ID, resp[0].ID)
-		assert.Equal(t, examples[1].Name, resp[1].Name)
+		assert.Len(t, resp.Data, 2)
+		assert.Equal(t, examples[0].ID, resp.Data[0].ID)
+		assert.Equal(t, examples[1].Name, resp.Data[1].Name)
+		assert.Equal(t, 10, resp.Limit)
+		assert.Equal(t, 0, resp.Offset)
+		assert.Equal(t, 12, resp.Total)
+	})
+
+	// Test protected resource query parameters are passed to the service
+	t.Run("ProtectedResourcesQuery", func(t *testing.T) {
+		resources := []*models.ProtectedResource{
+			{ID: "protected-resource-2", Name: "Protected Resource 2", OwnerID: "user456"},
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/protected/jwt?limit=1&offset=0&ownerId=user456", nil)
+		w := httptest.NewRecorder()
+
+		mockService.On("ListProtectedResources", mock.Anything, 1, 0, "user456").Return(resources, nil)
+
+		handler.JWTProtectedResourceHandler().ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp models.Page[*models.ProtectedResource]
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Data, 1)
+		assert.Equal(t, "user456", resp.Data[0].OwnerID)
+		assert.Equal(t, 1, resp.Limit)
 	})
 
 	// Test CreateExampleHandler
@@ -279,10 +387,1164 @@ func TestHandlers(t *testing.T) {
 		w := httptest.NewRecorder()
 
 		// Set up mock expectations
-		mockService.On("GetExample", mock.Anything, id).Return(nil, repository.ErrNotFound)
+		mockService.On("GetExample", mock.Anything, id).Return(nil, service.ErrNotFound)
 
 		handler.GetExampleHandler().ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusNotFound, w.Code)
 	})
 }
+
+func TestETag(t *testing.T) {
+	log := logger.Default()
+
+	// withID returns the request with the chi id URL param set
+	withID := func(req *http.Request, id string) *http.Request {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", id)
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	id := uuid.New().String()
+	example := &models.Example{
+		BaseModel:   models.BaseModel{ID: id, UpdatedAt: time.Now()},
+		Name:        "Test Example",
+		Description: "Test Description",
+	}
+
+	// Test GetExampleHandler sets an ETag
+	t.Run("GetSetsETag", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+		mockService.On("GetExample", mock.Anything, id).Return(example, nil)
+
+		w := httptest.NewRecorder()
+		handler.GetExampleHandler().ServeHTTP(w, withID(httptest.NewRequest(http.MethodGet, "/api/v1/examples/"+id, nil), id))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		etag := w.Header().Get("ETag")
+		assert.NotEmpty(t, etag)
+		assert.True(t, strings.HasPrefix(etag, `"`) && strings.HasSuffix(etag, `"`))
+	})
+
+	// Test GetExampleHandler returns 304 when If-None-Match matches
+	t.Run("GetNotModified", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+		mockService.On("GetExample", mock.Anything, id).Return(example, nil)
+
+		w := httptest.NewRecorder()
+		handler.GetExampleHandler().ServeHTTP(w, withID(httptest.NewRequest(http.MethodGet, "/api/v1/examples/"+id, nil), id))
+		etag := w.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/examples/"+id, nil)
+		req.Header.Set("If-None-Match", etag)
+		w = httptest.NewRecorder()
+		handler.GetExampleHandler().ServeHTTP(w, withID(req, id))
+
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+		assert.Empty(t, w.Body.Bytes())
+	})
+
+	// Test the ETag changes when the example is modified
+	t.Run("GetModified", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+		mockService.On("GetExample", mock.Anything, id).Return(example, nil).Once()
+
+		w := httptest.NewRecorder()
+		handler.GetExampleHandler().ServeHTTP(w, withID(httptest.NewRequest(http.MethodGet, "/api/v1/examples/"+id, nil), id))
+		etag := w.Header().Get("ETag")
+
+		modified := *example
+		modified.Name = "Modified Example"
+		modified.UpdatedAt = example.UpdatedAt.Add(time.Second)
+		mockService.On("GetExample", mock.Anything, id).Return(&modified, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/examples/"+id, nil)
+		req.Header.Set("If-None-Match", etag)
+		w = httptest.NewRecorder()
+		handler.GetExampleHandler().ServeHTTP(w, withID(req, id))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	})
+
+	// Test UpdateExampleHandler returns 412 when If-Match doesn't match
+	t.Run("UpdatePreconditionFailed", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+		mockService.On("GetExample", mock.Anything, id).Return(example, nil)
+
+		body, err := json.Marshal(models.ExampleRequest{Name: "Updated Example"})
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/examples/"+id, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"stale"`)
+		w := httptest.NewRecorder()
+		handler.UpdateExampleHandler().ServeHTTP(w, withID(req, id))
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		mockService.AssertNotCalled(t, "UpdateExampleIfUnmodified", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	// Test If-Match uses strong comparison, so a weak ETag never matches
+	t.Run("UpdateWeakETag", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+		mockService.On("GetExample", mock.Anything, id).Return(example, nil)
+
+		w := httptest.NewRecorder()
+		handler.GetExampleHandler().ServeHTTP(w, withID(httptest.NewRequest(http.MethodGet, "/api/v1/examples/"+id, nil), id))
+		etag := w.Header().Get("ETag")
+
+		body, err := json.Marshal(models.ExampleRequest{Name: "Updated Example"})
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/examples/"+id, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", "W/"+etag)
+		w = httptest.NewRecorder()
+		handler.UpdateExampleHandler().ServeHTTP(w, withID(req, id))
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		mockService.AssertNotCalled(t, "UpdateExampleIfUnmodified", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	// Test a 412 when the example changes between the check and the write
+	t.Run("UpdateRaced", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+		mockService.On("GetExample", mock.Anything, id).Return(example, nil)
+		mockService.On("UpdateExampleIfUnmodified", mock.Anything, id, mock.Anything, example.UpdatedAt).
+			Return(nil, &service.Error{Kind: service.ErrPreconditionFailed, Err: repository.ErrModified})
+
+		body, err := json.Marshal(models.ExampleRequest{Name: "Updated Example"})
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/examples/"+id, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", "*")
+		w := httptest.NewRecorder()
+		handler.UpdateExampleHandler().ServeHTTP(w, withID(req, id))
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	// Test JSON and XML representations have different ETags
+	t.Run("ETagPerContentType", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+		mockService.On("GetExample", mock.Anything, id).Return(example, nil)
+
+		w := httptest.NewRecorder()
+		handler.GetExampleHandler().ServeHTTP(w, withID(httptest.NewRequest(http.MethodGet, "/api/v1/examples/"+id, nil), id))
+		jsonETag := w.Header().Get("ETag")
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/examples/"+id, nil)
+		req.Header.Set("Accept", "application/xml")
+		w = httptest.NewRecorder()
+		handler.GetExampleHandler().ServeHTTP(w, withID(req, id))
+
+		assert.NotEqual(t, jsonETag, w.Header().Get("ETag"))
+
+		// If-None-Match for the JSON representation doesn't match the XML one
+		req = httptest.NewRequest(http.MethodGet, "/api/v1/examples/"+id, nil)
+		req.Header.Set("Accept", "application/xml")
+		req.Header.Set("If-None-Match", jsonETag)
+		w = httptest.NewRecorder()
+		handler.GetExampleHandler().ServeHTTP(w, withID(req, id))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	// Test UpdateExampleHandler applies the update when If-Match matches
+	t.Run("UpdateMatches", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+		mockService.On("GetExample", mock.Anything, id).Return(example, nil)
+
+		w := httptest.NewRecorder()
+		handler.GetExampleHandler().ServeHTTP(w, withID(httptest.NewRequest(http.MethodGet, "/api/v1/examples/"+id, nil), id))
+		etag := w.Header().Get("ETag")
+
+		updated := *example
+		updated.Name = "Updated Example"
+		mockService.On("UpdateExampleIfUnmodified", mock.Anything, id, mock.Anything, example.UpdatedAt).Return(&updated, nil)
+
+		body, err := json.Marshal(models.ExampleRequest{Name: "Updated Example"})
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/examples/"+id, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", etag)
+		w = httptest.NewRecorder()
+		handler.UpdateExampleHandler().ServeHTTP(w, withID(req, id))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+		assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	})
+}
+
+func TestBulkCreateExamplesHandler(t *testing.T) {
+	log := logger.Default()
+
+	reqBody := []models.ExampleRequest{
+		{Name: "First Example"},
+		{Name: ""},
+		{Name: "Third Example"},
+	}
+	body, err := json.Marshal(reqBody)
+	require.NoError(t, err)
+
+	// Test per-item results are mapped to statuses in a 207 response
+	t.Run("MultiStatus", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+
+		first := models.NewExample(uuid.New().String(), "First Example", "")
+		mockService.On("BulkCreateExamples", mock.Anything, mock.Anything, false).Return([]service.BulkResult{
+			{Example: first},
+			{Err: service.ErrInvalidRequest},
+			{Err: service.ErrConflict},
+		}, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/examples/bulk", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		handler.BulkCreateExamplesHandler().ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+
+		var resp []models.BulkCreateItemResult
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp, 3)
+		assert.Equal(t, http.StatusCreated, resp[0].Status)
+		assert.Equal(t, first.ID, resp[0].Example.ID)
+		assert.Equal(t, http.StatusBadRequest, resp[1].Status)
+		assert.NotEmpty(t, resp[1].Error)
+		assert.Equal(t, http.StatusConflict, resp[2].Status)
+		assert.Equal(t, 2, resp[2].Index)
+	})
+
+	// Test the atomic flag is passed through and rollbacks are reported
+	t.Run("Atomic", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+
+		mockService.On("BulkCreateExamples", mock.Anything, mock.Anything, true).Return([]service.BulkResult{
+			{Err: service.ErrRolledBack},
+			{Err: service.ErrInvalidRequest},
+			{Err: service.ErrRolledBack},
+		}, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/examples/bulk?atomic=true", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		handler.BulkCreateExamplesHandler().ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+
+		var resp []models.BulkCreateItemResult
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp, 3)
+		assert.Equal(t, http.StatusFailedDependency, resp[0].Status)
+		assert.Nil(t, resp[0].Example)
+		assert.Equal(t, http.StatusBadRequest, resp[1].Status)
+		assert.Equal(t, http.StatusFailedDependency, resp[2].Status)
+	})
+
+	// Test invalid requests are rejected before reaching the service
+	t.Run("BadRequest", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+
+		for _, tc := range []struct {
+			url  string
+			body string
+		}{
+			{url: "/api/v1/examples/bulk", body: "[]"},
+			{url: "/api/v1/examples/bulk", body: "{}"},
+			{url: "/api/v1/examples/bulk?atomic=maybe", body: string(body)},
+			{url: "/api/v1/examples/bulk", body: "[" + strings.Repeat(`{"name":"a"},`, 100) + `{"name":"a"}]`},
+		} {
+			req := httptest.NewRequest(http.MethodPost, tc.url, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			handler.BulkCreateExamplesHandler().ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, tc.url+" "+tc.body)
+		}
+
+		mockService.AssertNotCalled(t, "BulkCreateExamples", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestBatchGetExamplesHandler(t *testing.T) {
+	log := logger.Default()
+
+	first := models.NewExample(uuid.New().String(), "First Example", "")
+	second := models.NewExample(uuid.New().String(), "Second Example", "")
+	missing := uuid.New().String()
+
+	// batchGet posts body to the handler and decodes the response
+	batchGet := func(t *testing.T, handler *handlers.Handler, body string) (int, models.BatchGetResponse) {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/examples/batch-get", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.BatchGetExamplesHandler().ServeHTTP(w, req)
+
+		var resp models.BatchGetResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w.Code, resp
+	}
+
+	// Test found examples are returned in request order
+	t.Run("AllFound", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+
+		ids := []string{second.ID, first.ID}
+		mockService.On("GetExamplesByIDs", mock.Anything, ids).Return(map[string]*models.Example{
+			first.ID:  first,
+			second.ID: second,
+		}, nil)
+
+		status, resp := batchGet(t, handler, `{"ids":["`+second.ID+`","`+first.ID+`","`+second.ID+`"]}`)
+		assert.Equal(t, http.StatusOK, status)
+		require.Len(t, resp.Examples, 2)
+		assert.Equal(t, second.ID, resp.Examples[0].ID)
+		assert.Equal(t, first.ID, resp.Examples[1].ID)
+		assert.Empty(t, resp.Missing)
+	})
+
+	// Test unknown IDs are listed as missing
+	t.Run("SomeMissing", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+
+		ids := []string{first.ID, missing}
+		mockService.On("GetExamplesByIDs", mock.Anything, ids).Return(map[string]*models.Example{
+			first.ID: first,
+		}, nil)
+
+		status, resp := batchGet(t, handler, `{"ids":["`+first.ID+`","`+missing+`"]}`)
+		assert.Equal(t, http.StatusOK, status)
+		require.Len(t, resp.Examples, 1)
+		assert.Equal(t, first.ID, resp.Examples[0].ID)
+		assert.Equal(t, []string{missing}, resp.Missing)
+	})
+
+	// Test no IDs get an empty result
+	t.Run("EmptyInput", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+
+		mockService.On("GetExamplesByIDs", mock.Anything, []string{}).Return(map[string]*models.Example{}, nil)
+
+		status, resp := batchGet(t, handler, `{"ids":[]}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Empty(t, resp.Examples)
+		assert.Empty(t, resp.Missing)
+	})
+
+	// Test more IDs than the max page size are rejected before reaching the service
+	t.Run("TooMany", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService, handlers.WithMaxPageSize(1))
+
+		status, _ := batchGet(t, handler, `{"ids":["`+first.ID+`","`+second.ID+`"]}`)
+		assert.Equal(t, http.StatusBadRequest, status)
+		mockService.AssertNotCalled(t, "GetExamplesByIDs", mock.Anything, mock.Anything)
+	})
+}
+
+func TestContentNegotiation(t *testing.T) {
+	log := logger.Default()
+
+	id := uuid.New().String()
+	example := models.NewExample(id, "Test Example", "Test Description")
+
+	mockService := new(MockService)
+	mockService.On("GetExample", mock.Anything, id).Return(example, nil)
+	handler := handlers.NewHandler(log, mockService)
+
+	// get requests the example with the given Accept header
+	get := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/examples/"+id, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", id)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		w := httptest.NewRecorder()
+		handler.GetExampleHandler().ServeHTTP(w, req)
+		return w
+	}
+
+	// assertJSON asserts the response is the example encoded as JSON
+	assertJSON := func(t *testing.T, w *httptest.ResponseRecorder) {
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var resp models.Example
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, id, resp.ID)
+		assert.Equal(t, example.Name, resp.Name)
+	}
+
+	// assertXML asserts the response is the example encoded as XML
+	assertXML := func(t *testing.T, w *httptest.ResponseRecorder) {
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/xml", w.Header().Get("Content-Type"))
+		assert.True(t, strings.HasPrefix(w.Body.String(), "<?xml"))
+
+		var resp models.Example
+		require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, id, resp.ID)
+		assert.Equal(t, example.Name, resp.Name)
+		assert.Equal(t, example.Description, resp.Description)
+		assert.True(t, example.CreatedAt.Equal(resp.CreatedAt))
+	}
+
+	// Test JSON is the default
+	t.Run("Default", func(t *testing.T) {
+		assertJSON(t, get(""))
+	})
+
+	// Test JSON is returned for a wildcard
+	t.Run("Wildcard", func(t *testing.T) {
+		assertJSON(t, get("*/*"))
+	})
+
+	// Test JSON is returned when requested
+	t.Run("JSON", func(t *testing.T) {
+		assertJSON(t, get("application/json"))
+	})
+
+	// Test XML is returned when requested
+	t.Run("XML", func(t *testing.T) {
+		assertXML(t, get("application/xml"))
+	})
+
+	// Test quality values are honored
+	t.Run("QualityValues", func(t *testing.T) {
+		assertXML(t, get("application/json;q=0.5, application/xml"))
+		assertJSON(t, get("application/xml;q=0.5, application/json;q=0.9"))
+	})
+
+	// Test an explicit type wins over a wildcard of the same quality
+	t.Run("Specificity", func(t *testing.T) {
+		assertXML(t, get("application/xml, */*"))
+	})
+
+	// Test unsupported types are rejected
+	t.Run("NotAcceptable", func(t *testing.T) {
+		for _, accept := range []string{"text/html", "application/json;q=0, text/plain"} {
+			w := get(accept)
+			assert.Equal(t, http.StatusNotAcceptable, w.Code, accept)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		}
+	})
+}
+
+func TestListExamplesCursor(t *testing.T) {
+	log := logger.Default()
+
+	// Test a cursor request returns a page envelope
+	t.Run("Page", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+
+		examples := []*models.Example{models.NewExample(uuid.New().String(), "Test Example", "")}
+		mockService.On("ListExamplesAfter", mock.Anything, "abc", 1).Return(examples, "def", nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/examples?cursor=abc&limit=1", nil)
+		w := httptest.NewRecorder()
+		handler.ListExamplesHandler().ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var resp map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.JSONEq(t, `"def"`, string(resp["next_cursor"]))
+
+		var items []models.Example
+		require.NoError(t, json.Unmarshal(resp["data"], &items))
+		require.Len(t, items, 1)
+		assert.Equal(t, examples[0].ID, items[0].ID)
+	})
+
+	// Test an empty cursor starts from the first page
+	t.Run("FirstPage", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+
+		mockService.On("ListExamplesAfter", mock.Anything, "", 10).Return([]*models.Example{}, "", nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/examples?cursor=", nil)
+		w := httptest.NewRecorder()
+		handler.ListExamplesHandler().ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"data":[],"limit":10,"offset":0}`, w.Body.String())
+	})
+
+	// Test an invalid cursor is a bad request
+	t.Run("InvalidCursor", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+
+		mockService.On("ListExamplesAfter", mock.Anything, "bogus", 10).Return(nil, "", service.ErrValidation)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/examples?cursor=bogus", nil)
+		w := httptest.NewRecorder()
+		handler.ListExamplesHandler().ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestArchiveExamplesHandler(t *testing.T) {
+	log := logger.Default()
+
+	// Test the filter is passed to the service and the count returned
+	t.Run("Archived", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+
+		cutoff := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		filter := models.ArchiveFilter{Status: models.StatusInactive, OlderThan: cutoff}
+		mockService.On("ArchiveExamples", mock.Anything, filter).Return(3, nil)
+
+		body := `{"status":"inactive","olderThan":"2024-01-01T00:00:00Z"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/examples/archive", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.ArchiveExamplesHandler().ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"archived":3}`, w.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	// Test an invalid filter is a bad request
+	t.Run("InvalidFilter", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+
+		mockService.On("ArchiveExamples", mock.Anything, models.ArchiveFilter{}).Return(0, service.ErrValidation)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/examples/archive", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.ArchiveExamplesHandler().ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestSearchExamplesHandler(t *testing.T) {
+	log := logger.Default()
+
+	// Test matches are returned as a page in the service's order
+	t.Run("Matches", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+
+		examples := []*models.Example{
+			models.NewExample("a", "Widget", ""),
+			models.NewExample("b", "Gadget", "A widget holder"),
+		}
+		mockService.On("SearchExamples", mock.Anything, "widget", 5).Return(examples, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/examples/search?q=+widget+&limit=5", nil)
+		w := httptest.NewRecorder()
+		handler.SearchExamplesHandler().ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "5", w.Header().Get(handlers.PageLimitHeader))
+
+		var page models.Page[models.Example]
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		require.Len(t, page.Data, 2)
+		assert.Equal(t, "a", page.Data[0].ID)
+		assert.Equal(t, "b", page.Data[1].ID)
+		mockService.AssertExpectations(t)
+	})
+
+	// Test no matches is an empty page
+	t.Run("NoMatches", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+
+		mockService.On("SearchExamples", mock.Anything, "sprocket", 10).Return([]*models.Example{}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/examples/search?q=sprocket", nil)
+		w := httptest.NewRecorder()
+		handler.SearchExamplesHandler().ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"data":[],"limit":10,"offset":0}`, w.Body.String())
+	})
+
+	// Test a missing search term is a bad request
+	t.Run("MissingTerm", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/examples/search?q=+", nil)
+		w := httptest.NewRecorder()
+		handler.SearchExamplesHandler().ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "SearchExamples", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestMaxPageSize(t *testing.T) {
+	log := logger.Default()
+
+	// Test an oversized limit is clamped to the default maximum
+	t.Run("ClampedOffset", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+
+		mockService.On("ListExamples", mock.Anything, 100, 0).Return([]*models.Example{}, nil)
+		mockService.On("CountExamples", mock.Anything).Return(0, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/examples?limit=1000000", nil)
+		w := httptest.NewRecorder()
+		handler.ListExamplesHandler().ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "100", w.Header().Get(handlers.PageLimitHeader))
+		mockService.AssertExpectations(t)
+	})
+
+	// Test the cursor page reports the clamped limit
+	t.Run("ClampedCursor", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+
+		mockService.On("ListExamplesAfter", mock.Anything, "", 100).Return([]*models.Example{}, "", nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/examples?cursor=&limit=1000000", nil)
+		w := httptest.NewRecorder()
+		handler.ListExamplesHandler().ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"data":[],"limit":100,"offset":0}`, w.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	// Test a configured maximum replaces the default
+	t.Run("Configured", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService, handlers.WithMaxPageSize(25))
+
+		mockService.On("ListExamples", mock.Anything, 25, 0).Return([]*models.Example{}, nil)
+		mockService.On("CountExamples", mock.Anything).Return(0, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/examples?limit=50", nil)
+		w := httptest.NewRecorder()
+		handler.ListExamplesHandler().ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "25", w.Header().Get(handlers.PageLimitHeader))
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestCreateOnPut(t *testing.T) {
+	log := logger.Default()
+
+	// put sends a PUT for the example with the given ID to the handler
+	put := func(handler *handlers.Handler, id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/examples/"+id, strings.NewReader(`{"name":"Put Example"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", id)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		w := httptest.NewRecorder()
+		handler.UpdateExampleHandler().ServeHTTP(w, req)
+		return w
+	}
+
+	// Test an unknown ID is still a 404 by default
+	t.Run("Disabled", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+
+		mockService.On("UpdateExample", mock.Anything, "missing", mock.Anything).Return(nil, service.ErrNotFound)
+
+		w := put(handler, "missing")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertNotCalled(t, "UpsertExample", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	// Test an unknown ID is created with a 201 when enabled
+	t.Run("Creates", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService, handlers.WithCreateOnPut(true))
+
+		example := &models.Example{BaseModel: models.BaseModel{ID: "new"}, Name: "Put Example"}
+		mockService.On("UpsertExample", mock.Anything, "new", mock.Anything).Return(example, true, nil)
+
+		w := put(handler, "new")
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"id":"new"`)
+	})
+
+	// Test an existing ID is updated with a 200 when enabled
+	t.Run("Updates", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService, handlers.WithCreateOnPut(true))
+
+		example := &models.Example{BaseModel: models.BaseModel{ID: "existing"}, Name: "Put Example"}
+		mockService.On("UpsertExample", mock.Anything, "existing", mock.Anything).Return(example, false, nil)
+
+		w := put(handler, "existing")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	// Test a name taken by another example conflicts
+	t.Run("Conflict", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService, handlers.WithCreateOnPut(true))
+
+		mockService.On("UpsertExample", mock.Anything, "new", mock.Anything).Return(nil, false, service.ErrConflict)
+
+		w := put(handler, "new")
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestStatusForError(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"NotFound", service.ErrNotFound, http.StatusNotFound},
+		{"Conflict", service.ErrConflict, http.StatusConflict},
+		{"Validation", service.ErrValidation, http.StatusBadRequest},
+		{"PreconditionFailed", service.ErrPreconditionFailed, http.StatusPreconditionFailed},
+		{"Internal", service.ErrInternal, http.StatusInternalServerError},
+		{"Wrapped", &service.Error{Kind: service.ErrNotFound, Err: errors.New("resource not found")}, http.StatusNotFound},
+		{"DeadlineExceeded", fmt.Errorf("delete: %w", context.DeadlineExceeded), http.StatusGatewayTimeout},
+		{"Other", errors.New("boom"), http.StatusInternalServerError},
+	} {
+		// Test the service error is reported with its status
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockService)
+			handler := handlers.NewHandler(logger.Default(), mockService)
+			mockService.On("DeleteExample", mock.Anything, "abc").Return(tc.err)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/examples/abc", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "abc")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+			handler.DeleteExampleHandler().ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code)
+		})
+	}
+}
+
+// failingRepository is a memory repository whose writes fail with err
+type failingRepository struct {
+	*repository.MemoryRepository
+	err error
+}
+
+func (r *failingRepository) CreateExample(context.Context, *models.Example) error {
+	return r.err
+}
+
+func (r *failingRepository) UpdateExample(context.Context, *models.Example) error {
+	return r.err
+}
+
+func TestRepositoryErrorStatus(t *testing.T) {
+	log := logger.Default()
+	tel, err := telemetry.New(context.Background(), telemetry.Config{Enabled: false}, log)
+	require.NoError(t, err)
+
+	// newHandler returns a handler backed by a real service whose repository
+	// writes fail with repoErr
+	newHandler := func(t *testing.T, repoErr error) *handlers.Handler {
+		t.Helper()
+
+		repo := &failingRepository{MemoryRepository: repository.NewMemoryRepository(log), err: repoErr}
+		require.NoError(t, repo.MemoryRepository.CreateExample(context.Background(), models.NewExample("existing", "Existing", "")))
+		return handlers.NewHandler(log, service.New(repo, log, tel))
+	}
+
+	for _, tc := range []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"NotFound", repository.ErrNotFound, http.StatusNotFound},
+		{"AlreadyExists", repository.ErrAlreadyExists, http.StatusConflict},
+		{"InvalidData", repository.ErrInvalidData, http.StatusBadRequest},
+		{"InvalidCursor", repository.ErrInvalidCursor, http.StatusBadRequest},
+		{"Internal", repository.ErrInternal, http.StatusInternalServerError},
+		{"WrappedInternal", fmt.Errorf("query failed: %w", repository.ErrInternal), http.StatusInternalServerError},
+	} {
+		// Test the create handler reports the repository error with its status
+		t.Run("Create"+tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/examples", strings.NewReader(`{"name":"New Example"}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			newHandler(t, tc.err).CreateExampleHandler().ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code)
+		})
+
+		// Test the update handler reports the repository error with its status
+		t.Run("Update"+tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/examples/existing", strings.NewReader(`{"name":"Updated Example"}`))
+			req.Header.Set("Content-Type", "application/json")
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "existing")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+			newHandler(t, tc.err).UpdateExampleHandler().ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code)
+		})
+	}
+}
+
+func TestPaginationValidation(t *testing.T) {
+	mockService := new(MockService)
+	handler := handlers.NewHandler(logger.Default(), mockService)
+
+	for _, tc := range []struct {
+		name, query, detail string
+	}{
+		{"NegativeLimit", "limit=-1", "limit must be at least 1"},
+		{"ZeroLimit", "limit=0", "limit must be at least 1"},
+		{"NegativeOffset", "offset=-5", "offset must be at least 0"},
+		{"NonNumericOffset", "offset=ten", `offset must be an integer, got \"ten\"`},
+		{"OverflowingOffset", "offset=99999999999999999999", "offset is out of range"},
+		{"OverflowingLimit", "limit=99999999999999999999", "limit is out of range"},
+	} {
+		// Test the invalid value is rejected with a 400 naming the parameter
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/examples?"+tc.query, nil)
+			w := httptest.NewRecorder()
+			handler.ListExamplesHandler().ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), tc.detail)
+		})
+	}
+
+	// Test the protected resource lists validate too
+	t.Run("ProtectedResources", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/protected/jwt?limit=abc", nil)
+		w := httptest.NewRecorder()
+		handler.JWTProtectedResourceHandler().ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	// No invalid request reaches the service
+	mockService.AssertNotCalled(t, "ListExamples", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSchemaHandler(t *testing.T) {
+	handler := handlers.NewHandler(logger.Default(), new(MockService))
+
+	router := chi.NewRouter()
+	router.Get("/api/v1/schemas/{model}", handler.SchemaHandler())
+
+	// Test a known model is served with its constraints
+	t.Run("Found", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/schemas/ExampleRequest", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var schema models.JSONSchema
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &schema))
+		assert.Equal(t, []string{"name"}, schema.Required)
+		require.NotNil(t, schema.Properties["name"].MinLength)
+		assert.Equal(t, 3, *schema.Properties["name"].MinLength)
+	})
+
+	// Test an unknown model is not found
+	t.Run("NotFound", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/schemas/Nope", nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestUserProfileHandler(t *testing.T) {
+	log := logger.Default()
+
+	// Test the user ID stored by the OAuth2 middleware is resolved
+	t.Run("UserIDFromContext", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+
+		claimsForUser := mock.MatchedBy(func(claims *auth.Claims) bool {
+			return claims.UserID == "user-42" && assert.ObjectsAreEqual([]string{"read"}, claims.Scopes)
+		})
+		mockService.On("GetUserProfile", mock.Anything, claimsForUser).
+			Return(&models.UserProfile{ID: "user-42", Username: "user42"}, nil)
+
+		ctx := context.WithValue(context.Background(), auth.UserIDContextKey, "user-42")
+		ctx = context.WithValue(ctx, auth.ScopesContextKey, []string{"read"})
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/me/oauth2", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		handler.UserProfileHandler().ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var profile models.UserProfile
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &profile))
+		assert.Equal(t, "user-42", profile.ID)
+		mockService.AssertExpectations(t)
+	})
+
+	// Test a request without an authenticated user is unauthorized
+	t.Run("Unauthenticated", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/me", nil)
+		w := httptest.NewRecorder()
+		handler.UserProfileHandler().ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		mockService.AssertNotCalled(t, "GetUserProfile", mock.Anything, mock.Anything)
+	})
+
+	// Test a token without a user ID is unauthorized
+	t.Run("MissingUserID", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := handlers.NewHandler(log, mockService)
+
+		ctx := context.WithValue(context.Background(), auth.ClaimsContextKey, &auth.Claims{Scopes: []string{"read"}})
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/me", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		handler.UserProfileHandler().ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		mockService.AssertNotCalled(t, "GetUserProfile", mock.Anything, mock.Anything)
+	})
+}
+
+func TestDecodeJSON(t *testing.T) {
+	log := logger.Default()
+
+	// create posts body to the create handler and returns the response
+	create := func(t *testing.T, mockService *MockService, body string) (int, handlers.ErrorResponse) {
+		t.Helper()
+
+		handler := handlers.NewHandler(log, mockService)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/examples", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.CreateExampleHandler().ServeHTTP(w, req)
+
+		var resp handlers.ErrorResponse
+		if w.Code != http.StatusCreated {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w.Code, resp
+	}
+
+	// Test a misspelled field is rejected and named
+	t.Run("UnknownField", func(t *testing.T) {
+		mockService := new(MockService)
+		code, resp := create(t, mockService, `{"name":"Example","descripton":"typo"}`)
+
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `unknown field "descripton"`, resp.Error)
+		mockService.AssertNotCalled(t, "CreateExample", mock.Anything, mock.Anything)
+	})
+
+	// Test data after the JSON value is rejected
+	t.Run("TrailingGarbage", func(t *testing.T) {
+		mockService := new(MockService)
+		code, resp := create(t, mockService, `{"name":"Example"} {"name":"Again"}`)
+
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, "request body must contain a single JSON value", resp.Error)
+		mockService.AssertNotCalled(t, "CreateExample", mock.Anything, mock.Anything)
+	})
+
+	// Test a field of the wrong type is named
+	t.Run("WrongType", func(t *testing.T) {
+		code, resp := create(t, new(MockService), `{"name":42}`)
+
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `field "name" must be a string`, resp.Error)
+	})
+
+	// Test malformed JSON reports where it broke
+	t.Run("Malformed", func(t *testing.T) {
+		code, resp := create(t, new(MockService), `{"name":}`)
+
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Contains(t, resp.Error, "malformed JSON at offset")
+	})
+
+	// Test a valid body, including trailing whitespace, is accepted
+	t.Run("Valid", func(t *testing.T) {
+		mockService := new(MockService)
+		mockService.On("CreateExample", mock.Anything, mock.Anything).
+			Return(models.NewExample(uuid.New().String(), "Example", ""), nil)
+
+		code, _ := create(t, mockService, "{\"name\":\"Example\"}\n")
+
+		assert.Equal(t, http.StatusCreated, code)
+		mockService.AssertExpectations(t)
+	})
+
+	// Test the update handler rejects unknown fields too
+	t.Run("Update", func(t *testing.T) {
+		handler := handlers.NewHandler(log, new(MockService))
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/examples/123", strings.NewReader(`{"name":"Example","extra":1}`))
+		w := httptest.NewRecorder()
+		handler.UpdateExampleHandler().ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `unknown field \"extra\"`)
+	})
+}
+
+func TestRespondErrorIDs(t *testing.T) {
+	// Test the request and trace IDs are included in the error body
+	t.Run("WithTrace", func(t *testing.T) {
+		traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+		require.NoError(t, err)
+		spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/examples/abc", nil)
+		req.Header.Set("X-Request-ID", "test-request-id")
+		req = req.WithContext(trace.ContextWithSpanContext(req.Context(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  spanID,
+		})))
+		w := httptest.NewRecorder()
+
+		handlers.RespondError(w, req, http.StatusNotFound, "Example not found", nil)
+
+		var resp handlers.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "test-request-id", resp.RequestID)
+		assert.Equal(t, traceID.String(), resp.TraceID)
+	})
+
+	// Test the IDs are omitted when absent
+	t.Run("WithoutIDs", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/examples/abc", nil)
+		w := httptest.NewRecorder()
+
+		handlers.RespondError(w, req, http.StatusNotFound, "Example not found", nil)
+
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.NotContains(t, resp, "requestId")
+		assert.NotContains(t, resp, "traceId")
+	})
+}
+
+func TestRespondValidationError(t *testing.T) {
+	// Test every field error is listed in the response
+	t.Run("Fields", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/examples", nil)
+		req.Header.Set("X-Request-ID", "test-request-id")
+		w := httptest.NewRecorder()
+
+		handlers.RespondValidationError(w, req, []handlers.FieldError{
+			{Field: "name", Message: "must be at least 3 characters"},
+			{Field: "description", Message: "must be at most 500 characters"},
+		})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{
+			"status": 400,
+			"message": "Validation failed",
+			"requestId": "test-request-id",
+			"fields": [
+				{"field": "name", "message": "must be at least 3 characters"},
+				{"field": "description", "message": "must be at most 500 characters"}
+			]
+		}`, w.Body.String())
+	})
+
+	// Test an error without field errors serializes as before
+	t.Run("NoFields", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/examples", nil)
+		w := httptest.NewRecorder()
+
+		handlers.RespondError(w, req, http.StatusBadRequest, "Name is required", nil)
+
+		assert.JSONEq(t, `{"status": 400, "message": "Name is required"}`, w.Body.String())
+	})
+}
+
+// countingWriter counts the calls to WriteHeader
+type countingWriter struct {
+	*httptest.ResponseRecorder
+	writeHeaders int
+}
+
+func (w *countingWriter) WriteHeader(status int) {
+	w.writeHeaders++
+	w.ResponseRecorder.WriteHeader(status)
+}
+
+func TestRespondJSON(t *testing.T) {
+	// Test an encodable payload is written with the given status
+	t.Run("Encodable", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/hello", nil)
+		w := &countingWriter{ResponseRecorder: httptest.NewRecorder()}
+
+		handlers.RespondJSON(w, req, http.StatusAccepted, map[string]string{"message": "ok"})
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Equal(t, 1, w.writeHeaders)
+		assert.JSONEq(t, `{"message":"ok"}`, w.Body.String())
+	})
+
+	// Test a payload that can't be encoded produces a single 500 error response
+	t.Run("Unencodable", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/hello", nil)
+		req.Header.Set("X-Request-ID", "test-request-id")
+		w := &countingWriter{ResponseRecorder: httptest.NewRecorder()}
+
+		handlers.RespondJSON(w, req, http.StatusOK, map[string]interface{}{"events": make(chan int)})
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, 1, w.writeHeaders)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{
+			"status": 500,
+			"message": "Internal Server Error",
+			"requestId": "test-request-id"
+		}`, w.Body.String())
+	})
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"mime"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Supported response content types
+const (
+	contentTypeJSON = "application/json"
+	contentTypeXML  = "application/xml"
+
+	// Streaming content types, which aren't negotiated
+	contentTypeNDJSON      = "application/x-ndjson"
+	contentTypeEventStream = "text/event-stream"
+)
+
+// supportedContentTypes lists the response content types in order of preference
+var supportedContentTypes = []string{contentTypeJSON, contentTypeXML}
+
+// negotiateContentType picks the supported content type that best matches an
+// Accept header. An empty header accepts JSON. It returns false if none of the
+// supported types are acceptable.
+func negotiateContentType(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return contentTypeJSON, true
+	}
+
+	best := ""
+	bestQ, bestSpecificity := 0.0, -1
+	for _, supported := range supportedContentTypes {
+		q, specificity := acceptQuality(accept, supported)
+		if q <= 0 {
+			continue
+		}
+
+		// Prefer higher quality, then the more specific match, then the
+		// earlier supported type
+		if q > bestQ || (q == bestQ && specificity > bestSpecificity) {
+			best, bestQ, bestSpecificity = supported, q, specificity
+		}
+	}
+
+	return best, best != ""
+}
+
+// acceptQuality returns the quality value the Accept header gives to
+// contentType, taken from the most specific matching media range, along with
+// that range's specificity (0 for */*, 1 for type/*, 2 for an exact match)
+func acceptQuality(accept, contentType string) (float64, int) {
+	typ, _, _ := strings.Cut(contentType, "/")
+
+	q, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		var s int
+		switch mediaType {
+		case contentType:
+			s = 2
+		case typ + "/*":
+			s = 1
+		case "*/*":
+			s = 0
+		default:
+			continue
+		}
+
+		if s <= specificity {
+			continue
+		}
+
+		specificity = s
+		q = 1
+		if value, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+	}
+
+	return q, specificity
+}
+
+// xmlList wraps a slice so it marshals as a single XML document
+type xmlList struct {
+	XMLName xml.Name    `xml:"items"`
+	Items   interface{} `xml:"item"`
+}
+
+// marshalXML marshals payload as an XML document, wrapping slices in an
+// <items> root element
+func marshalXML(payload interface{}) ([]byte, error) {
+	if v := reflect.ValueOf(payload); v.Kind() == reflect.Slice {
+		payload = xmlList{Items: payload}
+	}
+
+	body, err := xml.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dBiTech/go-apiTemplate/internal/service"
+	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+)
+
+const (
+	// watchWriteTimeout bounds how long sending one event to a watcher may take
+	watchWriteTimeout = 10 * time.Second
+
+	// sseKeepAlive is how often an idle event stream sends a comment, so
+	// proxies don't close it
+	sseKeepAlive = 15 * time.Second
+)
+
+// EventSubscriber subscribes to example change events, e.g. a service.Broadcaster
+type EventSubscriber interface {
+	Subscribe() (<-chan service.Event, func())
+}
+
+// WatchExamplesHandler handles GET /examples/watch
+// @Summary Watch example changes
+// @Description Upgrades to a WebSocket streaming a JSON event for every example created, updated or deleted
+// @Tags examples
+// @Security BearerAuth
+// @Success 101 {object} service.Event "Switching to a WebSocket of events"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 426 {string} string "Not a WebSocket handshake"
+// @Failure 501 {object} ErrorResponse "Watching is not available"
+// @Router /examples/watch [get]
+func (h *Handler) WatchExamplesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		log := logger.FromContext(ctx)
+
+		// Get span and add attributes
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(attribute.String("handler", "watchExamples"))
+
+		if h.events == nil {
+			RespondError(w, r, http.StatusNotImplemented, "Watching examples is not available", nil)
+			return
+		}
+
+		// Subscribe before accepting so no event after the handshake is missed
+		events, unsubscribe := h.events.Subscribe()
+		defer unsubscribe()
+
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			// Accept has already responded
+			log.Debug("failed to accept watch connection", logger.Error(err))
+			return
+		}
+		defer conn.CloseNow()
+
+		// Watchers only receive; CloseRead ends ctx when the client disconnects
+		ctx = conn.CloseRead(ctx)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case event, ok := <-events:
+				if !ok {
+					_ = conn.Close(websocket.StatusGoingAway, "event stream closed")
+					return
+				}
+				if err := writeEvent(ctx, conn, event); err != nil {
+					if !errors.Is(err, context.Canceled) {
+						log.Debug("failed to send watch event", logger.Error(err))
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// StreamExamplesHandler handles GET /examples/events
+// @Summary Stream example changes
+// @Description Streams a Server-Sent Event for every example created, updated or deleted, with a keep-alive comment every 15 seconds. A final close event is sent when the server ends the stream.
+// @Tags examples
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Success 200 {object} service.Event "One data line per event"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 501 {object} ErrorResponse "Streaming is not available"
+// @Router /examples/events [get]
+func (h *Handler) StreamExamplesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		log := logger.FromContext(ctx)
+
+		// Get span and add attributes
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(attribute.String("handler", "streamExamples"))
+
+		flusher, ok := w.(http.Flusher)
+		if h.events == nil || !ok {
+			RespondError(w, r, http.StatusNotImplemented, "Streaming examples is not available", nil)
+			return
+		}
+
+		events, unsubscribe := h.events.Subscribe()
+		defer unsubscribe()
+
+		// The stream outlives the server's write timeout
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			log.Debug("failed to clear write deadline", logger.Error(err))
+		}
+
+		w.Header().Set("Content-Type", contentTypeEventStream)
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		keepAlive := time.NewTicker(sseKeepAlive)
+		defer keepAlive.Stop()
+
+		// Stop when the client disconnects or the stream closes
+		for {
+			var err error
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-keepAlive.C:
+				_, err = io.WriteString(w, ": keep-alive\n\n")
+
+			case event, ok := <-events:
+				if !ok {
+					// Tell the client the stream ended on purpose, e.g. on shutdown
+					_, _ = io.WriteString(w, "event: close\ndata: stream closed\n\n")
+					flusher.Flush()
+					return
+				}
+				err = writeSSE(w, event)
+			}
+			if err != nil {
+				log.Debug("failed to send stream event", logger.Error(err))
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeSSE writes an event as a Server-Sent Event data line
+func writeSSE(w io.Writer, event service.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+	return err
+}
+
+// writeEvent sends an event to a watcher as JSON
+func writeEvent(ctx context.Context, conn *websocket.Conn, event service.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, watchWriteTimeout)
+	defer cancel()
+
+	return wsjson.Write(ctx, conn, event)
+}
@@ -0,0 +1,78 @@
+package handlers_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dBiTech/go-apiTemplate/internal/handlers"
+	"github.com/dBiTech/go-apiTemplate/internal/models"
+	"github.com/dBiTech/go-apiTemplate/internal/repository"
+	"github.com/dBiTech/go-apiTemplate/internal/service"
+	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+)
+
+func TestStreamExamplesHandler(t *testing.T) {
+	log := logger.Default()
+
+	// Test a created example arrives as an SSE frame, and disconnecting unsubscribes
+	t.Run("StreamsEvents", func(t *testing.T) {
+		events := service.NewBroadcaster(10)
+		svc := service.New(repository.NewMemoryRepository(log), log, nil, service.WithEventPublisher(events))
+		handler := handlers.NewHandler(log, svc, handlers.WithEventSubscriber(events))
+
+		ts := httptest.NewServer(handler.StreamExamplesHandler())
+		defer ts.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		require.Equal(t, 1, events.Subscribers())
+
+		created, err := svc.CreateExample(ctx, &models.ExampleRequest{Name: "Streamed"})
+		require.NoError(t, err)
+
+		reader := bufio.NewReader(resp.Body)
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(line, "data: "), "unexpected line %q", line)
+
+		var event service.Event
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+		assert.Equal(t, service.EventExampleCreated, event.Type)
+		assert.Equal(t, created.ID, event.EntityID)
+
+		blank, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "\n", blank)
+
+		cancel()
+		assert.Eventually(t, func() bool { return events.Subscribers() == 0 }, time.Second, 10*time.Millisecond)
+	})
+
+	// Test streaming responds 501 without an event source
+	t.Run("NotAvailable", func(t *testing.T) {
+		handler := handlers.NewHandler(log, new(MockService))
+
+		w := httptest.NewRecorder()
+		handler.StreamExamplesHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/examples/events", nil))
+
+		assert.Equal(t, http.StatusNotImplemented, w.Code)
+	})
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Baggage copies the given request headers, such as X-Tenant-ID, into
+// OpenTelemetry baggage on the request context so downstream spans and
+// services inherit them, and records them as "baggage.<key>" attributes on
+// the current span. Members are keyed by the lowercased header name and
+// replace any member with the same key; empty or unencodable headers are
+// skipped.
+func Baggage(keys []string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if len(keys) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			bag := baggage.FromContext(ctx)
+			span := trace.SpanFromContext(ctx)
+
+			changed := false
+			for _, key := range keys {
+				value := r.Header.Get(key)
+				if value == "" {
+					continue
+				}
+
+				name := strings.ToLower(key)
+				member, err := baggage.NewMemberRaw(name, value)
+				if err != nil {
+					continue
+				}
+				updated, err := bag.SetMember(member)
+				if err != nil {
+					continue
+				}
+
+				bag = updated
+				changed = true
+				span.SetAttributes(attribute.String("baggage."+name, value))
+			}
+
+			if changed {
+				r = r.WithContext(baggage.ContextWithBaggage(ctx, bag))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	appmiddleware "github.com/dBiTech/go-apiTemplate/internal/middleware"
+)
+
+func TestBaggage(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	// serve sends a request with the given headers inside a recorded span and
+	// returns the baggage the handler saw
+	serve := func(t *testing.T, headers map[string]string) baggage.Baggage {
+		t.Helper()
+
+		var bag baggage.Baggage
+		handler := appmiddleware.Baggage([]string{"X-Tenant-ID", "X-Customer-ID"})(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			bag = baggage.FromContext(r.Context())
+		}))
+
+		ctx, span := provider.Tracer("test").Start(context.Background(), "request")
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/hello", nil).WithContext(ctx)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		span.End()
+
+		return bag
+	}
+
+	// Test a configured header becomes a baggage member and a span attribute
+	t.Run("Propagated", func(t *testing.T) {
+		bag := serve(t, map[string]string{"X-Tenant-ID": "tenant-42"})
+
+		assert.Equal(t, "tenant-42", bag.Member("x-tenant-id").Value())
+
+		spans := recorder.Ended()
+		require.NotEmpty(t, spans)
+		assert.Contains(t, spans[len(spans)-1].Attributes(), attribute.String("baggage.x-tenant-id", "tenant-42"))
+	})
+
+	// Test missing and unconfigured headers are left out
+	t.Run("Ignored", func(t *testing.T) {
+		bag := serve(t, map[string]string{"X-Other": "value"})
+
+		assert.Zero(t, bag.Len())
+	})
+}
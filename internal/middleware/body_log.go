@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+
+	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+)
+
+// sensitiveBodyField matches a JSON string member whose key looks secret,
+// including a value cut off by truncation
+var sensitiveBodyField = regexp.MustCompile(`("[^"]*(?i:password|secret|token|authorization|api_?key)[^"]*"\s*:\s*)"(?:[^"\\]|\\.)*("|\\?$)`)
+
+// BodyLogger logs JSON request and response bodies through the request logger
+// while it is at debug level, and does nothing otherwise. At most maxBytes of
+// each body are logged, with the values of password, secret, token,
+// authorization and API key fields masked. The request body is restored for
+// the handler, buffering only the logged prefix. A non-positive maxBytes
+// disables body logging.
+func BodyLogger(maxBytes int) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if maxBytes <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			log := logger.FromContext(r.Context())
+			if !logger.DebugEnabled(log) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if r.Body != nil && isJSON(r.Header.Get("Content-Type")) {
+				prefix, err := io.ReadAll(io.LimitReader(r.Body, int64(maxBytes)+1))
+				r.Body = &replayBody{Reader: io.MultiReader(bytes.NewReader(prefix), r.Body), Closer: r.Body}
+				if err == nil && len(prefix) > 0 {
+					logBody(log, "request body", prefix, maxBytes)
+				}
+			}
+
+			bw := &bodyCaptureWriter{ResponseWriter: w, limit: maxBytes + 1}
+			next.ServeHTTP(bw, r)
+
+			if bw.body.Len() > 0 && isJSON(w.Header().Get("Content-Type")) {
+				logBody(log, "response body", bw.body.Bytes(), maxBytes)
+			}
+		})
+	}
+}
+
+// logBody logs body, masked and cut to maxBytes
+func logBody(log logger.Logger, msg string, body []byte, maxBytes int) {
+	truncated := len(body) > maxBytes
+	if truncated {
+		body = body[:maxBytes]
+	}
+
+	log.Debug(msg,
+		logger.String("body", string(sensitiveBodyField.ReplaceAll(body, []byte(`$1"****"`)))),
+		logger.Bool("truncated", truncated),
+	)
+}
+
+// isJSON reports whether contentType is application/json
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/json"
+}
+
+// replayBody reads the logged prefix followed by the rest of the original
+// body, and closes the original body
+type replayBody struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyCaptureWriter keeps the first limit bytes written to the response
+type bodyCaptureWriter struct {
+	http.ResponseWriter
+	body  bytes.Buffer
+	limit int
+}
+
+// Write captures the start of the response body
+func (w *bodyCaptureWriter) Write(b []byte) (int, error) {
+	if remaining := w.limit - w.body.Len(); remaining > 0 {
+		w.body.Write(b[:min(len(b), remaining)])
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher if the underlying ResponseWriter supports it
+func (w *bodyCaptureWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (w *bodyCaptureWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
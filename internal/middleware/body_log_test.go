@@ -0,0 +1,82 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	appmiddleware "github.com/dBiTech/go-apiTemplate/internal/middleware"
+	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+)
+
+func TestBodyLogger(t *testing.T) {
+	const maxBytes = 32
+	requestBody := `{"name":"A fairly long example name","password":"hunter2","description":"more text"}`
+
+	// serve sends requestBody through BodyLogger with a request logger at
+	// level and returns the logs and the body the handler received
+	serve := func(t *testing.T, level zapcore.Level, body string) (*observer.ObservedLogs, string) {
+		t.Helper()
+
+		core, logs := observer.New(level)
+		var received string
+		handler := appmiddleware.BodyLogger(maxBytes)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			received = string(b)
+
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":"1","token":"abc.def.ghi"}`))
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/examples", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(logger.ToContext(req.Context(), logger.NewFromZap(zap.New(core))))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		return logs, received
+	}
+
+	// Test the request body is logged truncated while the handler gets all of it
+	t.Run("RequestTruncated", func(t *testing.T) {
+		logs, received := serve(t, zapcore.DebugLevel, requestBody)
+
+		assert.Equal(t, requestBody, received)
+
+		entries := logs.FilterMessage("request body").All()
+		require.Len(t, entries, 1)
+		fields := entries[0].ContextMap()
+		assert.Equal(t, requestBody[:maxBytes], fields["body"])
+		assert.Equal(t, true, fields["truncated"])
+	})
+
+	// Test sensitive values are masked, even when cut off by truncation
+	t.Run("Redacted", func(t *testing.T) {
+		logs, _ := serve(t, zapcore.DebugLevel, `{"password":"hunter2hunter2hunter2hunter2"}`)
+
+		request := logs.FilterMessage("request body").All()
+		require.Len(t, request, 1)
+		assert.Equal(t, `{"password":"****"`, request[0].ContextMap()["body"])
+
+		response := logs.FilterMessage("response body").All()
+		require.Len(t, response, 1)
+		assert.Equal(t, `{"id":"1","token":"****"}`, response[0].ContextMap()["body"])
+		assert.Equal(t, false, response[0].ContextMap()["truncated"])
+	})
+
+	// Test nothing is logged above debug level
+	t.Run("InfoLevel", func(t *testing.T) {
+		logs, received := serve(t, zapcore.InfoLevel, requestBody)
+
+		assert.Equal(t, requestBody, received)
+		assert.Zero(t, logs.Len())
+	})
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// defaultCacheControl is sent on successful GET responses of routes without
+// a rule
+const defaultCacheControl = "no-store"
+
+// CacheControl sets the Cache-Control header of successful GET and HEAD
+// responses from rules keyed by route pattern, such as "/examples/{id}" ->
+// "max-age=30". A rule matches a route whose pattern equals it or ends with
+// it, so rules can leave out the prefix a router is mounted under. Routes
+// without a rule get no-store. Other methods, error responses and responses
+// that already set Cache-Control are left alone.
+func CacheControl(rules map[string]string) func(next http.Handler) http.Handler {
+	normalized := make(map[string]string, len(rules))
+	for pattern, value := range rules {
+		normalized[trimTrailingSlash(pattern)] = value
+	}
+
+	// lookup returns the Cache-Control value for a route pattern
+	lookup := func(pattern string) string {
+		pattern = trimTrailingSlash(pattern)
+		if value, ok := normalized[pattern]; ok {
+			return value
+		}
+		// Prefer the most specific rule when several match
+		best, value := "", defaultCacheControl
+		for rule, v := range normalized {
+			if strings.HasSuffix(pattern, rule) && len(rule) > len(best) {
+				best, value = rule, v
+			}
+		}
+		return value
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(&cacheControlResponseWriter{ResponseWriter: w, r: r, lookup: lookup}, r)
+		})
+	}
+}
+
+// trimTrailingSlash removes a trailing slash from any pattern but "/"
+func trimTrailingSlash(pattern string) string {
+	if len(pattern) > 1 {
+		return strings.TrimSuffix(pattern, "/")
+	}
+	return pattern
+}
+
+// cacheControlResponseWriter sets Cache-Control when the status is written,
+// once routing has resolved the request's pattern
+type cacheControlResponseWriter struct {
+	http.ResponseWriter
+	r           *http.Request
+	lookup      func(pattern string) string
+	wroteHeader bool
+}
+
+// WriteHeader sets Cache-Control for successful responses
+func (cw *cacheControlResponseWriter) WriteHeader(statusCode int) {
+	if !cw.wroteHeader {
+		cw.wroteHeader = true
+		if statusCode < http.StatusBadRequest && cw.Header().Get("Cache-Control") == "" {
+			cw.Header().Set("Cache-Control", cw.lookup(routePattern(cw.r)))
+		}
+	}
+	cw.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write writes an implicit 200 status before the body
+func (cw *cacheControlResponseWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher if the underlying ResponseWriter supports it
+func (cw *cacheControlResponseWriter) Flush() {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (cw *cacheControlResponseWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
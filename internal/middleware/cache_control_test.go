@@ -0,0 +1,77 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	appmiddleware "github.com/dBiTech/go-apiTemplate/internal/middleware"
+)
+
+func TestCacheControl(t *testing.T) {
+	ok := func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}
+
+	router := chi.NewRouter()
+	router.Route("/api/v1", func(r chi.Router) {
+		r.Use(appmiddleware.CacheControl(map[string]string{"/examples/{id}": "max-age=30"}))
+
+		r.Get("/examples", ok)
+		r.Post("/examples", ok)
+		r.Get("/examples/{id}", func(w http.ResponseWriter, r *http.Request) {
+			if chi.URLParam(r, "id") == "missing" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			ok(w, r)
+		})
+		r.Get("/private", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Cache-Control", "private")
+			ok(w, r)
+		})
+	})
+
+	// cacheControl sends a request and returns its status and Cache-Control
+	cacheControl := func(method, path string) (int, string) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Code, w.Header().Get("Cache-Control")
+	}
+
+	// Test a GET of a route with a rule gets its value
+	t.Run("MatchingGet", func(t *testing.T) {
+		status, value := cacheControl(http.MethodGet, "/api/v1/examples/abc")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "max-age=30", value)
+	})
+
+	// Test a GET of a route without a rule gets no-store
+	t.Run("DefaultGet", func(t *testing.T) {
+		_, value := cacheControl(http.MethodGet, "/api/v1/examples")
+		assert.Equal(t, "no-store", value)
+	})
+
+	// Test other methods are left alone
+	t.Run("Post", func(t *testing.T) {
+		status, value := cacheControl(http.MethodPost, "/api/v1/examples")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Empty(t, value)
+	})
+
+	// Test error responses are left alone
+	t.Run("Error", func(t *testing.T) {
+		status, value := cacheControl(http.MethodGet, "/api/v1/examples/missing")
+		assert.Equal(t, http.StatusNotFound, status)
+		assert.Empty(t, value)
+	})
+
+	// Test a header set by the handler is kept
+	t.Run("HandlerHeader", func(t *testing.T) {
+		_, value := cacheControl(http.MethodGet, "/api/v1/private")
+		assert.Equal(t, "private", value)
+	})
+}
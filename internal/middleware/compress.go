@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultMinCompressBytes is the smallest response Compress gzips unless
+// configured otherwise
+const DefaultMinCompressBytes = 1024
+
+// Compress gzips responses for clients that accept it. Responses are buffered
+// until minBytes have been written, so bodies smaller than that are sent as
+// is. A handler that flushes before reaching minBytes is streaming, so its
+// response is compressed from that point on regardless of size. Upgrade
+// requests pass through untouched.
+func Compress(minBytes int) func(next http.Handler) http.Handler {
+	if minBytes <= 0 {
+		minBytes = DefaultMinCompressBytes
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Upgrades such as WebSockets take over the connection, so
+			// holding back their 101 would break the handshake
+			if !acceptsGzip(r.Header.Get("Accept-Encoding")) || r.Header.Get("Upgrade") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			cw := &compressResponseWriter{ResponseWriter: w, minBytes: minBytes}
+			defer cw.close()
+
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || (coding != "gzip" && coding != "*") {
+			continue
+		}
+		if q, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil && parsed == 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// compressResponseWriter buffers a response until it is large enough to be
+// worth compressing, then either gzips it or writes it through unchanged
+type compressResponseWriter struct {
+	http.ResponseWriter
+	minBytes int
+
+	status  int
+	buf     []byte
+	decided bool
+	gz      *gzip.Writer
+}
+
+// WriteHeader records the status until the encoding has been decided
+func (cw *compressResponseWriter) WriteHeader(statusCode int) {
+	if cw.decided {
+		cw.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	if cw.status == 0 {
+		cw.status = statusCode
+	}
+}
+
+// Write buffers the body until minBytes is reached, then compresses it
+func (cw *compressResponseWriter) Write(b []byte) (int, error) {
+	if cw.decided {
+		if cw.gz != nil {
+			return cw.gz.Write(b)
+		}
+		return cw.ResponseWriter.Write(b)
+	}
+
+	cw.buf = append(cw.buf, b...)
+	if len(cw.buf) >= cw.minBytes {
+		if err := cw.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush commits to compression, writes everything buffered so far and
+// flushes it to the client
+func (cw *compressResponseWriter) Flush() {
+	if !cw.decided {
+		if err := cw.decide(true); err != nil {
+			return
+		}
+	}
+	if cw.gz != nil {
+		_ = cw.gz.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// close writes a response that never reached minBytes uncompressed and
+// finishes a compressed one
+func (cw *compressResponseWriter) close() {
+	if !cw.decided {
+		_ = cw.decide(false)
+	}
+	if cw.gz != nil {
+		_ = cw.gz.Close()
+	}
+}
+
+// decide writes the header and the buffered body, gzipping them if compress
+// is set and the response hasn't already been encoded by the handler. A
+// strong ETag is made weak on a gzipped response, as its bytes differ from
+// the representation the ETag was computed for.
+func (cw *compressResponseWriter) decide(compress bool) error {
+	cw.decided = true
+
+	header := cw.Header()
+	if compress && header.Get("Content-Encoding") == "" && bodyAllowed(cw.status) {
+		// Sniff the content type from the plain body, as net/http would
+		// otherwise sniff the compressed bytes
+		if header.Get("Content-Type") == "" && len(cw.buf) > 0 {
+			header.Set("Content-Type", http.DetectContentType(cw.buf))
+		}
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+		cw.gz = gzip.NewWriter(cw.ResponseWriter)
+	}
+
+	if cw.status != 0 {
+		cw.ResponseWriter.WriteHeader(cw.status)
+	}
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if cw.gz != nil {
+		_, err := cw.gz.Write(buf)
+		return err
+	}
+	_, err := cw.ResponseWriter.Write(buf)
+	return err
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (cw *compressResponseWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// bodyAllowed reports whether a response with status may carry a body
+func bodyAllowed(status int) bool {
+	return status != http.StatusNoContent && status != http.StatusNotModified && (status == 0 || status >= 200)
+}
@@ -0,0 +1,125 @@
+package middleware_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appmiddleware "github.com/dBiTech/go-apiTemplate/internal/middleware"
+)
+
+func TestCompress(t *testing.T) {
+	small := strings.Repeat("a", 100)
+	large := strings.Repeat("b", 2048)
+
+	// serve sends a request accepting gzip through Compress with a 1KB threshold
+	serve := func(acceptEncoding string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		appmiddleware.Compress(1024)(handler).ServeHTTP(w, req)
+		return w
+	}
+
+	// gunzip decompresses a response body
+	gunzip := func(t *testing.T, w *httptest.ResponseRecorder) string {
+		t.Helper()
+
+		zr, err := gzip.NewReader(w.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	// write returns a handler writing body in chunks of size with status
+	write := func(status int, body string, size int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(status)
+			for i := 0; i < len(body); i += size {
+				_, _ = w.Write([]byte(body[i:min(i+size, len(body))]))
+			}
+		}
+	}
+
+	// Test a body below the threshold is sent uncompressed
+	t.Run("BelowThreshold", func(t *testing.T) {
+		w := serve("gzip", write(http.StatusCreated, small, 10))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+		assert.Equal(t, small, w.Body.String())
+	})
+
+	// Test a body above the threshold is gzipped, even when written in chunks
+	t.Run("AboveThreshold", func(t *testing.T) {
+		w := serve("gzip, deflate", write(http.StatusOK, large, 100))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+		assert.Equal(t, large, gunzip(t, w))
+	})
+
+	// Test clients that don't accept gzip get the plain body
+	t.Run("NotAccepted", func(t *testing.T) {
+		for _, header := range []string{"", "identity", "gzip;q=0"} {
+			w := serve(header, write(http.StatusOK, large, len(large)))
+
+			assert.Empty(t, w.Header().Get("Content-Encoding"), header)
+			assert.Equal(t, large, w.Body.String(), header)
+		}
+	})
+
+	// Test a flushed response is compressed and every chunk reaches the client
+	t.Run("Streaming", func(t *testing.T) {
+		w := serve("gzip", func(w http.ResponseWriter, r *http.Request) {
+			flusher, ok := w.(http.Flusher)
+			require.True(t, ok)
+
+			for i := 0; i < 3; i++ {
+				_, _ = w.Write([]byte("line\n"))
+				flusher.Flush()
+			}
+		})
+
+		assert.True(t, w.Flushed)
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "line\nline\nline\n", gunzip(t, w))
+	})
+
+	// Test a strong ETag is made weak when the response is gzipped
+	t.Run("WeakensETag", func(t *testing.T) {
+		withETag := func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", `"abc"`)
+			_, _ = w.Write([]byte(large))
+		}
+
+		w := serve("gzip", withETag)
+		assert.Equal(t, `W/"abc"`, w.Header().Get("ETag"))
+
+		w = serve("", withETag)
+		assert.Equal(t, `"abc"`, w.Header().Get("ETag"))
+	})
+
+	// Test a response already encoded by the handler is left alone
+	t.Run("AlreadyEncoded", func(t *testing.T) {
+		w := serve("gzip", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "br")
+			_, _ = w.Write([]byte(large))
+		})
+
+		assert.Equal(t, "br", w.Header().Get("Content-Encoding"))
+		assert.Equal(t, large, w.Body.String())
+	})
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+// concurrencyRetryAfter is the Retry-After value, in seconds, sent with
+// requests rejected by MaxConcurrent
+const concurrencyRetryAfter = "1"
+
+// MaxConcurrent limits the server to n requests in progress at once. A
+// request arriving while n are being handled is rejected immediately with 503
+// Service Unavailable and a Retry-After header rather than queued. A
+// non-positive n disables the limit.
+func MaxConcurrent(n int) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if n <= 0 {
+			return next
+		}
+
+		sem := make(chan struct{}, n)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case sem <- struct{}{}:
+			default:
+				w.Header().Set("Retry-After", concurrencyRetryAfter)
+				writeJSONError(w, errorResponse{
+					Status:  http.StatusServiceUnavailable,
+					Message: "Service Unavailable",
+					Error:   "Too many concurrent requests",
+				})
+				return
+			}
+			defer func() { <-sem }()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
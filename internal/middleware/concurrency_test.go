@@ -0,0 +1,80 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	appmiddleware "github.com/dBiTech/go-apiTemplate/internal/middleware"
+)
+
+func TestMaxConcurrent(t *testing.T) {
+	const limit = 2
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	handler := appmiddleware.MaxConcurrent(limit)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		entered <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// serve sends a request to the limited handler
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/hello", nil))
+		return w
+	}
+
+	// Saturate the limiter with requests that block in the handler
+	var wg sync.WaitGroup
+	codes := make(chan int, limit)
+	for i := 0; i < limit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- serve().Code
+		}()
+		<-entered
+	}
+
+	// Test the overflow request is rejected without reaching the handler
+	t.Run("Overflow", func(t *testing.T) {
+		w := serve()
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+		assert.Contains(t, w.Body.String(), "Too many concurrent requests")
+	})
+
+	close(release)
+	wg.Wait()
+	close(codes)
+
+	// Test the admitted requests completed normally
+	t.Run("Admitted", func(t *testing.T) {
+		for code := range codes {
+			assert.Equal(t, http.StatusOK, code)
+		}
+	})
+
+	// Test capacity is released once requests finish
+	t.Run("Released", func(t *testing.T) {
+		go func() { <-entered }()
+		assert.Equal(t, http.StatusOK, serve().Code)
+	})
+
+	// Test a non-positive limit disables the middleware
+	t.Run("Disabled", func(t *testing.T) {
+		next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+
+		w := httptest.NewRecorder()
+		appmiddleware.MaxConcurrent(0)(next).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+)
+
+// RequireJSON rejects POST, PUT and PATCH requests with 415 Unsupported
+// Media Type unless their Content-Type is application/json. Parameters such
+// as charset are allowed. Requests with other methods pass through.
+func RequireJSON() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch:
+			default:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err != nil || mediaType != "application/json" {
+				writeJSONError(w, errorResponse{
+					Status:  http.StatusUnsupportedMediaType,
+					Message: "Unsupported Media Type",
+					Error:   "Content-Type must be application/json",
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	appmiddleware "github.com/dBiTech/go-apiTemplate/internal/middleware"
+)
+
+func TestRequireJSON(t *testing.T) {
+	handler := appmiddleware.RequireJSON()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	// serve sends a request with the given method and Content-Type
+	serve := func(method, contentType string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/examples", strings.NewReader(`{"name":"Example"}`))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	// Test a JSON body passes, with or without a charset
+	t.Run("JSON", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, serve(http.MethodPost, "application/json").Code)
+		assert.Equal(t, http.StatusCreated, serve(http.MethodPut, "application/json; charset=utf-8").Code)
+	})
+
+	// Test another content type is rejected
+	t.Run("WrongContentType", func(t *testing.T) {
+		w := serve(http.MethodPost, "text/plain")
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		assert.Contains(t, w.Body.String(), "Content-Type must be application/json")
+	})
+
+	// Test a missing content type is rejected
+	t.Run("MissingContentType", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnsupportedMediaType, serve(http.MethodPatch, "").Code)
+	})
+
+	// Test methods without a body aren't checked
+	t.Run("OtherMethods", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, serve(http.MethodGet, "").Code)
+		assert.Equal(t, http.StatusCreated, serve(http.MethodDelete, "").Code)
+	})
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RequestTimeoutHeader is the request header clients use to ask the server to
+// give up after a number of milliseconds
+const RequestTimeoutHeader = "X-Request-Timeout"
+
+// MinRequestTimeout is the shortest deadline a client can ask for. Shorter
+// ones are raised to it so requests can't be made to fail before any work is
+// done.
+const MinRequestTimeout = 100 * time.Millisecond
+
+// ClientDeadline sets a context deadline from the X-Request-Timeout header,
+// at least MinRequestTimeout and capped at max. The header is a number of
+// milliseconds or a Go duration such as "1.5s". Requests with a missing,
+// malformed or non-positive header keep the server's default deadline.
+func ClientDeadline(max time.Duration) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timeout, ok := parseRequestTimeout(r.Header.Get(RequestTimeoutHeader))
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if timeout < MinRequestTimeout {
+				timeout = MinRequestTimeout
+			}
+			if max > 0 && timeout > max {
+				timeout = max
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// parseRequestTimeout parses an X-Request-Timeout value, reporting false for
+// empty, malformed or non-positive values
+func parseRequestTimeout(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	var timeout time.Duration
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		timeout = time.Duration(ms) * time.Millisecond
+	} else if d, err := time.ParseDuration(value); err == nil {
+		timeout = d
+	} else {
+		return 0, false
+	}
+
+	return timeout, timeout > 0
+}
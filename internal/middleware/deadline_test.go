@@ -0,0 +1,75 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	appmiddleware "github.com/dBiTech/go-apiTemplate/internal/middleware"
+)
+
+func TestClientDeadline(t *testing.T) {
+	// serve sends a request with the given header through the middleware and
+	// returns the time left until the handler's deadline, if it has one
+	serve := func(header string) (time.Duration, bool) {
+		var remaining time.Duration
+		var hasDeadline bool
+		handler := appmiddleware.ClientDeadline(time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var deadline time.Time
+			deadline, hasDeadline = r.Context().Deadline()
+			remaining = time.Until(deadline)
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if header != "" {
+			req.Header.Set(appmiddleware.RequestTimeoutHeader, header)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		return remaining, hasDeadline
+	}
+
+	// Test a header in milliseconds sets the deadline
+	t.Run("Applied", func(t *testing.T) {
+		remaining, ok := serve("200")
+		assert.True(t, ok)
+		assert.InDelta(t, 200*time.Millisecond, remaining, float64(50*time.Millisecond))
+	})
+
+	// Test a Go duration is accepted
+	t.Run("Duration", func(t *testing.T) {
+		remaining, ok := serve("300ms")
+		assert.True(t, ok)
+		assert.InDelta(t, 300*time.Millisecond, remaining, float64(50*time.Millisecond))
+	})
+
+	// Test a timeout above the maximum is capped
+	t.Run("Capped", func(t *testing.T) {
+		remaining, ok := serve("60000")
+		assert.True(t, ok)
+		assert.InDelta(t, time.Second, remaining, float64(50*time.Millisecond))
+	})
+
+	// Test a timeout below the minimum is raised to it
+	t.Run("Minimum", func(t *testing.T) {
+		remaining, ok := serve("1ns")
+		assert.True(t, ok)
+		assert.InDelta(t, appmiddleware.MinRequestTimeout, remaining, float64(50*time.Millisecond))
+	})
+
+	// Test malformed and non-positive headers are ignored
+	t.Run("Malformed", func(t *testing.T) {
+		for _, header := range []string{"soon", "-5", "0", "1.5"} {
+			_, ok := serve(header)
+			assert.False(t, ok, header)
+		}
+	})
+
+	// Test a missing header leaves the context alone
+	t.Run("Missing", func(t *testing.T) {
+		_, ok := serve("")
+		assert.False(t, ok)
+	})
+}
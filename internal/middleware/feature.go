@@ -0,0 +1,22 @@
+package middleware
+
+import "net/http"
+
+// RequireFeature responds 404 Not Found, as if the route didn't exist, while
+// isEnabled reports the named feature as off. The feature is checked on every
+// request.
+func RequireFeature(isEnabled func(name string) bool, name string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !isEnabled(name) {
+				writeJSONError(w, errorResponse{
+					Status:  http.StatusNotFound,
+					Message: "Not Found",
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
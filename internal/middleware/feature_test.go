@@ -0,0 +1,52 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dBiTech/go-apiTemplate/internal/config"
+	appmiddleware "github.com/dBiTech/go-apiTemplate/internal/middleware"
+)
+
+func TestRequireFeature(t *testing.T) {
+	cfg := &config.Config{Features: map[string]bool{"bulkCreate": true, "search": false}}
+
+	router := chi.NewRouter()
+	router.With(appmiddleware.RequireFeature(cfg.IsEnabled, "bulkCreate")).Post("/examples/bulk", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusMultiStatus)
+	})
+	router.With(appmiddleware.RequireFeature(cfg.IsEnabled, "search")).Get("/examples/search", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	router.With(appmiddleware.RequireFeature(cfg.IsEnabled, "unknown")).Get("/examples/unknown", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// serve sends a request through the router
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	// Test an enabled feature's route is served normally
+	t.Run("Enabled", func(t *testing.T) {
+		assert.Equal(t, http.StatusMultiStatus, serve(http.MethodPost, "/examples/bulk").Code)
+	})
+
+	// Test a disabled feature's route is not found
+	t.Run("Disabled", func(t *testing.T) {
+		w := serve(http.MethodGet, "/examples/search")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.JSONEq(t, `{"status":404,"message":"Not Found"}`, w.Body.String())
+	})
+
+	// Test an unconfigured feature is off
+	t.Run("Unknown", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/examples/unknown").Code)
+	})
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPSOptions configures RedirectHTTPS
+type HTTPSOptions struct {
+	// Enabled turns the middleware on; leave it off unless the server is
+	// reached over TLS, directly or through a trusted TLS-terminating proxy
+	Enabled bool
+
+	// HSTSMaxAge is the max-age of the Strict-Transport-Security header set
+	// on HTTPS responses (0 omits the header)
+	HSTSMaxAge time.Duration
+
+	// HSTSIncludeSubdomains adds includeSubDomains to the HSTS header
+	HSTSIncludeSubdomains bool
+
+	// ExcludePaths are route patterns served over plain HTTP without a
+	// redirect, such as load balancer health checks
+	ExcludePaths []string
+}
+
+// RedirectHTTPS redirects plain HTTP requests to the same URL over HTTPS with
+// a 308, so the method and body are kept, and sets HSTS on HTTPS responses. A
+// request is HTTPS if it arrived over TLS, or if X-Forwarded-Proto says so and
+// RealIP, mounted ahead of it, found the peer to be a trusted proxy. It is a
+// no-op unless opts.Enabled is set.
+func RedirectHTTPS(opts HTTPSOptions) func(next http.Handler) http.Handler {
+	excluded := newPathSet(opts.ExcludePaths)
+
+	hsts := ""
+	if opts.HSTSMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d", int64(opts.HSTSMaxAge.Seconds()))
+		if opts.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		if !opts.Enabled {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isHTTPS(r) {
+				if hsts != "" {
+					w.Header().Set("Strict-Transport-Security", hsts)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if excluded.matches(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Drop any plain HTTP port so the default HTTPS port is used
+			host := r.Host
+			if h, _, err := net.SplitHostPort(host); err == nil {
+				host = h
+			}
+
+			http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+		})
+	}
+}
+
+// isHTTPS reports whether the request reached the server, or the trusted
+// proxy in front of it, over TLS
+func isHTTPS(r *http.Request) bool {
+	if r.TLS != nil {
+		return true
+	}
+	if !FromTrustedProxy(r.Context()) {
+		return false
+	}
+
+	// A proxy chain may list several protocols; the first is the client's
+	proto, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Proto"), ",")
+	return strings.EqualFold(strings.TrimSpace(proto), "https")
+}
@@ -0,0 +1,96 @@
+package middleware_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appmiddleware "github.com/dBiTech/go-apiTemplate/internal/middleware"
+)
+
+func TestRedirectHTTPS(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	realIP, err := appmiddleware.RealIP([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+	handler := realIP(appmiddleware.RedirectHTTPS(appmiddleware.HTTPSOptions{
+		Enabled:               true,
+		HSTSMaxAge:            365 * 24 * time.Hour,
+		HSTSIncludeSubdomains: true,
+		ExcludePaths:          []string{"/health"},
+	})(ok))
+
+	// serve sends req through the middleware
+	serve := func(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	// Test a plain HTTP request is redirected to the same URL over HTTPS
+	t.Run("Redirect", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "http://api.example.com:8080/api/v1/examples?limit=5", nil)
+		w := serve(handler, req)
+
+		assert.Equal(t, http.StatusPermanentRedirect, w.Code)
+		assert.Equal(t, "https://api.example.com/api/v1/examples?limit=5", w.Header().Get("Location"))
+		assert.Empty(t, w.Header().Get("Strict-Transport-Security"))
+	})
+
+	// Test a request the trusted proxy received over HTTPS is served with HSTS
+	t.Run("ForwardedProto", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "http://api.example.com/api/v1/hello", nil)
+		req.RemoteAddr = "10.1.2.3:4000"
+		req.Header.Set("X-Forwarded-Proto", "https")
+		w := serve(handler, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "max-age=31536000; includeSubDomains", w.Header().Get("Strict-Transport-Security"))
+	})
+
+	// Test X-Forwarded-Proto from an untrusted peer is ignored
+	t.Run("SpoofedForwardedProto", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "http://api.example.com/api/v1/hello", nil)
+		req.RemoteAddr = "203.0.113.7:4000"
+		req.Header.Set("X-Forwarded-Proto", "https")
+		w := serve(handler, req)
+
+		assert.Equal(t, http.StatusPermanentRedirect, w.Code)
+		assert.Empty(t, w.Header().Get("Strict-Transport-Security"))
+	})
+
+	// Test a direct TLS request is served with HSTS
+	t.Run("DirectTLS", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "https://api.example.com/api/v1/hello", nil)
+		req.TLS = &tls.ConnectionState{}
+		w := serve(handler, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, w.Header().Get("Strict-Transport-Security"))
+	})
+
+	// Test excluded paths are served over plain HTTP
+	t.Run("ExcludedPath", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "http://10.0.0.5/health", nil)
+		w := serve(handler, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Location"))
+	})
+
+	// Test the middleware does nothing when disabled
+	t.Run("Disabled", func(t *testing.T) {
+		disabled := appmiddleware.RedirectHTTPS(appmiddleware.HTTPSOptions{HSTSMaxAge: time.Hour})(ok)
+		req := httptest.NewRequest(http.MethodGet, "http://api.example.com/api/v1/hello", nil)
+		w := serve(disabled, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Strict-Transport-Security"))
+	})
+}
@@ -0,0 +1,328 @@
+package middleware
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header carrying the client's idempotency key
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader is set on responses replayed from the idempotency store
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// IdempotentResponse is a stored response that can be replayed
+type IdempotentResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	// BodyHash is the SHA-256 of the request body the response was made for
+	BodyHash [sha256.Size]byte
+}
+
+// IdempotencyStore stores responses by idempotency key. A key is reserved
+// while its first request is handled, then either set or released.
+type IdempotencyStore interface {
+	// Reserve claims key for a new request. It returns the stored response
+	// when there is one, and reserved is false when the key is already
+	// stored or claimed by a request still in flight.
+	Reserve(key string) (stored *IdempotentResponse, reserved bool)
+	Set(key string, response *IdempotentResponse)
+	Release(key string)
+}
+
+// Idempotency replays the stored response for unsafe requests that repeat a
+// previously seen Idempotency-Key instead of invoking the handler again. Keys
+// are scoped to the credential the caller presented. Reusing a key with a
+// different request body is rejected with 422, and repeating one whose request
+// is still in flight with 409. Bodies larger than maxBodyBytes are rejected
+// with 413; a non-positive maxBodyBytes doesn't limit them.
+func Idempotency(store IdempotencyStore, maxBodyBytes int64) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" || !isUnsafeMethod(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := readBody(w, r, maxBodyBytes)
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeJSONError(w, errorResponse{
+					Status:  http.StatusRequestEntityTooLarge,
+					Message: "Request Entity Too Large",
+					Error:   "request body must not exceed " + strconv.FormatInt(tooLarge.Limit, 10) + " bytes",
+				})
+				return
+			}
+			if err != nil {
+				writeJSONError(w, errorResponse{
+					Status:  http.StatusBadRequest,
+					Message: "Bad Request",
+					Error:   "failed to read request body",
+				})
+				return
+			}
+			bodyHash := sha256.Sum256(body)
+
+			// Scope the key to the caller and endpoint so keys can't collide
+			// across clients or routes
+			storeKey := idempotencyScope(r) + " " + r.Method + " " + r.URL.Path + " " + key
+
+			stored, reserved := store.Reserve(storeKey)
+			switch {
+			case stored != nil && stored.BodyHash != bodyHash:
+				writeJSONError(w, errorResponse{
+					Status:  http.StatusUnprocessableEntity,
+					Message: "Unprocessable Entity",
+					Error:   "Idempotency-Key was already used with a different request body",
+				})
+				return
+			case stored != nil:
+				// Replay the stored response
+				for name, values := range stored.Header {
+					// Keep headers set for this request, such as X-Request-ID
+					if _, exists := w.Header()[name]; !exists {
+						w.Header()[name] = values
+					}
+				}
+				w.Header().Set(IdempotentReplayedHeader, "true")
+				w.WriteHeader(stored.StatusCode)
+				_, _ = w.Write(stored.Body)
+				return
+			case !reserved:
+				writeJSONError(w, errorResponse{
+					Status:  http.StatusConflict,
+					Message: "Conflict",
+					Error:   "a request with this Idempotency-Key is still in progress",
+				})
+				return
+			}
+
+			// Release the reservation unless a response is stored, including
+			// when the handler panics
+			recorded := false
+			defer func() {
+				if !recorded {
+					store.Release(storeKey)
+				}
+			}()
+
+			// Capture the response while writing it through
+			rec := &recordingResponseWriter{
+				ResponseWriter: w,
+				statusCode:     http.StatusOK,
+			}
+
+			next.ServeHTTP(rec, r)
+
+			// Server errors are transient, so let the client retry them
+			if rec.statusCode >= http.StatusInternalServerError {
+				return
+			}
+
+			store.Set(storeKey, &IdempotentResponse{
+				StatusCode: rec.statusCode,
+				Header:     recordedHeader(w.Header()),
+				Body:       rec.body.Bytes(),
+				BodyHash:   bodyHash,
+			})
+			recorded = true
+		})
+	}
+}
+
+// unrecordedHeaders are set by outer middleware such as Compress for the
+// encoding of one response, so replaying them would misdescribe the stored body
+var unrecordedHeaders = []string{"Content-Encoding", "Content-Length", "Vary"}
+
+// recordedHeader returns a copy of header without unrecordedHeaders
+func recordedHeader(header http.Header) http.Header {
+	recorded := header.Clone()
+	for _, name := range unrecordedHeaders {
+		recorded.Del(name)
+	}
+	return recorded
+}
+
+// readBody reads the whole request body, up to maxBytes when positive, and
+// restores it for the handler
+func readBody(w http.ResponseWriter, r *http.Request, maxBytes int64) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+
+	reader := r.Body
+	if maxBytes > 0 {
+		reader = http.MaxBytesReader(w, r.Body, maxBytes)
+	}
+
+	body, err := io.ReadAll(reader)
+	_ = r.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// idempotencyScope identifies the caller of r by a hash of the credential it
+// presented. The middleware runs before route authentication, so the
+// credential isn't verified; callers without one share a scope.
+func idempotencyScope(r *http.Request) string {
+	if credential := r.Header.Get("Authorization"); credential != "" {
+		sum := sha256.Sum256([]byte(credential))
+		return "credential:" + hex.EncodeToString(sum[:])
+	}
+
+	return "anonymous"
+}
+
+// isUnsafeMethod reports whether the HTTP method may change server state
+func isUnsafeMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// recordingResponseWriter writes through to the underlying writer while
+// keeping a copy of the status code and body
+type recordingResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+// WriteHeader captures the status code
+func (rw *recordingResponseWriter) WriteHeader(statusCode int) {
+	rw.statusCode = statusCode
+	rw.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write captures the response body
+func (rw *recordingResponseWriter) Write(b []byte) (int, error) {
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (rw *recordingResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// MemoryIdempotencyStore is an in-memory IdempotencyStore with TTL eviction.
+// When it holds maxEntries responses, storing another evicts the oldest.
+type MemoryIdempotencyStore struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu       sync.Mutex
+	order    *list.List
+	entries  map[string]*list.Element
+	inflight map[string]struct{}
+}
+
+// idempotencyEntry is a stored response and its expiry
+type idempotencyEntry struct {
+	key       string
+	response  *IdempotentResponse
+	expiresAt time.Time
+}
+
+// NewMemoryIdempotencyStore creates a new in-memory idempotency store holding
+// at most maxEntries responses; a non-positive maxEntries doesn't limit them
+func NewMemoryIdempotencyStore(ttl time.Duration, maxEntries int) *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+		inflight:   make(map[string]struct{}),
+	}
+}
+
+// Reserve returns the stored response for key if it hasn't expired, and
+// otherwise claims key unless another request already has
+func (s *MemoryIdempotencyStore) Reserve(key string) (*IdempotentResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictExpired(time.Now())
+
+	if elem, ok := s.entries[key]; ok {
+		return elem.Value.(*idempotencyEntry).response, false
+	}
+
+	if _, ok := s.inflight[key]; ok {
+		return nil, false
+	}
+
+	s.inflight[key] = struct{}{}
+	return nil, true
+}
+
+// Set stores the response for key, releasing its reservation and evicting
+// any expired entries, then the oldest ones while the store is over capacity
+func (s *MemoryIdempotencyStore) Set(key string, response *IdempotentResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.evictExpired(now)
+
+	delete(s.inflight, key)
+	if elem, ok := s.entries[key]; ok {
+		s.order.Remove(elem)
+	}
+
+	// Every entry lives for the same TTL, so appending keeps the list in
+	// expiry order
+	s.entries[key] = s.order.PushBack(&idempotencyEntry{
+		key:       key,
+		response:  response,
+		expiresAt: now.Add(s.ttl),
+	})
+
+	for s.maxEntries > 0 && s.order.Len() > s.maxEntries {
+		oldest := s.order.Front()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*idempotencyEntry).key)
+	}
+}
+
+// Release drops the reservation of key without storing a response
+func (s *MemoryIdempotencyStore) Release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.inflight, key)
+}
+
+// evictExpired removes the entries that expired by now, oldest first. The
+// caller must hold s.mu.
+func (s *MemoryIdempotencyStore) evictExpired(now time.Time) {
+	for elem := s.order.Front(); elem != nil; elem = s.order.Front() {
+		entry := elem.Value.(*idempotencyEntry)
+		if !now.After(entry.expiresAt) {
+			return
+		}
+		s.order.Remove(elem)
+		delete(s.entries, entry.key)
+	}
+}
@@ -0,0 +1,296 @@
+package middleware_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appmiddleware "github.com/dBiTech/go-apiTemplate/internal/middleware"
+)
+
+func TestIdempotency(t *testing.T) {
+	calls := 0
+	handler := appmiddleware.Idempotency(appmiddleware.NewMemoryIdempotencyStore(time.Minute, 0), 0)(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			calls++
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"call":` + strconv.Itoa(calls) + `}`))
+		}),
+	)
+
+	newRequest := func(key string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/examples", strings.NewReader(`{"name":"test"}`))
+		if key != "" {
+			req.Header.Set(appmiddleware.IdempotencyKeyHeader, key)
+		}
+		return req
+	}
+
+	// newRequestAs creates a request for key with the given credential and body
+	newRequestAs := func(key, credential, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/examples", strings.NewReader(body))
+		req.Header.Set(appmiddleware.IdempotencyKeyHeader, key)
+		if credential != "" {
+			req.Header.Set("Authorization", credential)
+		}
+		return req
+	}
+
+	// Test the first call executes the handler
+	t.Run("FirstCall", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequest("key-1"))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.JSONEq(t, `{"call":1}`, w.Body.String())
+		assert.Empty(t, w.Header().Get(appmiddleware.IdempotentReplayedHeader))
+		assert.Equal(t, 1, calls)
+	})
+
+	// Test the second call with the same key replays the stored response
+	t.Run("SecondCallReplayed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequest("key-1"))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.JSONEq(t, `{"call":1}`, w.Body.String())
+		assert.Equal(t, "true", w.Header().Get(appmiddleware.IdempotentReplayedHeader))
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.Equal(t, 1, calls)
+	})
+
+	// Test a different key executes the handler again
+	t.Run("DifferentKey", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequest("key-2"))
+
+		assert.JSONEq(t, `{"call":2}`, w.Body.String())
+		assert.Equal(t, 2, calls)
+	})
+
+	// Test requests without a key are never replayed
+	t.Run("NoKey", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequest(""))
+
+		assert.JSONEq(t, `{"call":3}`, w.Body.String())
+		assert.Equal(t, 3, calls)
+	})
+
+	// Test another caller's key is not replayed to a different credential
+	t.Run("ScopedToCredential", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequestAs("key-3", "Bearer alice", `{"name":"test"}`))
+		assert.JSONEq(t, `{"call":4}`, w.Body.String())
+
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequestAs("key-3", "Bearer bob", `{"name":"test"}`))
+		assert.JSONEq(t, `{"call":5}`, w.Body.String())
+		assert.Empty(t, w.Header().Get(appmiddleware.IdempotentReplayedHeader))
+
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequestAs("key-3", "Bearer alice", `{"name":"test"}`))
+		assert.JSONEq(t, `{"call":4}`, w.Body.String())
+		assert.Equal(t, "true", w.Header().Get(appmiddleware.IdempotentReplayedHeader))
+	})
+
+	// Test reusing a key with a different body is rejected
+	t.Run("DifferentBody", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequestAs("key-1", "", `{"name":"other"}`))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, 5, calls)
+	})
+}
+
+func TestIdempotencyInFlight(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := appmiddleware.Idempotency(appmiddleware.NewMemoryIdempotencyStore(time.Minute, 0), 0)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Fail") != "" {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			close(started)
+			<-release
+			w.WriteHeader(http.StatusCreated)
+		}),
+	)
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/examples", strings.NewReader(`{}`))
+		req.Header.Set(appmiddleware.IdempotencyKeyHeader, "key")
+		return req
+	}
+
+	// Test a duplicate of a request still in flight is rejected
+	t.Run("Concurrent", func(t *testing.T) {
+		done := make(chan int)
+		go func() {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, newRequest())
+			done <- w.Code
+		}()
+		<-started
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequest())
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		close(release)
+		assert.Equal(t, http.StatusCreated, <-done)
+	})
+
+	// Test a server error releases the key so the request can be retried
+	t.Run("ReleasedAfterServerError", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			req := newRequest()
+			req.Header.Set(appmiddleware.IdempotencyKeyHeader, "failing")
+			req.Header.Set("X-Fail", "true")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		}
+	})
+}
+
+func TestMemoryIdempotencyStoreExpiry(t *testing.T) {
+	store := appmiddleware.NewMemoryIdempotencyStore(10*time.Millisecond, 0)
+	_, reserved := store.Reserve("key")
+	require.True(t, reserved)
+	store.Set("key", &appmiddleware.IdempotentResponse{StatusCode: http.StatusOK})
+
+	stored, _ := store.Reserve("key")
+	assert.NotNil(t, stored)
+
+	time.Sleep(20 * time.Millisecond)
+
+	stored, reserved = store.Reserve("key")
+	assert.Nil(t, stored)
+	assert.True(t, reserved)
+}
+
+func TestMemoryIdempotencyStoreReservation(t *testing.T) {
+	store := appmiddleware.NewMemoryIdempotencyStore(time.Minute, 0)
+
+	// Test a reserved key can't be reserved again until released
+	_, reserved := store.Reserve("key")
+	require.True(t, reserved)
+	_, reserved = store.Reserve("key")
+	assert.False(t, reserved)
+
+	store.Release("key")
+	_, reserved = store.Reserve("key")
+	assert.True(t, reserved)
+}
+
+func TestMemoryIdempotencyStoreMaxEntries(t *testing.T) {
+	store := appmiddleware.NewMemoryIdempotencyStore(time.Minute, 2)
+	for _, key := range []string{"a", "b", "c"} {
+		_, reserved := store.Reserve(key)
+		require.True(t, reserved)
+		store.Set(key, &appmiddleware.IdempotentResponse{StatusCode: http.StatusOK})
+	}
+
+	// Test the oldest entry was evicted to make room for the newest
+	stored, reserved := store.Reserve("a")
+	assert.Nil(t, stored)
+	assert.True(t, reserved)
+
+	for _, key := range []string{"b", "c"} {
+		stored, _ := store.Reserve(key)
+		assert.NotNil(t, stored, key)
+	}
+}
+
+func TestIdempotencyMaxBody(t *testing.T) {
+	calls := 0
+	handler := appmiddleware.Idempotency(appmiddleware.NewMemoryIdempotencyStore(time.Minute, 0), 8)(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusCreated)
+		}),
+	)
+
+	// serve posts body with an idempotency key
+	serve := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/examples", strings.NewReader(body))
+		req.Header.Set(appmiddleware.IdempotencyKeyHeader, "key-"+body)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	// Test a body within the limit reaches the handler
+	t.Run("WithinLimit", func(t *testing.T) {
+		w := serve("12345678")
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, 1, calls)
+	})
+
+	// Test a larger body is rejected without calling the handler
+	t.Run("TooLarge", func(t *testing.T) {
+		w := serve("123456789")
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestIdempotencyWithCompress(t *testing.T) {
+	body := strings.Repeat("a", 2048)
+	handler := appmiddleware.Compress(1024)(appmiddleware.Idempotency(appmiddleware.NewMemoryIdempotencyStore(time.Minute, 0), 0)(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(body))
+		}),
+	))
+
+	// serve sends a request accepting the given encoding with the same key
+	serve := func(acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/examples", strings.NewReader(`{}`))
+		req.Header.Set(appmiddleware.IdempotencyKeyHeader, "key")
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	// Test the first and replayed responses are both valid gzip
+	t.Run("ReplayCompressed", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			w := serve("gzip")
+			require.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+
+			zr, err := gzip.NewReader(w.Body)
+			require.NoError(t, err)
+			decoded, err := io.ReadAll(zr)
+			require.NoError(t, err)
+			assert.Equal(t, body, string(decoded))
+		}
+	})
+
+	// Test a replay to a client that doesn't accept gzip is sent plain
+	t.Run("ReplayUncompressed", func(t *testing.T) {
+		w := serve("")
+
+		assert.Equal(t, "true", w.Header().Get(appmiddleware.IdempotentReplayedHeader))
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, body, w.Body.String())
+	})
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// MethodOverrideHeader names the method a POST should be routed as
+const MethodOverrideHeader = "X-HTTP-Method-Override"
+
+// methodOverrideField is the form field naming the method of a form POST
+const methodOverrideField = "_method"
+
+// MethodOverride routes a POST as the method named by the
+// X-HTTP-Method-Override header or, for form posts, the _method field, for
+// clients behind proxies that only allow GET and POST. Only PUT, PATCH and
+// DELETE can be requested; other values are ignored. It must run before
+// routing.
+func MethodOverride() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			method := r.Header.Get(MethodOverrideHeader)
+			if method == "" && isFormPost(r) {
+				method = r.PostFormValue(methodOverrideField)
+			}
+
+			switch method = strings.ToUpper(strings.TrimSpace(method)); method {
+			case http.MethodPut, http.MethodPatch, http.MethodDelete:
+				r.Method = method
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isFormPost reports whether the request body is URL-encoded form data
+func isFormPost(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/x-www-form-urlencoded"
+}
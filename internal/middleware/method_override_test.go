@@ -0,0 +1,66 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	appmiddleware "github.com/dBiTech/go-apiTemplate/internal/middleware"
+)
+
+func TestMethodOverride(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(appmiddleware.MethodOverride())
+	r.Post("/examples/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("post"))
+	})
+	r.Delete("/examples/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("delete"))
+	})
+	r.Get("/examples/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("get"))
+	})
+
+	// serve sends req through the router and returns the handler that ran
+	serve := func(req *http.Request) string {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	// Test a POST with the override header runs the delete handler
+	t.Run("Header", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/examples/1", nil)
+		req.Header.Set(appmiddleware.MethodOverrideHeader, "delete")
+
+		assert.Equal(t, "delete", serve(req))
+	})
+
+	// Test a form POST can override with the _method field
+	t.Run("FormField", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/examples/1", strings.NewReader("_method=DELETE"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		assert.Equal(t, "delete", serve(req))
+	})
+
+	// Test methods outside the allow-list are ignored
+	t.Run("NotAllowed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/examples/1", nil)
+		req.Header.Set(appmiddleware.MethodOverrideHeader, http.MethodGet)
+
+		assert.Equal(t, "post", serve(req))
+	})
+
+	// Test only POSTs are overridden
+	t.Run("OnlyPost", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/examples/1", nil)
+		req.Header.Set(appmiddleware.MethodOverrideHeader, http.MethodDelete)
+
+		assert.Equal(t, "get", serve(req))
+	})
+}
@@ -3,24 +3,48 @@
 package middleware
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/dBiTech/go-apiTemplate/internal/auth"
 	"github.com/dBiTech/go-apiTemplate/pkg/logger"
 	"github.com/dBiTech/go-apiTemplate/pkg/metrics"
 	"github.com/dBiTech/go-apiTemplate/pkg/telemetry"
 )
 
+// ContextKey is a key for values the middleware stores in the request context
+type ContextKey string
+
 // RequestIDKey is the context key for the request ID
-const RequestIDKey = "request_id"
+const RequestIDKey ContextKey = "request_id"
+
+// RequestIDFromContext returns the request ID stored by RequestLogger, or ""
+// if there is none
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(RequestIDKey).(string)
+	return requestID
+}
+
+// RequestLogger adds request logging, skipping the log lines for requests
+// whose route pattern is in excludePaths. When accessLog is set, each request
+// is logged as a single combined entry on completion instead of separate
+// started and completed entries.
+func RequestLogger(log logger.Logger, excludePaths []string, accessLog bool) func(next http.Handler) http.Handler {
+	excluded := newPathSet(excludePaths)
 
-// RequestLogger adds request logging
-func RequestLogger(log logger.Logger) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
@@ -44,18 +68,52 @@ func RequestLogger(log logger.Logger) func(next http.Handler) http.Handler {
 				logger.String("user_agent", r.UserAgent()),
 			)
 
-			// Add logger to context
-			ctx := logger.ToContext(r.Context(), reqLogger)
+			// Add the request ID and logger to context
+			ctx := context.WithValue(r.Context(), RequestIDKey, requestID)
+			ctx = logger.ToContext(ctx, reqLogger)
 			r = r.WithContext(ctx)
 
+			// Skip logging for excluded paths
+			if excluded.matches(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			// Create response wrapper to capture status
 			rw := &responseWriter{
 				ResponseWriter: w,
 				statusCode:     http.StatusOK,
 			}
 
+			if accessLog {
+				// Count the request body as the handler reads it
+				body := &countingReadCloser{ReadCloser: r.Body}
+				if r.Body != nil {
+					r.Body = body
+				}
+
+				next.ServeHTTP(rw, r)
+
+				requestSize := r.ContentLength
+				if requestSize < 0 {
+					requestSize = body.size
+				}
+
+				// Read the logger back so fields added further down the chain,
+				// such as the authenticated user, are included
+				logger.FromContext(ctx).Info("request",
+					logger.Int("status", rw.statusCode),
+					logger.String("status_class", statusClass(rw.statusCode)),
+					logger.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+					logger.Int64("request_size", requestSize),
+					logger.Int("response_size", rw.size),
+					logger.String("route", routePattern(r)),
+				)
+				return
+			}
+
 			// Log request start
-			reqLogger.Info("request started")
+			reqLogger.Debug("request started")
 
 			// Process request
 			next.ServeHTTP(rw, r)
@@ -63,8 +121,8 @@ func RequestLogger(log logger.Logger) func(next http.Handler) http.Handler {
 			// Calculate duration
 			duration := time.Since(start)
 
-			// Log request completion
-			reqLogger.Info("request completed",
+			// Log request completion with any fields added further down the chain
+			logger.FromContext(ctx).Info("request completed",
 				logger.Int("status", rw.statusCode),
 				logger.Duration("duration", duration),
 				logger.Int("response_size", rw.size),
@@ -73,10 +131,47 @@ func RequestLogger(log logger.Logger) func(next http.Handler) http.Handler {
 	}
 }
 
-// Metrics adds prometheus metrics
-func Metrics(m *metrics.Metrics) func(next http.Handler) http.Handler {
+// UserLogFields adds the authenticated user's ID and scopes to the request
+// logger, so handler log lines and the request's completion log are
+// attributed. It must run after the auth middleware.
+func UserLogFields() func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
-		return m.InstrumentHandler(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			var fields []logger.Field
+			if userID, ok := auth.GetUserID(ctx); ok {
+				fields = append(fields, logger.String("user_id", userID))
+			}
+			if scopes, ok := auth.GetScopes(ctx); ok {
+				fields = append(fields, logger.String("scopes", strings.Join(scopes, ",")))
+			}
+
+			if len(fields) > 0 && !logger.AddFields(ctx, fields...) {
+				// No request logger to update, so store one for the handler
+				r = r.WithContext(logger.ToContext(ctx, logger.FromContext(ctx).With(fields...)))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Metrics adds prometheus metrics, skipping requests whose route pattern is in excludePaths
+func Metrics(m *metrics.Metrics, excludePaths []string) func(next http.Handler) http.Handler {
+	excluded := newPathSet(excludePaths)
+
+	return func(next http.Handler) http.Handler {
+		instrumented := m.InstrumentHandler(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if excluded.matches(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			instrumented.ServeHTTP(w, r)
+		})
 	}
 }
 
@@ -121,28 +216,59 @@ func Tracing(tel *telemetry.Telemetry) func(next http.Handler) http.Handler {
 	}
 }
 
-// Recover middleware handles panics
-func Recover(log logger.Logger) func(next http.Handler) http.Handler {
+// Recover middleware handles panics, responding 500 with a JSON error body
+// carrying the request and trace IDs. If the handler had already started its
+// response, the panic is only logged, since a second status and body would
+// corrupt the one being sent.
+func Recover(log logger.Logger, debugErrors bool) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := &recoverResponseWriter{ResponseWriter: w}
+
 			defer func() {
-				if err := recover(); err != nil {
-					// Log the error
+				if rec := recover(); rec != nil {
+					// Coerce the recovered value into an error
+					err, ok := rec.(error)
+					if !ok {
+						err = fmt.Errorf("%v", rec)
+					}
+
+					// Log the error with the stack trace
+					stack := debug.Stack()
 					log.Error("panic recovered",
-						logger.Any("error", err),
+						logger.Error(err),
+						logger.String("stack", string(stack)),
+						logger.Bool("response_started", rw.wroteHeader),
 					)
 
-					// Extract span from context
+					// Record the error on the span if one is active
 					span := trace.SpanFromContext(r.Context())
-					span.SetStatus(codes.Error, "panic")
-					span.RecordError(err.(error))
+					if span.IsRecording() {
+						span.SetStatus(codes.Error, "panic")
+						span.RecordError(err)
+					}
 
-					// Return 500 Internal Server Error
-					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+					if rw.wroteHeader {
+						return
+					}
+
+					// Return 500 Internal Server Error, exposing the stack only in debug mode
+					resp := errorResponse{
+						Status:    http.StatusInternalServerError,
+						Message:   "Internal Server Error",
+						RequestID: r.Header.Get("X-Request-ID"),
+					}
+					if spanCtx := span.SpanContext(); spanCtx.HasTraceID() {
+						resp.TraceID = spanCtx.TraceID().String()
+					}
+					if debugErrors {
+						resp.Stack = truncateStack(stack)
+					}
+					writeJSONError(w, resp)
 				}
 			}()
 
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(rw, r)
 		})
 	}
 }
@@ -186,6 +312,91 @@ func CORS(allowedOrigins []string) func(next http.Handler) http.Handler {
 	}
 }
 
+// maxDebugStackBytes caps the stack trace included in debug error responses
+const maxDebugStackBytes = 4096
+
+// errorResponse mirrors the JSON error body returned by the handlers
+type errorResponse struct {
+	Status    int    `json:"status"`
+	Message   string `json:"message"`
+	Error     string `json:"error,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+	TraceID   string `json:"traceId,omitempty"`
+	Stack     string `json:"stack,omitempty"`
+}
+
+// writeJSONError writes a JSON error body with its status
+func writeJSONError(w http.ResponseWriter, resp errorResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.Status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// truncateStack returns the stack trace capped at maxDebugStackBytes
+func truncateStack(stack []byte) string {
+	if len(stack) <= maxDebugStackBytes {
+		return string(stack)
+	}
+	return string(stack[:maxDebugStackBytes]) + "\n... (truncated)"
+}
+
+// pathSet is a set of chi route patterns
+type pathSet map[string]struct{}
+
+// newPathSet creates a path set from a list of route patterns
+func newPathSet(paths []string) pathSet {
+	set := make(pathSet, len(paths))
+	for _, path := range paths {
+		set[path] = struct{}{}
+	}
+	return set
+}
+
+// matches reports whether the request's route pattern is in the set
+func (p pathSet) matches(r *http.Request) bool {
+	if len(p) == 0 {
+		return false
+	}
+	_, ok := p[routePattern(r)]
+	return ok
+}
+
+// routePattern resolves the chi route pattern that handles the request,
+// falling back to the raw URL path when no route matches. Router-level
+// middleware runs before routing, so the pattern is looked up directly
+// against the router when it hasn't been matched yet.
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+		if rctx.Routes != nil {
+			if pattern := rctx.Routes.Find(chi.NewRouteContext(), r.Method, r.URL.Path); pattern != "" {
+				return pattern
+			}
+		}
+	}
+	return r.URL.Path
+}
+
+// statusClass returns the class of an HTTP status code, e.g. "2xx"
+func statusClass(status int) string {
+	return strconv.Itoa(status/100) + "xx"
+}
+
+// countingReadCloser counts the bytes read from a request body
+type countingReadCloser struct {
+	io.ReadCloser
+	size int64
+}
+
+// Read counts the bytes read
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.size += int64(n)
+	return n, err
+}
+
 // responseWriter is a wrapper for http.ResponseWriter that tracks status code and size
 type responseWriter struct {
 	http.ResponseWriter
@@ -212,3 +423,43 @@ func (rw *responseWriter) Flush() {
 		f.Flush()
 	}
 }
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// recoverResponseWriter tracks whether the response has started so Recover
+// knows whether it can still send an error
+type recoverResponseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+// WriteHeader marks the response as started, unless the status is
+// informational and another will follow
+func (rw *recoverResponseWriter) WriteHeader(statusCode int) {
+	if statusCode >= http.StatusOK || statusCode == http.StatusSwitchingProtocols {
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write marks the response as started
+func (rw *recoverResponseWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	return rw.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher if the underlying ResponseWriter supports it
+func (rw *recoverResponseWriter) Flush() {
+	rw.wroteHeader = true
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (rw *recoverResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,435 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/dBiTech/go-apiTemplate/internal/auth"
+	appmiddleware "github.com/dBiTech/go-apiTemplate/internal/middleware"
+	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+	"github.com/dBiTech/go-apiTemplate/pkg/metrics"
+)
+
+// logEntry is a single recorded log line
+type logEntry struct {
+	level  string
+	msg    string
+	fields []logger.Field
+}
+
+// recordingLogger is a logger.Logger that records every log line
+type recordingLogger struct {
+	mu      *sync.Mutex
+	entries *[]logEntry
+	fields  []logger.Field
+}
+
+func newRecordingLogger() *recordingLogger {
+	return &recordingLogger{
+		mu:      &sync.Mutex{},
+		entries: &[]logEntry{},
+	}
+}
+
+func (l *recordingLogger) record(level, msg string, fields []logger.Field) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	all := append(append([]logger.Field{}, l.fields...), fields...)
+	*l.entries = append(*l.entries, logEntry{level: level, msg: msg, fields: all})
+}
+
+func (l *recordingLogger) Debug(msg string, fields ...logger.Field) { l.record("debug", msg, fields) }
+func (l *recordingLogger) Info(msg string, fields ...logger.Field)  { l.record("info", msg, fields) }
+func (l *recordingLogger) Warn(msg string, fields ...logger.Field)  { l.record("warn", msg, fields) }
+func (l *recordingLogger) Error(msg string, fields ...logger.Field) { l.record("error", msg, fields) }
+func (l *recordingLogger) Fatal(msg string, fields ...logger.Field) { l.record("fatal", msg, fields) }
+
+func (l *recordingLogger) With(fields ...logger.Field) logger.Logger {
+	return &recordingLogger{
+		mu:      l.mu,
+		entries: l.entries,
+		fields:  append(append([]logger.Field{}, l.fields...), fields...),
+	}
+}
+
+func (l *recordingLogger) WithContext(_ context.Context) logger.Logger {
+	return l
+}
+
+// Entries returns a copy of the recorded log lines
+func (l *recordingLogger) Entries() []logEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]logEntry{}, *l.entries...)
+}
+
+// fieldString returns the string value of the log entry's field with the given key
+func fieldString(entry logEntry, key string) string {
+	for _, field := range entry.fields {
+		if field.Key == key {
+			return field.String
+		}
+	}
+	return ""
+}
+
+func TestExcludePaths(t *testing.T) {
+	log := newRecordingLogger()
+	m := metrics.NewMetrics("test", metrics.WithGoCollector(false), metrics.WithProcessCollector(false))
+	excludePaths := []string{"/health", "/examples/{id}"}
+
+	router := chi.NewRouter()
+	router.Use(appmiddleware.RequestLogger(log, excludePaths, false))
+	router.Use(appmiddleware.Metrics(m, excludePaths))
+	router.Get("/health", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	router.Get("/examples/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	router.Get("/hello", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	scrape := func() string {
+		w := httptest.NewRecorder()
+		m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		return w.Body.String()
+	}
+
+	// Test an excluded static path
+	t.Run("ExcludedPath", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, w.Header().Get("X-Request-ID"))
+		assert.Empty(t, log.Entries())
+		assert.NotContains(t, scrape(), "test_http_requests_total")
+	})
+
+	// Test an excluded route pattern
+	t.Run("ExcludedPattern", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/examples/abc", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, log.Entries())
+		assert.NotContains(t, scrape(), "test_http_requests_total")
+	})
+
+	// Test a path that isn't excluded
+	t.Run("IncludedPath", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/hello", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, log.Entries())
+
+		var found bool
+		for _, line := range strings.Split(scrape(), "\n") {
+			if strings.HasPrefix(line, "test_http_requests_total") {
+				assert.Contains(t, line, `path="/hello"`)
+				found = true
+			}
+		}
+		assert.True(t, found)
+	})
+}
+
+func TestRecover(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+	}{
+		{name: "String", value: "something went wrong"},
+		{name: "Error", value: errors.New("something went wrong")},
+		{name: "Int", value: 42},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := newRecordingLogger()
+
+			handler := appmiddleware.Recover(log, false)(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+				panic(tt.value)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+			w := httptest.NewRecorder()
+
+			require.NotPanics(t, func() {
+				handler.ServeHTTP(w, req)
+			})
+
+			assert.Equal(t, http.StatusInternalServerError, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+			var resp map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.InDelta(t, float64(http.StatusInternalServerError), resp["status"], 0)
+			assert.Equal(t, "Internal Server Error", resp["message"])
+			assert.NotContains(t, resp, "stack")
+
+			entries := log.Entries()
+			require.Len(t, entries, 1)
+			assert.Equal(t, "panic recovered", entries[0].msg)
+			assert.Contains(t, fieldString(entries[0], "stack"), "middleware_test.go")
+		})
+	}
+
+	// Test debug mode includes a truncated stack in the response body
+	t.Run("DebugErrors", func(t *testing.T) {
+		log := newRecordingLogger()
+
+		handler := appmiddleware.Recover(log, true)(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+			panic("something went wrong")
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		stack, ok := resp["stack"].(string)
+		require.True(t, ok)
+		assert.Contains(t, stack, "goroutine")
+		assert.LessOrEqual(t, len(stack), 4096+len("\n... (truncated)"))
+	})
+
+	// Test the error body carries the request ID
+	t.Run("RequestID", func(t *testing.T) {
+		handler := appmiddleware.Recover(newRecordingLogger(), false)(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+			panic("something went wrong")
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+		req.Header.Set("X-Request-ID", "req-123")
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "req-123", resp["requestId"])
+	})
+
+	// Test a panic after the response started leaves it as written
+	t.Run("ResponseStarted", func(t *testing.T) {
+		log := newRecordingLogger()
+
+		handler := appmiddleware.Recover(log, false)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte("partial"))
+			panic("something went wrong")
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+		w := httptest.NewRecorder()
+
+		require.NotPanics(t, func() {
+			handler.ServeHTTP(w, req)
+		})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+		assert.Equal(t, "partial", w.Body.String())
+
+		entries := log.Entries()
+		require.Len(t, entries, 1)
+		assert.Equal(t, "panic recovered", entries[0].msg)
+	})
+}
+
+func TestAccessLog(t *testing.T) {
+	// newRouter creates a router logging to an observer core
+	newRouter := func(accessLog bool) (*chi.Mux, *observer.ObservedLogs) {
+		core, logs := observer.New(zapcore.DebugLevel)
+		log := logger.NewFromZap(zap.New(core))
+
+		router := chi.NewRouter()
+		router.Use(appmiddleware.RequestLogger(log, nil, accessLog))
+		router.Post("/examples/{id}", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.Copy(io.Discard, r.Body)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte("created"))
+		})
+
+		return router, logs
+	}
+
+	// Test a 404 is logged as a single entry with the access log fields
+	t.Run("NotFound", func(t *testing.T) {
+		router, logs := newRouter(true)
+
+		req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusNotFound, w.Code)
+
+		entries := logs.All()
+		require.Len(t, entries, 1)
+
+		entry := entries[0]
+		assert.Equal(t, zapcore.InfoLevel, entry.Level)
+		assert.Equal(t, "request", entry.Message)
+
+		fields := entry.ContextMap()
+		assert.Equal(t, int64(http.StatusNotFound), fields["status"])
+		assert.Equal(t, "4xx", fields["status_class"])
+		assert.Contains(t, fields, "latency_ms")
+		assert.Equal(t, int64(0), fields["request_size"])
+		assert.Equal(t, int64(w.Body.Len()), fields["response_size"])
+		assert.Equal(t, "/missing", fields["route"])
+		assert.Equal(t, w.Header().Get("X-Request-ID"), fields["request_id"])
+	})
+
+	// Test the matched route pattern and request size are logged
+	t.Run("Matched", func(t *testing.T) {
+		router, logs := newRouter(true)
+
+		req := httptest.NewRequest(http.MethodPost, "/examples/abc", strings.NewReader(`{"name":"x"}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		entries := logs.All()
+		require.Len(t, entries, 1)
+
+		fields := entries[0].ContextMap()
+		assert.Equal(t, "2xx", fields["status_class"])
+		assert.Equal(t, int64(len(`{"name":"x"}`)), fields["request_size"])
+		assert.Equal(t, int64(len("created")), fields["response_size"])
+		assert.Equal(t, "/examples/{id}", fields["route"])
+	})
+
+	// Test the default mode logs the started line at debug
+	t.Run("StartedIsDebug", func(t *testing.T) {
+		router, logs := newRouter(false)
+
+		req := httptest.NewRequest(http.MethodPost, "/examples/abc", nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		started := logs.FilterMessage("request started").All()
+		require.Len(t, started, 1)
+		assert.Equal(t, zapcore.DebugLevel, started[0].Level)
+
+		completed := logs.FilterMessage("request completed").All()
+		require.Len(t, completed, 1)
+		assert.Equal(t, zapcore.InfoLevel, completed[0].Level)
+	})
+}
+
+func TestUserLogFields(t *testing.T) {
+	log := newRecordingLogger()
+
+	authenticator, err := auth.NewAuthenticator(auth.Config{
+		JWTSecret:         "test-secret",
+		JWTSigningMethod:  "HS256",
+		JWTExpirationTime: time.Hour,
+	}, logger.Default())
+	require.NoError(t, err)
+
+	router := chi.NewRouter()
+	router.Use(appmiddleware.RequestLogger(log, nil, false))
+	router.With(authenticator.JWTAuthMiddleware(nil), appmiddleware.UserLogFields()).
+		Get("/me", func(w http.ResponseWriter, r *http.Request) {
+			logger.FromContext(r.Context()).Info("handled")
+			w.WriteHeader(http.StatusOK)
+		})
+
+	token, err := authenticator.GenerateJWTToken("user-42", nil, []string{"read", "write"})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// entry returns the recorded log line with msg
+	entry := func(t *testing.T, msg string) logEntry {
+		t.Helper()
+
+		for _, e := range log.Entries() {
+			if e.msg == msg {
+				return e
+			}
+		}
+		require.Failf(t, "log line not found", "%q", msg)
+		return logEntry{}
+	}
+
+	// Test the handler's log line is attributed to the user
+	t.Run("HandlerLog", func(t *testing.T) {
+		handled := entry(t, "handled")
+		assert.Equal(t, "user-42", fieldString(handled, "user_id"))
+		assert.Equal(t, "read,write", fieldString(handled, "scopes"))
+		assert.NotEmpty(t, fieldString(handled, "request_id"))
+	})
+
+	// Test the request logger's completion line picks up the user too
+	t.Run("CompletionLog", func(t *testing.T) {
+		assert.Equal(t, "user-42", fieldString(entry(t, "request completed"), "user_id"))
+	})
+}
+
+func TestRequestIDFromContext(t *testing.T) {
+	var seen string
+	handler := appmiddleware.RequestLogger(logger.Default(), nil, false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = appmiddleware.RequestIDFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// Test a generated ID matches the response header
+	t.Run("Generated", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.NotEmpty(t, seen)
+		assert.Equal(t, w.Header().Get("X-Request-ID"), seen)
+	})
+
+	// Test an incoming ID is passed through
+	t.Run("Incoming", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Request-ID", "incoming-id")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, "incoming-id", seen)
+		assert.Equal(t, "incoming-id", w.Header().Get("X-Request-ID"))
+	})
+
+	// Test a context without an ID returns ""
+	t.Run("Missing", func(t *testing.T) {
+		assert.Empty(t, appmiddleware.RequestIDFromContext(context.Background()))
+	})
+}
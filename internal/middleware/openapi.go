@@ -0,0 +1,386 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// openAPISpec is the subset of a Swagger 2.0 document used for validation
+type openAPISpec struct {
+	BasePath    string                                `json:"basePath"`
+	Paths       map[string]map[string]json.RawMessage `json:"paths"`
+	Definitions map[string]*openAPISchema             `json:"definitions"`
+}
+
+// openAPIOperation is the subset of a Swagger 2.0 operation used for validation
+type openAPIOperation struct {
+	Parameters []openAPIParameter `json:"parameters"`
+}
+
+// openAPIParameter is a Swagger 2.0 operation parameter
+type openAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required"`
+	Type     string         `json:"type"`
+	Enum     []interface{}  `json:"enum"`
+	Minimum  *float64       `json:"minimum"`
+	Maximum  *float64       `json:"maximum"`
+	Schema   *openAPISchema `json:"schema"`
+}
+
+// openAPISchema is the subset of a JSON schema that is validated
+type openAPISchema struct {
+	Ref        string                    `json:"$ref"`
+	Type       string                    `json:"type"`
+	Required   []string                  `json:"required"`
+	Properties map[string]*openAPISchema `json:"properties"`
+	Items      *openAPISchema            `json:"items"`
+	Enum       []interface{}             `json:"enum"`
+	MinLength  *int                      `json:"minLength"`
+	MaxLength  *int                      `json:"maxLength"`
+	Minimum    *float64                  `json:"minimum"`
+	Maximum    *float64                  `json:"maximum"`
+}
+
+// openAPIRoute is a spec path split into segments, with its operations by method
+type openAPIRoute struct {
+	segments   []string
+	operations map[string]*openAPIOperation
+}
+
+// openAPIValidator validates requests against the routes of a spec
+type openAPIValidator struct {
+	routes      []openAPIRoute
+	definitions map[string]*openAPISchema
+}
+
+// schemaViolation describes where a value broke its schema
+type schemaViolation struct {
+	location   string
+	schemaPath string
+	message    string
+}
+
+// Error formats the violation for the client
+func (v *schemaViolation) Error() string {
+	return fmt.Sprintf("%s: %s (schema %s)", v.location, v.message, v.schemaPath)
+}
+
+// OpenAPIValidator validates request query parameters and JSON bodies against
+// a Swagger 2.0 spec, such as the one generated by swag, before they reach the
+// handlers. Requests that violate the spec get a 400 naming the violated
+// schema path. Requests for routes the spec doesn't describe pass through.
+func OpenAPIValidator(spec []byte) (func(next http.Handler) http.Handler, error) {
+	validator, err := newOpenAPIValidator(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			op := validator.operation(r.Method, r.URL.Path)
+			if op == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if err := validator.validate(op, r); err != nil {
+				writeJSONError(w, errorResponse{
+					Status:  http.StatusBadRequest,
+					Message: "Request validation failed",
+					Error:   err.Error(),
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// newOpenAPIValidator parses spec into a validator
+func newOpenAPIValidator(spec []byte) (*openAPIValidator, error) {
+	var doc openAPISpec
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+	}
+
+	v := &openAPIValidator{definitions: doc.Definitions}
+	basePath := strings.TrimSuffix(doc.BasePath, "/")
+
+	for path, item := range doc.Paths {
+		route := openAPIRoute{
+			segments:   splitPath(basePath + path),
+			operations: make(map[string]*openAPIOperation),
+		}
+
+		// Path items can hold non-operation keys such as shared parameters
+		for method, raw := range item {
+			switch strings.ToUpper(method) {
+			case http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+				http.MethodOptions, http.MethodHead, http.MethodPatch:
+			default:
+				continue
+			}
+
+			var op openAPIOperation
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, fmt.Errorf("failed to parse OpenAPI operation %s %s: %w", method, path, err)
+			}
+			route.operations[strings.ToUpper(method)] = &op
+		}
+
+		v.routes = append(v.routes, route)
+	}
+
+	return v, nil
+}
+
+// splitPath splits a URL path into its non-empty segments
+func splitPath(path string) []string {
+	return strings.FieldsFunc(path, func(r rune) bool { return r == '/' })
+}
+
+// operation finds the operation for method and path, preferring the route
+// with the fewest templated segments when several match
+func (v *openAPIValidator) operation(method, path string) *openAPIOperation {
+	segments := splitPath(path)
+
+	var best *openAPIOperation
+	bestParams := -1
+	for _, route := range v.routes {
+		op, ok := route.operations[method]
+		if !ok || len(route.segments) != len(segments) {
+			continue
+		}
+
+		params, matched := 0, true
+		for i, segment := range route.segments {
+			if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+				params++
+				continue
+			}
+			if segment != segments[i] {
+				matched = false
+				break
+			}
+		}
+
+		if matched && (bestParams < 0 || params < bestParams) {
+			best, bestParams = op, params
+		}
+	}
+
+	return best
+}
+
+// validate checks the request's query parameters and body against op. The
+// body is buffered and restored so handlers can still read it.
+func (v *openAPIValidator) validate(op *openAPIOperation, r *http.Request) error {
+	query := r.URL.Query()
+
+	for _, param := range op.Parameters {
+		switch param.In {
+		case "query":
+			if err := validateQueryParam(param, query); err != nil {
+				return err
+			}
+
+		case "body":
+			if param.Schema == nil || !isJSONRequest(r) {
+				continue
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				return &schemaViolation{location: "body", schemaPath: "#", message: "could not be read"}
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			if len(bytes.TrimSpace(body)) == 0 {
+				if param.Required {
+					return &schemaViolation{location: "body", schemaPath: "#", message: "is required"}
+				}
+				continue
+			}
+
+			var value interface{}
+			if err := json.Unmarshal(body, &value); err != nil {
+				return &schemaViolation{location: "body", schemaPath: "#", message: "is not valid JSON"}
+			}
+
+			if err := v.validateValue(param.Schema, "#", "body", value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// isJSONRequest reports whether the request body is declared as JSON or is untyped
+func isJSONRequest(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// validateQueryParam checks a query parameter's presence, type and bounds
+func validateQueryParam(param openAPIParameter, query map[string][]string) error {
+	values, ok := query[param.Name]
+	location := "query." + param.Name
+	schemaPath := "#/parameters/" + param.Name
+
+	if !ok || len(values) == 0 {
+		if param.Required {
+			return &schemaViolation{location: location, schemaPath: schemaPath + "/required", message: "is required"}
+		}
+		return nil
+	}
+
+	raw := values[0]
+	var value interface{} = raw
+	switch param.Type {
+	case "integer", "number":
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil || (param.Type == "integer" && n != math.Trunc(n)) {
+			return &schemaViolation{location: location, schemaPath: schemaPath + "/type", message: "must be of type " + param.Type}
+		}
+		value = n
+
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return &schemaViolation{location: location, schemaPath: schemaPath + "/type", message: "must be of type boolean"}
+		}
+		value = b
+	}
+
+	schema := &openAPISchema{Enum: param.Enum, Minimum: param.Minimum, Maximum: param.Maximum}
+	return checkConstraints(schema, schemaPath, location, value)
+}
+
+// validateValue checks value against schema, resolving references to definitions
+func (v *openAPIValidator) validateValue(schema *openAPISchema, schemaPath, location string, value interface{}) error {
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/definitions/")
+		resolved, ok := v.definitions[name]
+		if !ok {
+			return nil
+		}
+		return v.validateValue(resolved, schema.Ref, location, value)
+	}
+
+	if schema.Type != "" && !matchesType(schema.Type, value) {
+		return &schemaViolation{location: location, schemaPath: schemaPath + "/type", message: "must be of type " + schema.Type}
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, ok := typed[name]; !ok {
+				return &schemaViolation{
+					location:   location + "." + name,
+					schemaPath: schemaPath + "/required",
+					message:    "is required",
+				}
+			}
+		}
+
+		for name, property := range schema.Properties {
+			propValue, ok := typed[name]
+			if !ok {
+				continue
+			}
+			if err := v.validateValue(property, schemaPath+"/properties/"+name, location+"."+name, propValue); err != nil {
+				return err
+			}
+		}
+
+	case []interface{}:
+		if schema.Items != nil {
+			for i, item := range typed {
+				if err := v.validateValue(schema.Items, schemaPath+"/items", fmt.Sprintf("%s[%d]", location, i), item); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return checkConstraints(schema, schemaPath, location, value)
+}
+
+// matchesType reports whether a decoded JSON value has the given schema type
+func matchesType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	default:
+		return true
+	}
+}
+
+// checkConstraints checks the enum, length and range constraints of schema
+func checkConstraints(schema *openAPISchema, schemaPath, location string, value interface{}) error {
+	if len(schema.Enum) > 0 {
+		allowed := false
+		for _, candidate := range schema.Enum {
+			if fmt.Sprint(candidate) == fmt.Sprint(value) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return &schemaViolation{location: location, schemaPath: schemaPath + "/enum", message: fmt.Sprintf("must be one of %v", schema.Enum)}
+		}
+	}
+
+	switch typed := value.(type) {
+	case string:
+		length := len([]rune(typed))
+		if schema.MinLength != nil && length < *schema.MinLength {
+			return &schemaViolation{location: location, schemaPath: schemaPath + "/minLength", message: fmt.Sprintf("must be at least %d characters", *schema.MinLength)}
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			return &schemaViolation{location: location, schemaPath: schemaPath + "/maxLength", message: fmt.Sprintf("must be at most %d characters", *schema.MaxLength)}
+		}
+
+	case float64:
+		if schema.Minimum != nil && typed < *schema.Minimum {
+			return &schemaViolation{location: location, schemaPath: schemaPath + "/minimum", message: fmt.Sprintf("must be at least %v", *schema.Minimum)}
+		}
+		if schema.Maximum != nil && typed > *schema.Maximum {
+			return &schemaViolation{location: location, schemaPath: schemaPath + "/maximum", message: fmt.Sprintf("must be at most %v", *schema.Maximum)}
+		}
+	}
+
+	return nil
+}
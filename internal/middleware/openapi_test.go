@@ -0,0 +1,117 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dBiTech/go-apiTemplate/docs"
+	appmiddleware "github.com/dBiTech/go-apiTemplate/internal/middleware"
+)
+
+func TestOpenAPIValidator(t *testing.T) {
+	validator, err := appmiddleware.OpenAPIValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	require.NoError(t, err)
+
+	var received string
+	handler := validator(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// serve sends a request through the validator and decodes any error body
+	serve := func(method, target, body string) (int, map[string]interface{}) {
+		received = ""
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		var resp map[string]interface{}
+		if w.Code != http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w.Code, resp
+	}
+
+	// Test a body breaking the example schema is rejected with the schema path
+	t.Run("InvalidBody", func(t *testing.T) {
+		code, resp := serve(http.MethodPost, "/api/v1/examples", `{"name":"ab"}`)
+
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, "Request validation failed", resp["message"])
+		assert.Contains(t, resp["error"], "body.name")
+		assert.Contains(t, resp["error"], "#/definitions/models.ExampleRequest/properties/name/minLength")
+		assert.Empty(t, received)
+	})
+
+	// Test a missing required property is rejected
+	t.Run("MissingRequired", func(t *testing.T) {
+		code, resp := serve(http.MethodPut, "/api/v1/examples/123", `{"description":"no name"}`)
+
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Contains(t, resp["error"], "#/definitions/models.ExampleRequest/required")
+	})
+
+	// Test a property of the wrong type is rejected
+	t.Run("WrongType", func(t *testing.T) {
+		code, resp := serve(http.MethodPost, "/api/v1/examples", `{"name":123}`)
+
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Contains(t, resp["error"], "must be of type string")
+	})
+
+	// Test a valid body reaches the handler intact
+	t.Run("ValidBody", func(t *testing.T) {
+		code, _ := serve(http.MethodPost, "/api/v1/examples", `{"name":"valid name"}`)
+
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, `{"name":"valid name"}`, received)
+	})
+
+	// Test query parameters are checked against their declared type
+	t.Run("InvalidQuery", func(t *testing.T) {
+		code, resp := serve(http.MethodGet, "/api/v1/examples?limit=ten", "")
+
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Contains(t, resp["error"], "query.limit")
+	})
+
+	// Test items of a bulk body are checked against the example schema
+	t.Run("InvalidBulkItem", func(t *testing.T) {
+		code, resp := serve(http.MethodPost, "/api/v1/examples/bulk", `[{"name":"valid name"},{"name":"ab"}]`)
+
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Contains(t, resp["error"], "body[1].name")
+		assert.Contains(t, resp["error"], "#/definitions/models.ExampleRequest/properties/name/minLength")
+		assert.Empty(t, received)
+	})
+
+	// Test a static route is matched ahead of a templated sibling
+	t.Run("SearchRequiresQuery", func(t *testing.T) {
+		code, resp := serve(http.MethodGet, "/api/v1/examples/search", "")
+
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Contains(t, resp["error"], "query.q")
+	})
+
+	// Test routes missing from the spec pass through
+	t.Run("UnknownRoute", func(t *testing.T) {
+		code, _ := serve(http.MethodPost, "/api/v1/unknown", `{"name":1}`)
+
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	// Test an unparsable spec is reported
+	t.Run("InvalidSpec", func(t *testing.T) {
+		_, err := appmiddleware.OpenAPIValidator([]byte("not json"))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// RealIP sets the request's RemoteAddr to the client IP. Forwarded headers
+// are only honored when the immediate peer is one of the trusted proxies,
+// given as CIDRs or single IPs; otherwise the peer address is used as is, so
+// clients can't spoof their IP by sending the headers themselves.
+//
+// X-Forwarded-For is read right to left, skipping trusted proxies, so the
+// first untrusted hop is taken as the client. X-Real-IP is used when
+// X-Forwarded-For is absent. Requests from a trusted proxy are marked so
+// FromTrustedProxy reports true for them.
+func RealIP(trustedProxies []string) (func(next http.Handler) http.Handler, error) {
+	trusted := make([]*net.IPNet, 0, len(trustedProxies))
+	for _, proxy := range trustedProxies {
+		network, err := parseNetwork(proxy)
+		if err != nil {
+			return nil, err
+		}
+		trusted = append(trusted, network)
+	}
+
+	// isTrusted reports whether ip belongs to a trusted proxy
+	isTrusted := func(ip net.IP) bool {
+		for _, network := range trusted {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			peer := remoteIP(r.RemoteAddr)
+			if peer == nil || !isTrusted(peer) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if ip := forwardedIP(r, isTrusted); ip != nil {
+				r.RemoteAddr = ip.String()
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), TrustedProxyKey, true)))
+		})
+	}, nil
+}
+
+// TrustedProxyKey is the context key marking requests whose immediate peer is
+// a trusted proxy
+const TrustedProxyKey ContextKey = "trusted_proxy"
+
+// FromTrustedProxy reports whether RealIP found the request's immediate peer
+// to be a trusted proxy, so its forwarded headers can be believed
+func FromTrustedProxy(ctx context.Context) bool {
+	trusted, _ := ctx.Value(TrustedProxyKey).(bool)
+	return trusted
+}
+
+// parseNetwork parses a CIDR, or a single IP as a network of one address
+func parseNetwork(s string) (*net.IPNet, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "/") {
+		_, network, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", s, err)
+		}
+		return network, nil
+	}
+
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid trusted proxy %q: not an IP or CIDR", s)
+	}
+	bits := 8 * net.IPv6len
+	if v4 := ip.To4(); v4 != nil {
+		ip, bits = v4, 8*net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// remoteIP parses the IP from a RemoteAddr, which may or may not have a port
+func remoteIP(addr string) net.IP {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return net.ParseIP(addr)
+}
+
+// forwardedIP returns the client IP from the forwarded headers set by a
+// trusted proxy, or nil if they don't name a valid one
+func forwardedIP(r *http.Request, isTrusted func(net.IP) bool) net.IP {
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+
+		var ip net.IP
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip = net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				return nil
+			}
+			if !isTrusted(ip) {
+				return ip
+			}
+		}
+		// Every hop is a trusted proxy, so the leftmost is the closest
+		// thing to a client
+		return ip
+	}
+
+	return net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP")))
+}
@@ -0,0 +1,73 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appmiddleware "github.com/dBiTech/go-apiTemplate/internal/middleware"
+)
+
+func TestRealIP(t *testing.T) {
+	realIP, err := appmiddleware.RealIP([]string{"10.0.0.0/8", "192.168.1.1"})
+	require.NoError(t, err)
+
+	// resolve sends a request from remoteAddr with the given headers and
+	// returns the RemoteAddr the handler saw
+	resolve := func(remoteAddr string, headers map[string]string) string {
+		var got string
+		handler := realIP(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			got = r.RemoteAddr
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		return got
+	}
+
+	// Test forwarded headers from a trusted proxy are honored
+	t.Run("TrustedPeer", func(t *testing.T) {
+		got := resolve("10.1.2.3:4000", map[string]string{"X-Forwarded-For": "203.0.113.7"})
+		assert.Equal(t, "203.0.113.7", got)
+	})
+
+	// Test a single trusted IP is honored
+	t.Run("TrustedSingleIP", func(t *testing.T) {
+		got := resolve("192.168.1.1:4000", map[string]string{"X-Real-IP": "203.0.113.8"})
+		assert.Equal(t, "203.0.113.8", got)
+	})
+
+	// Test forwarded headers from an untrusted peer are ignored
+	t.Run("UntrustedPeer", func(t *testing.T) {
+		got := resolve("198.51.100.9:4000", map[string]string{
+			"X-Forwarded-For": "203.0.113.7",
+			"X-Real-IP":       "203.0.113.7",
+		})
+		assert.Equal(t, "198.51.100.9:4000", got)
+	})
+
+	// Test a spoofed leftmost hop is skipped in favour of the first untrusted one
+	t.Run("SpoofedChain", func(t *testing.T) {
+		got := resolve("10.1.2.3:4000", map[string]string{"X-Forwarded-For": "1.2.3.4, 203.0.113.7, 10.9.9.9"})
+		assert.Equal(t, "203.0.113.7", got)
+	})
+
+	// Test a malformed header leaves the peer address in place
+	t.Run("Malformed", func(t *testing.T) {
+		got := resolve("10.1.2.3:4000", map[string]string{"X-Forwarded-For": "not-an-ip"})
+		assert.Equal(t, "10.1.2.3:4000", got)
+	})
+
+	// Test an invalid trusted proxy is rejected
+	t.Run("InvalidProxy", func(t *testing.T) {
+		_, err := appmiddleware.RealIP([]string{"10.0.0.0/33"})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,190 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServerTimingHeader is the response header carrying the timing breakdown
+const ServerTimingHeader = "Server-Timing"
+
+// ServerTimingKey is the context key for the request's phase timings
+const ServerTimingKey ContextKey = "server_timing"
+
+// ServerTiming sends a Server-Timing header with the durations of the phases
+// timed with StartTiming, TimePhase and TimeMiddleware, and the total time
+// until the response status was written. Phases still running then, such as
+// the handler writing its body, are measured up to that point.
+func ServerTiming() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timings := &serverTimings{start: time.Now()}
+			ctx := context.WithValue(r.Context(), ServerTimingKey, timings)
+
+			next.ServeHTTP(&serverTimingResponseWriter{ResponseWriter: w, timings: timings}, r.WithContext(ctx))
+		})
+	}
+}
+
+// StartTiming starts timing the named phase of the request and returns the
+// function that stops it. It does nothing when ServerTiming isn't in use.
+func StartTiming(ctx context.Context, name string) func() {
+	timings, ok := ctx.Value(ServerTimingKey).(*serverTimings)
+	if !ok {
+		return func() {}
+	}
+	return timings.begin(name)
+}
+
+// TimePhase times the rest of the chain as the named phase, excluding the
+// phases timed within it
+func TimePhase(name string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			stop := StartTiming(r.Context(), name)
+			defer stop()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// TimeMiddleware times mw as the named phase, up to when it passes the
+// request on or, if it responds itself, until it returns
+func TimeMiddleware(name string, mw func(http.Handler) http.Handler) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		// stopKey carries the phase's stop function from outside mw to inside it
+		type stopKey struct{}
+
+		inner := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if stop, ok := r.Context().Value(stopKey{}).(func()); ok {
+				stop()
+			}
+			next.ServeHTTP(w, r)
+		}))
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			stop := StartTiming(r.Context(), name)
+			defer stop()
+
+			inner.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), stopKey{}, stop)))
+		})
+	}
+}
+
+// phaseTiming is one timed phase of a request
+type phaseTiming struct {
+	name       string
+	start, end time.Time
+}
+
+// serverTimings collects the phase timings of a request
+type serverTimings struct {
+	mu     sync.Mutex
+	start  time.Time
+	phases []*phaseTiming
+}
+
+// begin starts a phase and returns the function that ends it. Only the first
+// call of the returned function counts.
+func (t *serverTimings) begin(name string) func() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	phase := &phaseTiming{name: name, start: time.Now()}
+	t.phases = append(t.phases, phase)
+
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		if phase.end.IsZero() {
+			phase.end = time.Now()
+		}
+	}
+}
+
+// header formats the timings as of now. Each phase's duration excludes the
+// phases that started within it.
+func (t *serverTimings) header(now time.Time) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// end returns when a phase ended, or now if it is still running
+	end := func(p *phaseTiming) time.Time {
+		if p.end.IsZero() {
+			return now
+		}
+		return p.end
+	}
+
+	metrics := make([]string, 0, len(t.phases)+1)
+	for i, p := range t.phases {
+		pEnd := end(p)
+		d := pEnd.Sub(p.start)
+		for _, q := range t.phases[i+1:] {
+			qEnd := end(q)
+			if qEnd.After(pEnd) {
+				qEnd = pEnd
+			}
+			if qEnd.After(q.start) {
+				d -= qEnd.Sub(q.start)
+			}
+		}
+		metrics = append(metrics, formatServerTiming(p.name, d))
+	}
+	metrics = append(metrics, formatServerTiming("total", now.Sub(t.start)))
+
+	return strings.Join(metrics, ", ")
+}
+
+// formatServerTiming formats a Server-Timing metric with its duration in milliseconds
+func formatServerTiming(name string, d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	return name + ";dur=" + strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
+
+// serverTimingResponseWriter sets Server-Timing when the status is written
+type serverTimingResponseWriter struct {
+	http.ResponseWriter
+	timings     *serverTimings
+	wroteHeader bool
+}
+
+// WriteHeader sets Server-Timing before writing the status
+func (sw *serverTimingResponseWriter) WriteHeader(statusCode int) {
+	if !sw.wroteHeader {
+		sw.wroteHeader = true
+		sw.Header().Set(ServerTimingHeader, sw.timings.header(time.Now()))
+	}
+	sw.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write writes an implicit 200 status before the body
+func (sw *serverTimingResponseWriter) Write(b []byte) (int, error) {
+	if !sw.wroteHeader {
+		sw.WriteHeader(http.StatusOK)
+	}
+	return sw.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher if the underlying ResponseWriter supports it
+func (sw *serverTimingResponseWriter) Flush() {
+	if !sw.wroteHeader {
+		sw.WriteHeader(http.StatusOK)
+	}
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (sw *serverTimingResponseWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
@@ -0,0 +1,98 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appmiddleware "github.com/dBiTech/go-apiTemplate/internal/middleware"
+)
+
+func TestServerTiming(t *testing.T) {
+	const handlerDelay = 5 * time.Millisecond
+
+	passthrough := func(next http.Handler) http.Handler { return next }
+	reject := func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+	}
+	handler := func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(handlerDelay)
+		_, _ = w.Write([]byte("ok"))
+	}
+
+	// newRouter builds a router with the timed phases, with or without ServerTiming
+	newRouter := func(enabled bool) *chi.Mux {
+		router := chi.NewRouter()
+		if enabled {
+			router.Use(appmiddleware.ServerTiming())
+		}
+		router.Use(appmiddleware.TimePhase("handler"))
+		router.With(appmiddleware.TimeMiddleware("auth", passthrough)).Get("/ok", handler)
+		router.With(appmiddleware.TimeMiddleware("auth", reject)).Get("/rejected", handler)
+		return router
+	}
+
+	// durations parses a Server-Timing header into milliseconds by metric name
+	durations := func(t *testing.T, header string) map[string]float64 {
+		t.Helper()
+
+		matches := regexp.MustCompile(`(\w+);dur=([0-9.]+)`).FindAllStringSubmatch(header, -1)
+		result := make(map[string]float64, len(matches))
+		for _, m := range matches {
+			d, err := strconv.ParseFloat(m[2], 64)
+			require.NoError(t, err)
+			result[m[1]] = d
+		}
+		return result
+	}
+
+	// Test the header has each phase and a plausible total
+	t.Run("Enabled", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		newRouter(true).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ok", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		header := w.Header().Get(appmiddleware.ServerTimingHeader)
+		require.NotEmpty(t, header)
+
+		d := durations(t, header)
+		require.Contains(t, d, "total")
+		require.Contains(t, d, "handler")
+		require.Contains(t, d, "auth")
+
+		minMillis := float64(handlerDelay) / float64(time.Millisecond)
+		assert.GreaterOrEqual(t, d["total"], minMillis)
+		assert.Less(t, d["total"], 1000.0)
+		assert.GreaterOrEqual(t, d["handler"], minMillis)
+		// Allow for each value being rounded to the microsecond
+		assert.LessOrEqual(t, d["handler"]+d["auth"], d["total"]+0.002)
+	})
+
+	// Test a rejecting middleware's time is still reported
+	t.Run("Rejected", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		newRouter(true).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/rejected", nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		d := durations(t, w.Header().Get(appmiddleware.ServerTimingHeader))
+		assert.Contains(t, d, "auth")
+		assert.Contains(t, d, "total")
+	})
+
+	// Test no header is sent without ServerTiming
+	t.Run("Disabled", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		newRouter(false).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ok", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get(appmiddleware.ServerTimingHeader))
+	})
+}
@@ -3,22 +3,40 @@
 package models
 
 import (
+	"encoding/xml"
+	"slices"
 	"time"
 )
 
 // BaseModel represents common fields for all models
 type BaseModel struct {
-	ID        string    `json:"id"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID        string    `json:"id" xml:"id"`
+	CreatedAt time.Time `json:"createdAt" xml:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt" xml:"updatedAt"`
+}
+
+// Example statuses
+const (
+	StatusActive   = "active"
+	StatusInactive = "inactive"
+	StatusArchived = "archived"
+)
+
+// ExampleStatuses lists the valid example statuses
+var ExampleStatuses = []string{StatusActive, StatusInactive, StatusArchived}
+
+// ValidExampleStatus reports whether status is one of ExampleStatuses
+func ValidExampleStatus(status string) bool {
+	return slices.Contains(ExampleStatuses, status)
 }
 
 // Example is an example model
 type Example struct {
+	XMLName xml.Name `json:"-" xml:"example"`
 	BaseModel
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Status      string `json:"status"`
+	Name        string `json:"name" xml:"name"`
+	Description string `json:"description" xml:"description"`
+	Status      string `json:"status" xml:"status"`
 }
 
 // NewExample creates a new example model
@@ -32,32 +50,86 @@ func NewExample(id, name, description string) *Example {
 		},
 		Name:        name,
 		Description: description,
-		Status:      "active",
+		Status:      StatusActive,
 	}
 }
 
-// ExampleRequest represents a request to create or update an example
+// ExampleRequest represents a request to create or update an example. An
+// omitted status is active on create and unchanged on update.
 type ExampleRequest struct {
-	Name        string `json:"name" validate:"required,min=3,max=100"`
-	Description string `json:"description" validate:"max=500"`
+	Name        string `json:"name" xml:"name" validate:"required,min=3,max=100"`
+	Description string `json:"description" xml:"description" validate:"max=500"`
+	Status      string `json:"status,omitempty" xml:"status,omitempty" validate:"omitempty,oneof=active inactive archived"`
+}
+
+// BatchGetRequest represents a request to get many examples by ID
+type BatchGetRequest struct {
+	IDs []string `json:"ids" xml:"ids>id"`
+}
+
+// BatchGetResponse holds the examples found by a batch get, in request order,
+// and the requested IDs that don't exist
+type BatchGetResponse struct {
+	XMLName  xml.Name   `json:"-" xml:"batchGet"`
+	Examples []*Example `json:"examples" xml:"examples>example"`
+	Missing  []string   `json:"missing" xml:"missing>id"`
+}
+
+// ArchiveFilter selects the examples to archive by status, creation time or
+// both. Examples that are already archived never match.
+type ArchiveFilter struct {
+	Status    string    `json:"status,omitempty" xml:"status,omitempty" validate:"omitempty,oneof=active inactive"`
+	OlderThan time.Time `json:"olderThan" xml:"olderThan"` // Only examples created before this time
+}
+
+// Matches reports whether the example is selected by the filter
+func (f ArchiveFilter) Matches(example *Example) bool {
+	if example.Status == StatusArchived {
+		return false
+	}
+	if f.Status != "" && example.Status != f.Status {
+		return false
+	}
+	if !f.OlderThan.IsZero() && !example.CreatedAt.Before(f.OlderThan) {
+		return false
+	}
+	return true
+}
+
+// ArchiveResponse reports how many examples an archive request archived
+type ArchiveResponse struct {
+	XMLName  xml.Name `json:"-" xml:"archive"`
+	Archived int      `json:"archived" xml:"archived"`
+}
+
+// BulkCreateItemResult represents the outcome of one item in a bulk create request
+type BulkCreateItemResult struct {
+	XMLName xml.Name `json:"-" xml:"result"`
+	Index   int      `json:"index" xml:"index"`
+	Status  int      `json:"status" xml:"status"`
+	Example *Example `json:"example,omitempty" xml:"example,omitempty"`
+	Error   string   `json:"error,omitempty" xml:"error,omitempty"`
 }
 
 // ProtectedResource represents a resource that requires authentication
 type ProtectedResource struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"createdAt"`
-	OwnerID   string    `json:"ownerId"`
+	XMLName   xml.Name  `json:"-" xml:"protectedResource"`
+	ID        string    `json:"id" xml:"id"`
+	Name      string    `json:"name" xml:"name"`
+	Content   string    `json:"content" xml:"content"`
+	CreatedAt time.Time `json:"createdAt" xml:"createdAt"`
+	OwnerID   string    `json:"ownerId" xml:"ownerId"`
+	Scope     string    `json:"scope" xml:"scope"` // Scope a caller needs to see the resource
 }
 
 // UserProfile represents a user profile
 type UserProfile struct {
-	ID       string   `json:"id"`
-	Username string   `json:"username"`
-	Email    string   `json:"email"`
-	Roles    []string `json:"roles"`
-	Scopes   []string `json:"scopes"`
+	XMLName  xml.Name `json:"-" xml:"userProfile"`
+	ID       string   `json:"id" xml:"id"`
+	Username string   `json:"username" xml:"username"`
+	Email    string   `json:"email" xml:"email"`
+	Roles    []string `json:"roles" xml:"roles>role"`
+	Scopes   []string `json:"scopes" xml:"scopes>scope"`
 }
 
 // ExampleResponse represents an example response
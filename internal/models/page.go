@@ -0,0 +1,40 @@
+package models
+
+import "encoding/xml"
+
+// Page is the envelope list endpoints respond with. Offset pages carry the
+// offset used, cursor pages carry the cursor of the next page instead.
+type Page[T any] struct {
+	XMLName xml.Name `json:"-" xml:"page"`
+
+	// Data holds the items of the page. Items are marshaled to XML under
+	// their own element name.
+	Data []T `json:"data"`
+
+	// Limit is the effective page size after clamping
+	Limit int `json:"limit" xml:"limit"`
+
+	// Offset is the number of items skipped before this page
+	Offset int `json:"offset" xml:"offset"`
+
+	// Total is the number of items across all pages, when known
+	Total int `json:"total,omitempty" xml:"total,omitempty"`
+
+	// NextCursor fetches the following page, empty on the last page or when
+	// paging by offset
+	NextCursor string `json:"next_cursor,omitempty" xml:"nextCursor,omitempty"`
+}
+
+// NewPage creates a page of data fetched with limit and offset. A nil slice
+// is replaced with an empty one so the page always marshals a data array.
+func NewPage[T any](data []T, limit, offset int) *Page[T] {
+	if data == nil {
+		data = []T{}
+	}
+
+	return &Page[T]{
+		Data:   data,
+		Limit:  limit,
+		Offset: offset,
+	}
+}
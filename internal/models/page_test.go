@@ -0,0 +1,70 @@
+package models_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dBiTech/go-apiTemplate/internal/models"
+)
+
+func TestPage(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	example := &models.Example{
+		BaseModel:   models.BaseModel{ID: "example-1", CreatedAt: created, UpdatedAt: created},
+		Name:        "Example",
+		Description: "An example",
+		Status:      "active",
+	}
+
+	// Test an offset page marshals the envelope around its items
+	t.Run("JSON", func(t *testing.T) {
+		page := models.NewPage([]*models.Example{example}, 10, 20)
+		page.Total = 21
+
+		body, err := json.Marshal(page)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"data": [{
+				"id": "example-1",
+				"createdAt": "2024-01-02T03:04:05Z",
+				"updatedAt": "2024-01-02T03:04:05Z",
+				"name": "Example",
+				"description": "An example",
+				"status": "active"
+			}],
+			"limit": 10,
+			"offset": 20,
+			"total": 21
+		}`, string(body))
+	})
+
+	// Test a cursor page carries the next cursor
+	t.Run("Cursor", func(t *testing.T) {
+		page := models.NewPage([]*models.Example{}, 5, 0)
+		page.NextCursor = "abc"
+
+		body, err := json.Marshal(page)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"data":[],"limit":5,"offset":0,"next_cursor":"abc"}`, string(body))
+	})
+
+	// Test a nil slice still marshals as an empty array
+	t.Run("NilData", func(t *testing.T) {
+		body, err := json.Marshal(models.NewPage[*models.Example](nil, 10, 0))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"data":[],"limit":10,"offset":0}`, string(body))
+	})
+
+	// Test items keep their element name in XML
+	t.Run("XML", func(t *testing.T) {
+		body, err := xml.Marshal(models.NewPage([]*models.Example{example}, 10, 0))
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "<page><example><id>example-1</id>")
+		assert.Contains(t, string(body), "<limit>10</limit>")
+	})
+}
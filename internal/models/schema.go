@@ -0,0 +1,197 @@
+package models
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONSchemaDialect is the JSON Schema draft generated schemas declare
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is the subset of a JSON Schema document generated from the models
+type JSONSchema struct {
+	Schema     string                 `json:"$schema,omitempty"`
+	Title      string                 `json:"title,omitempty"`
+	Type       string                 `json:"type,omitempty"`
+	Format     string                 `json:"format,omitempty"`
+	Properties map[string]*JSONSchema `json:"properties,omitempty"`
+	Required   []string               `json:"required,omitempty"`
+	Items      *JSONSchema            `json:"items,omitempty"`
+	MinLength  *int                   `json:"minLength,omitempty"`
+	MaxLength  *int                   `json:"maxLength,omitempty"`
+	Minimum    *float64               `json:"minimum,omitempty"`
+	Maximum    *float64               `json:"maximum,omitempty"`
+	MinItems   *int                   `json:"minItems,omitempty"`
+	MaxItems   *int                   `json:"maxItems,omitempty"`
+	Enum       []string               `json:"enum,omitempty"`
+}
+
+// schemaModels maps the lower-cased names served by the schema endpoint to
+// the models they describe
+var schemaModels = map[string]interface{}{
+	"example":           Example{},
+	"examplerequest":    ExampleRequest{},
+	"protectedresource": ProtectedResource{},
+	"userprofile":       UserProfile{},
+}
+
+// SchemaFor returns the JSON Schema of the named model. Names are matched
+// case-insensitively, so "ExampleRequest" and "examplerequest" are the same.
+func SchemaFor(name string) (*JSONSchema, bool) {
+	model, ok := schemaModels[strings.ToLower(name)]
+	if !ok {
+		return nil, false
+	}
+	return GenerateSchema(model), true
+}
+
+// SchemaNames returns the names of the models SchemaFor knows, sorted
+func SchemaNames() []string {
+	names := make([]string, 0, len(schemaModels))
+	for _, model := range schemaModels {
+		names = append(names, reflect.TypeOf(model).Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GenerateSchema reflects over a struct and returns its JSON Schema. Property
+// names follow the json tags, and required, min, max and oneof validate tags
+// become the matching schema constraints.
+func GenerateSchema(v interface{}) *JSONSchema {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	schema := schemaForType(t)
+	schema.Schema = JSONSchemaDialect
+	schema.Title = t.Name()
+	return schema
+}
+
+// timeType is handled separately so timestamps are strings, not objects
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaForType returns the schema of a Go type
+func schemaForType(t reflect.Type) *JSONSchema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return &JSONSchema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &JSONSchema{Type: "array", Items: schemaForType(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object"}
+	case reflect.Struct:
+		schema := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}}
+		addProperties(schema, t)
+		return schema
+	default:
+		return &JSONSchema{}
+	}
+}
+
+// addProperties adds the exported fields of struct type t to schema, flattening
+// embedded structs the way encoding/json does
+func addProperties(schema *JSONSchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				addProperties(schema, embedded)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		property := schemaForType(field.Type)
+		if applyValidateTag(property, field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+}
+
+// applyValidateTag adds the constraints of a validate tag to schema and
+// reports whether the tag marks the field as required. Rules that have no
+// schema equivalent are ignored.
+func applyValidateTag(schema *JSONSchema, tag string) bool {
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch key {
+		case "required":
+			required = true
+		case "min", "max":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				continue
+			}
+			setBound(schema, key == "min", n)
+		case "oneof":
+			schema.Enum = strings.Fields(value)
+		}
+	}
+	return required
+}
+
+// setBound sets the lower or upper bound matching the schema's type: length
+// for strings, item count for arrays and value for numbers
+func setBound(schema *JSONSchema, lower bool, n int) {
+	switch schema.Type {
+	case "string":
+		if lower {
+			schema.MinLength = &n
+		} else {
+			schema.MaxLength = &n
+		}
+	case "array":
+		if lower {
+			schema.MinItems = &n
+		} else {
+			schema.MaxItems = &n
+		}
+	case "integer", "number":
+		f := float64(n)
+		if lower {
+			schema.Minimum = &f
+		} else {
+			schema.Maximum = &f
+		}
+	}
+}
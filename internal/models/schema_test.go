@@ -0,0 +1,79 @@
+package models_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dBiTech/go-apiTemplate/internal/models"
+)
+
+func TestGenerateSchema(t *testing.T) {
+	// Test validate tags become required and length constraints
+	t.Run("ExampleRequest", func(t *testing.T) {
+		schema, ok := models.SchemaFor("ExampleRequest")
+		require.True(t, ok)
+
+		body, err := json.Marshal(schema)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"title": "ExampleRequest",
+			"type": "object",
+			"properties": {
+				"name": {"type": "string", "minLength": 3, "maxLength": 100},
+				"description": {"type": "string", "maxLength": 500},
+				"status": {"type": "string", "enum": ["active", "inactive", "archived"]}
+			},
+			"required": ["name"]
+		}`, string(body))
+	})
+
+	// Test embedded fields are flattened and timestamps are date-time strings
+	t.Run("Example", func(t *testing.T) {
+		schema, ok := models.SchemaFor("example")
+		require.True(t, ok)
+
+		assert.Equal(t, "Example", schema.Title)
+		assert.ElementsMatch(t,
+			[]string{"id", "createdAt", "updatedAt", "name", "description", "status"},
+			keys(schema.Properties))
+		assert.Equal(t, "date-time", schema.Properties["createdAt"].Format)
+		assert.Empty(t, schema.Required)
+	})
+
+	// Test slices become arrays of their element type
+	t.Run("UserProfile", func(t *testing.T) {
+		schema, ok := models.SchemaFor("userprofile")
+		require.True(t, ok)
+
+		roles := schema.Properties["roles"]
+		require.NotNil(t, roles)
+		assert.Equal(t, "array", roles.Type)
+		assert.Equal(t, "string", roles.Items.Type)
+	})
+
+	// Test unknown models aren't found
+	t.Run("Unknown", func(t *testing.T) {
+		_, ok := models.SchemaFor("Nope")
+		assert.False(t, ok)
+	})
+
+	// Test the served model names
+	t.Run("Names", func(t *testing.T) {
+		assert.Equal(t,
+			[]string{"Example", "ExampleRequest", "ProtectedResource", "UserProfile"},
+			models.SchemaNames())
+	})
+}
+
+// keys returns the keys of a property map
+func keys(m map[string]*models.JSONSchema) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/dBiTech/go-apiTemplate/internal/models"
+	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+	"github.com/dBiTech/go-apiTemplate/pkg/metrics"
+)
+
+// CachingRepository wraps a Repository and caches GetExample results in an
+// in-process LRU with a TTL. Updates and deletes invalidate the cached entry.
+// All other methods pass through to the wrapped repository.
+type CachingRepository struct {
+	Repository
+	log  logger.Logger
+	size int
+	ttl  time.Duration
+
+	mu         sync.Mutex
+	order      *list.List
+	entries    map[string]*list.Element
+	generation uint64
+
+	hits   *prometheus.CounterVec
+	misses *prometheus.CounterVec
+}
+
+// cacheEntry is a cached example and its expiry
+type cacheEntry struct {
+	id        string
+	example   models.Example
+	expiresAt time.Time
+}
+
+// CacheOption configures optional dependencies of a CachingRepository
+type CacheOption func(*CachingRepository)
+
+// WithCacheMetrics registers the cache's hit and miss counters against m
+func WithCacheMetrics(m *metrics.Metrics) CacheOption {
+	return func(r *CachingRepository) {
+		r.hits = m.NewCounter("repository_cache_hits_total", "Total number of example cache hits.", nil)
+		r.misses = m.NewCounter("repository_cache_misses_total", "Total number of example cache misses.", nil)
+	}
+}
+
+// NewCachingRepository creates a caching repository that holds at most size
+// examples, each for at most ttl
+func NewCachingRepository(repo Repository, log logger.Logger, size int, ttl time.Duration, opts ...CacheOption) *CachingRepository {
+	r := &CachingRepository{
+		Repository: repo,
+		log:        log,
+		size:       size,
+		ttl:        ttl,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// GetExample gets an example by ID, serving it from the cache when possible
+func (r *CachingRepository) GetExample(ctx context.Context, id string) (*models.Example, error) {
+	r.mu.Lock()
+	if elem, ok := r.entries[id]; ok {
+		entry := elem.Value.(*cacheEntry)
+		if time.Now().Before(entry.expiresAt) {
+			r.order.MoveToFront(elem)
+			example := entry.example
+			r.mu.Unlock()
+
+			r.log.Debug("example cache hit", logger.String("id", id))
+			inc(r.hits)
+			return &example, nil
+		}
+		r.remove(elem)
+	}
+	generation := r.generation
+	r.mu.Unlock()
+
+	r.log.Debug("example cache miss", logger.String("id", id))
+	inc(r.misses)
+
+	example, err := r.Repository.GetExample(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	// Skip caching if the example may have changed while it was being loaded
+	if r.generation == generation {
+		r.add(id, example)
+	}
+	r.mu.Unlock()
+
+	// Hand out a copy so callers can't modify the cached example
+	result := *example
+	return &result, nil
+}
+
+// UpdateExample updates an example and invalidates its cache entry
+func (r *CachingRepository) UpdateExample(ctx context.Context, example *models.Example) error {
+	defer r.invalidate(example.ID)
+	return r.Repository.UpdateExample(ctx, example)
+}
+
+// UpdateExampleIfUnmodified conditionally updates an example and invalidates
+// its cache entry
+func (r *CachingRepository) UpdateExampleIfUnmodified(ctx context.Context, example *models.Example, version time.Time) error {
+	defer r.invalidate(example.ID)
+	return r.Repository.UpdateExampleIfUnmodified(ctx, example, version)
+}
+
+// UpsertExample creates or replaces an example and invalidates its cache entry
+func (r *CachingRepository) UpsertExample(ctx context.Context, example *models.Example) (bool, error) {
+	defer r.invalidate(example.ID)
+	return r.Repository.UpsertExample(ctx, example)
+}
+
+// DeleteExample deletes an example and invalidates its cache entry
+func (r *CachingRepository) DeleteExample(ctx context.Context, id string) error {
+	defer r.invalidate(id)
+	return r.Repository.DeleteExample(ctx, id)
+}
+
+// ArchiveExamples archives examples and empties the cache, since any cached
+// example may have been archived
+func (r *CachingRepository) ArchiveExamples(ctx context.Context, filter models.ArchiveFilter) ([]string, error) {
+	defer r.invalidateAll()
+	return r.Repository.ArchiveExamples(ctx, filter)
+}
+
+// Reset resets the wrapped repository, if it supports it, and empties the cache
+func (r *CachingRepository) Reset(ctx context.Context) error {
+	resettable, ok := r.Repository.(Resettable)
+	if !ok {
+		return ErrNotResettable
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.generation++
+	r.order.Init()
+	r.entries = make(map[string]*list.Element)
+
+	return resettable.Reset(ctx)
+}
+
+// invalidateAll drops every cache entry
+func (r *CachingRepository) invalidateAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.generation++
+	r.order.Init()
+	r.entries = make(map[string]*list.Element)
+}
+
+// invalidate drops the cache entry for id
+func (r *CachingRepository) invalidate(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.generation++
+	if elem, ok := r.entries[id]; ok {
+		r.remove(elem)
+	}
+}
+
+// add caches a copy of the example, evicting the least recently used entry
+// if the cache is full. The caller must hold r.mu.
+func (r *CachingRepository) add(id string, example *models.Example) {
+	if r.size <= 0 {
+		return
+	}
+
+	if elem, ok := r.entries[id]; ok {
+		r.remove(elem)
+	}
+
+	for r.order.Len() >= r.size {
+		r.remove(r.order.Back())
+	}
+
+	r.entries[id] = r.order.PushFront(&cacheEntry{
+		id:        id,
+		example:   *example,
+		expiresAt: time.Now().Add(r.ttl),
+	})
+}
+
+// remove drops a cache entry. The caller must hold r.mu.
+func (r *CachingRepository) remove(elem *list.Element) {
+	r.order.Remove(elem)
+	delete(r.entries, elem.Value.(*cacheEntry).id)
+}
+
+// inc increments an optional counter
+func inc(counter *prometheus.CounterVec) {
+	if counter != nil {
+		counter.WithLabelValues().Inc()
+	}
+}
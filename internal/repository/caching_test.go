@@ -0,0 +1,158 @@
+package repository_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dBiTech/go-apiTemplate/internal/models"
+	"github.com/dBiTech/go-apiTemplate/internal/repository"
+	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+	"github.com/dBiTech/go-apiTemplate/pkg/metrics"
+)
+
+// countingRepository counts GetExample calls that reach the wrapped repository
+type countingRepository struct {
+	repository.Repository
+	gets int
+}
+
+func (r *countingRepository) GetExample(ctx context.Context, id string) (*models.Example, error) {
+	r.gets++
+	return r.Repository.GetExample(ctx, id)
+}
+
+func TestCachingRepository(t *testing.T) {
+	log := logger.Default()
+	ctx := context.Background()
+
+	// newRepo creates a caching repository over a counting memory repository
+	// holding the given examples
+	newRepo := func(t *testing.T, size int, ttl time.Duration, m *metrics.Metrics, ids ...string) (*repository.CachingRepository, *countingRepository) {
+		backing := &countingRepository{Repository: repository.NewMemoryRepository(log)}
+		for _, id := range ids {
+			require.NoError(t, backing.CreateExample(ctx, models.NewExample(id, "Example "+id, "")))
+		}
+
+		var opts []repository.CacheOption
+		if m != nil {
+			opts = append(opts, repository.WithCacheMetrics(m))
+		}
+
+		return repository.NewCachingRepository(backing, log, size, ttl, opts...), backing
+	}
+
+	// Test a second Get is served from the cache
+	t.Run("CacheHit", func(t *testing.T) {
+		m := metrics.NewMetrics("test", metrics.WithGoCollector(false), metrics.WithProcessCollector(false))
+		repo, backing := newRepo(t, 10, time.Minute, m, "a")
+
+		first, err := repo.GetExample(ctx, "a")
+		require.NoError(t, err)
+		second, err := repo.GetExample(ctx, "a")
+		require.NoError(t, err)
+
+		assert.Equal(t, first.ID, second.ID)
+		assert.Equal(t, 1, backing.gets)
+
+		w := httptest.NewRecorder()
+		m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		assert.Contains(t, w.Body.String(), "test_repository_cache_hits_total 1")
+		assert.Contains(t, w.Body.String(), "test_repository_cache_misses_total 1")
+	})
+
+	// Test an update invalidates the cached entry
+	t.Run("UpdateInvalidates", func(t *testing.T) {
+		repo, backing := newRepo(t, 10, time.Minute, nil, "a")
+
+		example, err := repo.GetExample(ctx, "a")
+		require.NoError(t, err)
+
+		example.Name = "Updated"
+		require.NoError(t, repo.UpdateExample(ctx, example))
+
+		updated, err := repo.GetExample(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, "Updated", updated.Name)
+		assert.Equal(t, 2, backing.gets)
+	})
+
+	// Test a delete invalidates the cached entry
+	t.Run("DeleteInvalidates", func(t *testing.T) {
+		repo, _ := newRepo(t, 10, time.Minute, nil, "a")
+
+		_, err := repo.GetExample(ctx, "a")
+		require.NoError(t, err)
+		require.NoError(t, repo.DeleteExample(ctx, "a"))
+
+		_, err = repo.GetExample(ctx, "a")
+		assert.Equal(t, repository.ErrNotFound, err)
+	})
+
+	// Test archiving invalidates every cached entry
+	t.Run("ArchiveInvalidates", func(t *testing.T) {
+		repo, _ := newRepo(t, 10, time.Minute, nil, "a")
+
+		_, err := repo.GetExample(ctx, "a")
+		require.NoError(t, err)
+
+		ids, err := repo.ArchiveExamples(ctx, models.ArchiveFilter{Status: models.StatusActive})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a"}, ids)
+
+		example, err := repo.GetExample(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusArchived, example.Status)
+	})
+
+	// Test modifying a returned example doesn't change the cached copy
+	t.Run("ReturnsCopies", func(t *testing.T) {
+		repo, _ := newRepo(t, 10, time.Minute, nil, "a")
+
+		example, err := repo.GetExample(ctx, "a")
+		require.NoError(t, err)
+		example.Name = "Modified"
+
+		cached, err := repo.GetExample(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, "Example a", cached.Name)
+	})
+
+	// Test entries expire after the TTL
+	t.Run("Expiry", func(t *testing.T) {
+		repo, backing := newRepo(t, 10, 10*time.Millisecond, nil, "a")
+
+		_, err := repo.GetExample(ctx, "a")
+		require.NoError(t, err)
+		time.Sleep(20 * time.Millisecond)
+		_, err = repo.GetExample(ctx, "a")
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, backing.gets)
+	})
+
+	// Test the least recently used entry is evicted when full
+	t.Run("Eviction", func(t *testing.T) {
+		repo, backing := newRepo(t, 2, time.Minute, nil, "a", "b", "c")
+
+		for _, id := range []string{"a", "b", "a", "c"} {
+			_, err := repo.GetExample(ctx, id)
+			require.NoError(t, err)
+		}
+		require.Equal(t, 3, backing.gets)
+
+		// "a" was used more recently than "b", so "b" was evicted for "c"
+		_, err := repo.GetExample(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, 3, backing.gets)
+
+		_, err = repo.GetExample(ctx, "b")
+		require.NoError(t, err)
+		assert.Equal(t, 4, backing.gets)
+	})
+}
@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dBiTech/go-apiTemplate/internal/models"
+	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+)
+
+// BreakerState is the state of a circuit breaker
+type BreakerState string
+
+const (
+	// BreakerClosed lets calls through and counts consecutive failures
+	BreakerClosed BreakerState = "closed"
+
+	// BreakerOpen rejects calls until the cooldown has passed
+	BreakerOpen BreakerState = "open"
+
+	// BreakerHalfOpen lets a single trial call through to decide whether to close
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// errCircuitOpen is returned for calls rejected while the breaker is open
+var errCircuitOpen = fmt.Errorf("%w: circuit breaker open", ErrInternal)
+
+// CircuitBreakerRepository wraps a Repository and stops calling it after
+// threshold consecutive failures. While open, calls fail fast with
+// ErrInternal. After cooldown a single trial call is let through, closing the
+// breaker on success and reopening it on failure. Ping always reaches the
+// wrapped repository so health checks see the real store.
+type CircuitBreakerRepository struct {
+	Repository
+	log       logger.Logger
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+}
+
+// NewCircuitBreakerRepository creates a circuit breaker that opens after
+// threshold consecutive failures and half-opens after cooldown
+func NewCircuitBreakerRepository(repo Repository, log logger.Logger, threshold int, cooldown time.Duration) *CircuitBreakerRepository {
+	return &CircuitBreakerRepository{
+		Repository: repo,
+		log:        log,
+		threshold:  threshold,
+		cooldown:   cooldown,
+		state:      BreakerClosed,
+	}
+}
+
+// State returns the current breaker state
+func (r *CircuitBreakerRepository) State() BreakerState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Report an expired open breaker as ready for a trial call
+	if r.state == BreakerOpen && time.Since(r.openedAt) >= r.cooldown {
+		return BreakerHalfOpen
+	}
+	return r.state
+}
+
+// GetExample gets an example by ID through the breaker
+func (r *CircuitBreakerRepository) GetExample(ctx context.Context, id string) (*models.Example, error) {
+	if err := r.allow(); err != nil {
+		return nil, err
+	}
+	example, err := r.Repository.GetExample(ctx, id)
+	r.record(err)
+	return example, err
+}
+
+// GetExampleByName gets an example by name through the breaker
+func (r *CircuitBreakerRepository) GetExampleByName(ctx context.Context, name string) (*models.Example, error) {
+	if err := r.allow(); err != nil {
+		return nil, err
+	}
+	example, err := r.Repository.GetExampleByName(ctx, name)
+	r.record(err)
+	return example, err
+}
+
+// GetExamplesByIDs gets examples by ID through the breaker
+func (r *CircuitBreakerRepository) GetExamplesByIDs(ctx context.Context, ids []string) (map[string]*models.Example, error) {
+	if err := r.allow(); err != nil {
+		return nil, err
+	}
+	examples, err := r.Repository.GetExamplesByIDs(ctx, ids)
+	r.record(err)
+	return examples, err
+}
+
+// ArchiveExamples archives examples through the breaker
+func (r *CircuitBreakerRepository) ArchiveExamples(ctx context.Context, filter models.ArchiveFilter) ([]string, error) {
+	if err := r.allow(); err != nil {
+		return nil, err
+	}
+	ids, err := r.Repository.ArchiveExamples(ctx, filter)
+	r.record(err)
+	return ids, err
+}
+
+// SearchExamples searches examples through the breaker
+func (r *CircuitBreakerRepository) SearchExamples(ctx context.Context, query string, limit int) ([]*models.Example, error) {
+	if err := r.allow(); err != nil {
+		return nil, err
+	}
+	examples, err := r.Repository.SearchExamples(ctx, query, limit)
+	r.record(err)
+	return examples, err
+}
+
+// ListExamples lists examples through the breaker
+func (r *CircuitBreakerRepository) ListExamples(ctx context.Context, limit, offset int) ([]*models.Example, error) {
+	if err := r.allow(); err != nil {
+		return nil, err
+	}
+	examples, err := r.Repository.ListExamples(ctx, limit, offset)
+	r.record(err)
+	return examples, err
+}
+
+// CountExamples counts examples through the breaker
+func (r *CircuitBreakerRepository) CountExamples(ctx context.Context) (int, error) {
+	if err := r.allow(); err != nil {
+		return 0, err
+	}
+	count, err := r.Repository.CountExamples(ctx)
+	r.record(err)
+	return count, err
+}
+
+// ListExamplesAfter lists examples after a cursor through the breaker
+func (r *CircuitBreakerRepository) ListExamplesAfter(ctx context.Context, cursor string, limit int) ([]*models.Example, string, error) {
+	if err := r.allow(); err != nil {
+		return nil, "", err
+	}
+	examples, next, err := r.Repository.ListExamplesAfter(ctx, cursor, limit)
+	r.record(err)
+	return examples, next, err
+}
+
+// IterateExamples iterates examples through the breaker. Errors returned by
+// fn or from ctx ending don't count as failures.
+func (r *CircuitBreakerRepository) IterateExamples(ctx context.Context, fn func(*models.Example) error) error {
+	if err := r.allow(); err != nil {
+		return err
+	}
+
+	var fnErr error
+	err := r.Repository.IterateExamples(ctx, func(example *models.Example) error {
+		fnErr = fn(example)
+		return fnErr
+	})
+	if err != nil && (err == fnErr || ctx.Err() != nil) {
+		r.record(nil)
+		return err
+	}
+	r.record(err)
+	return err
+}
+
+// CreateExample creates an example through the breaker
+func (r *CircuitBreakerRepository) CreateExample(ctx context.Context, example *models.Example) error {
+	if err := r.allow(); err != nil {
+		return err
+	}
+	err := r.Repository.CreateExample(ctx, example)
+	r.record(err)
+	return err
+}
+
+// UpdateExample updates an example through the breaker
+func (r *CircuitBreakerRepository) UpdateExample(ctx context.Context, example *models.Example) error {
+	if err := r.allow(); err != nil {
+		return err
+	}
+	err := r.Repository.UpdateExample(ctx, example)
+	r.record(err)
+	return err
+}
+
+// UpdateExampleIfUnmodified conditionally updates an example through the breaker
+func (r *CircuitBreakerRepository) UpdateExampleIfUnmodified(ctx context.Context, example *models.Example, version time.Time) error {
+	if err := r.allow(); err != nil {
+		return err
+	}
+	err := r.Repository.UpdateExampleIfUnmodified(ctx, example, version)
+	r.record(err)
+	return err
+}
+
+// UpsertExample creates or replaces an example through the breaker
+func (r *CircuitBreakerRepository) UpsertExample(ctx context.Context, example *models.Example) (bool, error) {
+	if err := r.allow(); err != nil {
+		return false, err
+	}
+	created, err := r.Repository.UpsertExample(ctx, example)
+	r.record(err)
+	return created, err
+}
+
+// DeleteExample deletes an example through the breaker
+func (r *CircuitBreakerRepository) DeleteExample(ctx context.Context, id string) error {
+	if err := r.allow(); err != nil {
+		return err
+	}
+	err := r.Repository.DeleteExample(ctx, id)
+	r.record(err)
+	return err
+}
+
+// Reset resets the wrapped repository, if it supports it
+func (r *CircuitBreakerRepository) Reset(ctx context.Context) error {
+	resettable, ok := r.Repository.(Resettable)
+	if !ok {
+		return ErrNotResettable
+	}
+	return resettable.Reset(ctx)
+}
+
+// allow reports whether a call may proceed, moving an open breaker to
+// half-open once the cooldown has passed
+func (r *CircuitBreakerRepository) allow() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch r.state {
+	case BreakerOpen:
+		if time.Since(r.openedAt) < r.cooldown {
+			return errCircuitOpen
+		}
+		r.state = BreakerHalfOpen
+		r.log.Info("circuit breaker half-open, allowing trial call")
+		return nil
+
+	case BreakerHalfOpen:
+		// A trial call is already in flight
+		return errCircuitOpen
+
+	default:
+		return nil
+	}
+}
+
+// record updates the breaker with the outcome of a call
+func (r *CircuitBreakerRepository) record(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !isBreakerFailure(err) {
+		if r.state != BreakerClosed {
+			r.log.Info("circuit breaker closed")
+		}
+		r.state = BreakerClosed
+		r.failures = 0
+		return
+	}
+
+	r.failures++
+	if r.state == BreakerHalfOpen || r.failures >= r.threshold {
+		if r.state != BreakerOpen {
+			r.log.Warn("circuit breaker opened", logger.Int("failures", r.failures), logger.Error(err))
+		}
+		r.state = BreakerOpen
+		r.openedAt = time.Now()
+	}
+}
+
+// isBreakerFailure reports whether err indicates the store is unhealthy, as
+// opposed to an expected outcome such as a missing record, or a request that
+// was cancelled or ran out of time. Callers choose their own deadlines, so an
+// expired one says nothing about the store.
+func isBreakerFailure(err error) bool {
+	switch {
+	case err == nil,
+		errors.Is(err, ErrNotFound),
+		errors.Is(err, ErrAlreadyExists),
+		errors.Is(err, ErrInvalidData),
+		errors.Is(err, ErrInvalidCursor),
+		errors.Is(err, ErrModified),
+		errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded):
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,147 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dBiTech/go-apiTemplate/internal/models"
+	"github.com/dBiTech/go-apiTemplate/internal/repository"
+	"github.com/dBiTech/go-apiTemplate/pkg/health"
+	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+)
+
+// flakyRepository fails GetExample while failing is set and counts the calls
+// that reach it
+type flakyRepository struct {
+	*repository.MemoryRepository
+	failing atomic.Bool
+	calls   atomic.Int32
+}
+
+func (r *flakyRepository) GetExample(ctx context.Context, id string) (*models.Example, error) {
+	r.calls.Add(1)
+	if r.failing.Load() {
+		return nil, errors.New("connection refused")
+	}
+	return r.MemoryRepository.GetExample(ctx, id)
+}
+
+func TestCircuitBreakerRepository(t *testing.T) {
+	log := logger.Default()
+	ctx := context.Background()
+
+	// newBreaker creates a breaker over a flaky repository holding one example
+	newBreaker := func(t *testing.T) (*repository.CircuitBreakerRepository, *flakyRepository) {
+		t.Helper()
+
+		flaky := &flakyRepository{MemoryRepository: repository.NewMemoryRepository(log)}
+		err := flaky.CreateExample(ctx, &models.Example{
+			BaseModel: models.BaseModel{ID: "example-1", CreatedAt: time.Now()},
+			Name:      "Example",
+		})
+		require.NoError(t, err)
+
+		return repository.NewCircuitBreakerRepository(flaky, log, 3, 50*time.Millisecond), flaky
+	}
+
+	// Test consecutive failures open the breaker and calls then short-circuit
+	t.Run("Opens", func(t *testing.T) {
+		breaker, flaky := newBreaker(t)
+		flaky.failing.Store(true)
+
+		for i := 0; i < 3; i++ {
+			_, err := breaker.GetExample(ctx, "example-1")
+			require.Error(t, err)
+		}
+		assert.Equal(t, repository.BreakerOpen, breaker.State())
+
+		_, err := breaker.GetExample(ctx, "example-1")
+		assert.ErrorIs(t, err, repository.ErrInternal)
+		assert.Equal(t, int32(3), flaky.calls.Load())
+	})
+
+	// Test expected errors such as not found don't count as failures
+	t.Run("IgnoresNotFound", func(t *testing.T) {
+		breaker, _ := newBreaker(t)
+
+		for i := 0; i < 5; i++ {
+			_, err := breaker.GetExample(ctx, "missing")
+			require.ErrorIs(t, err, repository.ErrNotFound)
+		}
+		assert.Equal(t, repository.BreakerClosed, breaker.State())
+	})
+
+	// Test cancelled and expired requests don't count as failures
+	t.Run("IgnoresContextErrors", func(t *testing.T) {
+		breaker, _ := newBreaker(t)
+
+		expired, cancel := context.WithTimeout(ctx, time.Nanosecond)
+		defer cancel()
+		<-expired.Done()
+
+		cancelled, cancelNow := context.WithCancel(ctx)
+		cancelNow()
+
+		for i := 0; i < 5; i++ {
+			_, err := breaker.GetExample(expired, "example-1")
+			require.ErrorIs(t, err, context.DeadlineExceeded)
+			_, err = breaker.GetExample(cancelled, "example-1")
+			require.ErrorIs(t, err, context.Canceled)
+		}
+		assert.Equal(t, repository.BreakerClosed, breaker.State())
+	})
+
+	// Test a success after the cooldown closes the breaker
+	t.Run("ClosesAfterCooldown", func(t *testing.T) {
+		breaker, flaky := newBreaker(t)
+		flaky.failing.Store(true)
+		for i := 0; i < 3; i++ {
+			_, _ = breaker.GetExample(ctx, "example-1")
+		}
+		require.Equal(t, repository.BreakerOpen, breaker.State())
+
+		flaky.failing.Store(false)
+		time.Sleep(60 * time.Millisecond)
+		assert.Equal(t, repository.BreakerHalfOpen, breaker.State())
+
+		example, err := breaker.GetExample(ctx, "example-1")
+		require.NoError(t, err)
+		assert.Equal(t, "example-1", example.ID)
+		assert.Equal(t, repository.BreakerClosed, breaker.State())
+	})
+
+	// Test a failed trial call reopens the breaker
+	t.Run("ReopensOnFailedTrial", func(t *testing.T) {
+		breaker, flaky := newBreaker(t)
+		flaky.failing.Store(true)
+		for i := 0; i < 3; i++ {
+			_, _ = breaker.GetExample(ctx, "example-1")
+		}
+
+		time.Sleep(60 * time.Millisecond)
+		_, err := breaker.GetExample(ctx, "example-1")
+		require.Error(t, err)
+		assert.Equal(t, repository.BreakerOpen, breaker.State())
+	})
+
+	// Test the health check reports DEGRADED while the breaker is open
+	t.Run("HealthCheck", func(t *testing.T) {
+		breaker, flaky := newBreaker(t)
+		check := health.CircuitBreakerCheck("database-circuit", func() string {
+			return string(breaker.State())
+		})
+		assert.Equal(t, health.StatusUp, check(ctx).Status)
+
+		flaky.failing.Store(true)
+		for i := 0; i < 3; i++ {
+			_, _ = breaker.GetExample(ctx, "example-1")
+		}
+		assert.Equal(t, health.StatusDegraded, check(ctx).Status)
+	})
+}
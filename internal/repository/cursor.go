@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dBiTech/go-apiTemplate/internal/models"
+)
+
+// cursorKey is the sort key a pagination cursor points at: examples are
+// ordered by creation time, then by ID to break ties
+type cursorKey struct {
+	createdAt time.Time
+	id        string
+}
+
+// after reports whether the example sorts strictly after the key
+func (k cursorKey) after(example *models.Example) bool {
+	if !example.CreatedAt.Equal(k.createdAt) {
+		return example.CreatedAt.After(k.createdAt)
+	}
+	return example.ID > k.id
+}
+
+// encodeCursor builds an opaque cursor pointing at the given example
+func encodeCursor(example *models.Example) string {
+	key := strconv.FormatInt(example.CreatedAt.UnixNano(), 10) + ":" + example.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+// decodeCursor parses an opaque cursor produced by encodeCursor
+func decodeCursor(cursor string) (cursorKey, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return cursorKey{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	nanos, id, ok := strings.Cut(string(data), ":")
+	if !ok || id == "" {
+		return cursorKey{}, ErrInvalidCursor
+	}
+
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return cursorKey{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	return cursorKey{createdAt: time.Unix(0, n), id: id}, nil
+}
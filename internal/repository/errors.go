@@ -10,4 +10,7 @@ var (
 	ErrAlreadyExists = errors.New("resource already exists")
 	ErrInternal      = errors.New("internal repository error")
 	ErrInvalidData   = errors.New("invalid data")
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrNotResettable = errors.New("repository does not support reset")
+	ErrModified      = errors.New("resource has been modified")
 )
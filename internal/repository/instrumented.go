@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/dBiTech/go-apiTemplate/internal/models"
+	"github.com/dBiTech/go-apiTemplate/pkg/metrics"
+)
+
+// Results recorded for repository operations
+const (
+	resultSuccess = "success"
+	resultError   = "error"
+)
+
+// InstrumentedRepository wraps a Repository and records how long each
+// operation takes in repo_operation_duration_seconds, labeled with the
+// operation and whether it returned an error. Ping passes through unrecorded
+// so health checks don't skew the figures.
+type InstrumentedRepository struct {
+	Repository
+	durations *prometheus.HistogramVec
+}
+
+// NewInstrumentedRepository creates a repository that records the operation
+// durations of repo against m
+func NewInstrumentedRepository(repo Repository, m *metrics.Metrics) *InstrumentedRepository {
+	return &InstrumentedRepository{
+		Repository: repo,
+		durations: m.NewHistogram("repo_operation_duration_seconds",
+			"Duration of repository operations in seconds.",
+			[]string{"operation", "result"}, nil),
+	}
+}
+
+// GetExample gets an example by ID, recording its duration
+func (r *InstrumentedRepository) GetExample(ctx context.Context, id string) (*models.Example, error) {
+	start := time.Now()
+	example, err := r.Repository.GetExample(ctx, id)
+	r.observe("GetExample", start, err)
+	return example, err
+}
+
+// GetExampleByName gets an example by name, recording its duration
+func (r *InstrumentedRepository) GetExampleByName(ctx context.Context, name string) (*models.Example, error) {
+	start := time.Now()
+	example, err := r.Repository.GetExampleByName(ctx, name)
+	r.observe("GetExampleByName", start, err)
+	return example, err
+}
+
+// GetExamplesByIDs gets examples by ID, recording its duration
+func (r *InstrumentedRepository) GetExamplesByIDs(ctx context.Context, ids []string) (map[string]*models.Example, error) {
+	start := time.Now()
+	examples, err := r.Repository.GetExamplesByIDs(ctx, ids)
+	r.observe("GetExamplesByIDs", start, err)
+	return examples, err
+}
+
+// ArchiveExamples archives examples, recording its duration
+func (r *InstrumentedRepository) ArchiveExamples(ctx context.Context, filter models.ArchiveFilter) ([]string, error) {
+	start := time.Now()
+	ids, err := r.Repository.ArchiveExamples(ctx, filter)
+	r.observe("ArchiveExamples", start, err)
+	return ids, err
+}
+
+// SearchExamples searches examples, recording its duration
+func (r *InstrumentedRepository) SearchExamples(ctx context.Context, query string, limit int) ([]*models.Example, error) {
+	start := time.Now()
+	examples, err := r.Repository.SearchExamples(ctx, query, limit)
+	r.observe("SearchExamples", start, err)
+	return examples, err
+}
+
+// ListExamples lists examples, recording its duration
+func (r *InstrumentedRepository) ListExamples(ctx context.Context, limit, offset int) ([]*models.Example, error) {
+	start := time.Now()
+	examples, err := r.Repository.ListExamples(ctx, limit, offset)
+	r.observe("ListExamples", start, err)
+	return examples, err
+}
+
+// CountExamples counts examples, recording its duration
+func (r *InstrumentedRepository) CountExamples(ctx context.Context) (int, error) {
+	start := time.Now()
+	count, err := r.Repository.CountExamples(ctx)
+	r.observe("CountExamples", start, err)
+	return count, err
+}
+
+// ListExamplesAfter lists examples after a cursor, recording its duration
+func (r *InstrumentedRepository) ListExamplesAfter(ctx context.Context, cursor string, limit int) ([]*models.Example, string, error) {
+	start := time.Now()
+	examples, next, err := r.Repository.ListExamplesAfter(ctx, cursor, limit)
+	r.observe("ListExamplesAfter", start, err)
+	return examples, next, err
+}
+
+// IterateExamples iterates examples, recording its duration. The duration
+// includes the time spent in fn.
+func (r *InstrumentedRepository) IterateExamples(ctx context.Context, fn func(*models.Example) error) error {
+	start := time.Now()
+	err := r.Repository.IterateExamples(ctx, fn)
+	r.observe("IterateExamples", start, err)
+	return err
+}
+
+// CreateExample creates an example, recording its duration
+func (r *InstrumentedRepository) CreateExample(ctx context.Context, example *models.Example) error {
+	start := time.Now()
+	err := r.Repository.CreateExample(ctx, example)
+	r.observe("CreateExample", start, err)
+	return err
+}
+
+// UpdateExample updates an example, recording its duration
+func (r *InstrumentedRepository) UpdateExample(ctx context.Context, example *models.Example) error {
+	start := time.Now()
+	err := r.Repository.UpdateExample(ctx, example)
+	r.observe("UpdateExample", start, err)
+	return err
+}
+
+// UpdateExampleIfUnmodified conditionally updates an example, recording its
+// duration
+func (r *InstrumentedRepository) UpdateExampleIfUnmodified(ctx context.Context, example *models.Example, version time.Time) error {
+	start := time.Now()
+	err := r.Repository.UpdateExampleIfUnmodified(ctx, example, version)
+	r.observe("UpdateExampleIfUnmodified", start, err)
+	return err
+}
+
+// UpsertExample creates or replaces an example, recording its duration
+func (r *InstrumentedRepository) UpsertExample(ctx context.Context, example *models.Example) (bool, error) {
+	start := time.Now()
+	created, err := r.Repository.UpsertExample(ctx, example)
+	r.observe("UpsertExample", start, err)
+	return created, err
+}
+
+// DeleteExample deletes an example, recording its duration
+func (r *InstrumentedRepository) DeleteExample(ctx context.Context, id string) error {
+	start := time.Now()
+	err := r.Repository.DeleteExample(ctx, id)
+	r.observe("DeleteExample", start, err)
+	return err
+}
+
+// Reset resets the wrapped repository, if it supports it
+func (r *InstrumentedRepository) Reset(ctx context.Context) error {
+	resettable, ok := r.Repository.(Resettable)
+	if !ok {
+		return ErrNotResettable
+	}
+	return resettable.Reset(ctx)
+}
+
+// observe records the duration of an operation that started at start
+func (r *InstrumentedRepository) observe(operation string, start time.Time, err error) {
+	result := resultSuccess
+	if err != nil {
+		result = resultError
+	}
+	r.durations.WithLabelValues(operation, result).Observe(time.Since(start).Seconds())
+}
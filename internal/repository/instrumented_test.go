@@ -0,0 +1,66 @@
+package repository_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dBiTech/go-apiTemplate/internal/models"
+	"github.com/dBiTech/go-apiTemplate/internal/repository"
+	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+	"github.com/dBiTech/go-apiTemplate/pkg/metrics"
+)
+
+func TestInstrumentedRepository(t *testing.T) {
+	ctx := context.Background()
+
+	// scrape returns the metrics exposed by m
+	scrape := func(m *metrics.Metrics) string {
+		w := httptest.NewRecorder()
+		m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		return w.Body.String()
+	}
+
+	// newRepo creates an instrumented memory repository holding example "a"
+	newRepo := func(t *testing.T) (*repository.InstrumentedRepository, *metrics.Metrics) {
+		m := metrics.NewMetrics("test", metrics.WithGoCollector(false), metrics.WithProcessCollector(false))
+		backing := repository.NewMemoryRepository(logger.Default())
+		require.NoError(t, backing.CreateExample(ctx, models.NewExample("a", "Example a", "")))
+		return repository.NewInstrumentedRepository(backing, m), m
+	}
+
+	// Test a successful Get records a sample labeled with its operation
+	t.Run("GetExample", func(t *testing.T) {
+		repo, m := newRepo(t)
+
+		_, err := repo.GetExample(ctx, "a")
+		require.NoError(t, err)
+
+		body := scrape(m)
+		assert.Contains(t, body, `test_repo_operation_duration_seconds_count{operation="GetExample",result="success"} 1`)
+		assert.NotContains(t, body, `result="error"`)
+	})
+
+	// Test a failed Get is recorded as an error
+	t.Run("GetExampleError", func(t *testing.T) {
+		repo, m := newRepo(t)
+
+		_, err := repo.GetExample(ctx, "missing")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+
+		assert.Contains(t, scrape(m), `test_repo_operation_duration_seconds_count{operation="GetExample",result="error"} 1`)
+	})
+
+	// Test Ping isn't recorded
+	t.Run("PingUnrecorded", func(t *testing.T) {
+		repo, m := newRepo(t)
+
+		require.NoError(t, repo.Ping(ctx))
+
+		assert.NotContains(t, scrape(m), "repo_operation_duration_seconds")
+	})
+}
@@ -2,6 +2,11 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/dBiTech/go-apiTemplate/internal/models"
@@ -12,18 +17,54 @@ import (
 type Repository interface {
 	// Examples
 	GetExample(ctx context.Context, id string) (*models.Example, error)
+	GetExampleByName(ctx context.Context, name string) (*models.Example, error)
+	// GetExamplesByIDs gets the examples with the given IDs in one call, keyed
+	// by ID. IDs that don't exist are left out rather than failing the call.
+	GetExamplesByIDs(ctx context.Context, ids []string) (map[string]*models.Example, error)
+	// ArchiveExamples sets the status of every example matching filter to
+	// archived and returns the IDs of those that changed, in creation order
+	ArchiveExamples(ctx context.Context, filter models.ArchiveFilter) ([]string, error)
+	// SearchExamples finds up to limit examples whose name or description
+	// matches query, most relevant first. A limit of 0 or less returns every
+	// match. A database repository can back it with full-text search.
+	SearchExamples(ctx context.Context, query string, limit int) ([]*models.Example, error)
+	// ListExamples lists examples ordered by creation time and ID, so pages
+	// are stable between calls
 	ListExamples(ctx context.Context, limit, offset int) ([]*models.Example, error)
+	// CountExamples returns the number of examples across all pages
+	CountExamples(ctx context.Context) (int, error)
+	// ListExamplesAfter lists up to limit examples ordered by creation time
+	// and ID, starting after the given cursor (or from the start if empty).
+	// It returns the cursor for the next page, which is empty on the last page.
+	ListExamplesAfter(ctx context.Context, cursor string, limit int) ([]*models.Example, string, error)
+	// IterateExamples calls fn for every example in creation order, stopping
+	// at the first error from fn or when ctx is done
+	IterateExamples(ctx context.Context, fn func(*models.Example) error) error
 	CreateExample(ctx context.Context, example *models.Example) error
 	UpdateExample(ctx context.Context, example *models.Example) error
+	// UpdateExampleIfUnmodified updates the example only if the stored one
+	// was last updated at version, failing with ErrModified otherwise
+	UpdateExampleIfUnmodified(ctx context.Context, example *models.Example, version time.Time) error
+	// UpsertExample creates the example if its ID doesn't exist, or replaces
+	// it otherwise, reporting whether it was created
+	UpsertExample(ctx context.Context, example *models.Example) (created bool, err error)
 	DeleteExample(ctx context.Context, id string) error
 
 	// Health check
 	Ping(ctx context.Context) error
 }
 
+// Resettable is implemented by repositories that can discard all their data.
+// It is meant for development and test setups, never production data.
+type Resettable interface {
+	Reset(ctx context.Context) error
+}
+
 // MemoryRepository implements the Repository interface with in-memory storage
-// This is just for the template, in a real app you would implement a database repository
+// This is just for the template, in a real app you would implement a database repository.
+// Examples are copied in and out, so callers never share the stored ones.
 type MemoryRepository struct {
+	mu       sync.RWMutex
 	examples map[string]*models.Example
 	log      logger.Logger
 }
@@ -37,64 +78,404 @@ func NewMemoryRepository(log logger.Logger) *MemoryRepository {
 }
 
 // GetExample gets an example by ID
-func (r *MemoryRepository) GetExample(_ context.Context, id string) (*models.Example, error) {
+func (r *MemoryRepository) GetExample(ctx context.Context, id string) (*models.Example, error) {
+	if err := checkContext(ctx, "get example"); err != nil {
+		return nil, err
+	}
+
 	r.log.Debug("getting example", logger.String("id", id))
 
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	if example, ok := r.examples[id]; ok {
-		return example, nil
+		return copyExample(example), nil
 	}
 
 	return nil, ErrNotFound
 }
 
-// ListExamples lists examples
-func (r *MemoryRepository) ListExamples(_ context.Context, limit, offset int) ([]*models.Example, error) {
-	r.log.Debug("listing examples", logger.Int("limit", limit), logger.Int("offset", offset))
+// GetExampleByName gets an example by name
+func (r *MemoryRepository) GetExampleByName(ctx context.Context, name string) (*models.Example, error) {
+	if err := checkContext(ctx, "get example by name"); err != nil {
+		return nil, err
+	}
 
-	examples := make([]*models.Example, 0, len(r.examples))
+	r.log.Debug("getting example by name", logger.String("name", name))
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
-	i := 0
 	for _, example := range r.examples {
-		if i >= offset && (limit <= 0 || len(examples) < limit) {
-			examples = append(examples, example)
+		if example.Name == name {
+			return copyExample(example), nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// GetExamplesByIDs gets the examples with the given IDs
+func (r *MemoryRepository) GetExamplesByIDs(ctx context.Context, ids []string) (map[string]*models.Example, error) {
+	if err := checkContext(ctx, "get examples by IDs"); err != nil {
+		return nil, err
+	}
+
+	r.log.Debug("getting examples by IDs", logger.Int("count", len(ids)))
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	examples := make(map[string]*models.Example, len(ids))
+	for _, id := range ids {
+		if example, ok := r.examples[id]; ok {
+			examples[id] = copyExample(example)
 		}
-		i++
 	}
 
 	return examples, nil
 }
 
+// SearchExamples finds examples whose name or description contains query,
+// ignoring case. Name matches rank above description matches, and an exact or
+// leading name match above one elsewhere in the name; equally relevant
+// examples are in creation order.
+func (r *MemoryRepository) SearchExamples(ctx context.Context, query string, limit int) ([]*models.Example, error) {
+	if err := checkContext(ctx, "search examples"); err != nil {
+		return nil, err
+	}
+
+	r.log.Debug("searching examples", logger.String("query", query), logger.Int("limit", limit))
+
+	term := strings.ToLower(query)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	scores := make(map[*models.Example]int)
+	matches := make([]*models.Example, 0)
+	for _, example := range r.examples {
+		if score := searchScore(example, term); score > 0 {
+			scores[example] = score
+			matches = append(matches, example)
+		}
+	}
+
+	sortByCreation(matches)
+	sort.SliceStable(matches, func(i, j int) bool {
+		return scores[matches[i]] > scores[matches[j]]
+	})
+
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return copyExamples(matches), nil
+}
+
+// searchScore rates how well an example matches a lower-cased search term,
+// with 0 meaning no match
+func searchScore(example *models.Example, term string) int {
+	name := strings.ToLower(example.Name)
+	switch {
+	case name == term:
+		return 4
+	case strings.HasPrefix(name, term):
+		return 3
+	case strings.Contains(name, term):
+		return 2
+	case strings.Contains(strings.ToLower(example.Description), term):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// ListExamples lists examples ordered by creation time, then ID
+func (r *MemoryRepository) ListExamples(ctx context.Context, limit, offset int) ([]*models.Example, error) {
+	if err := checkContext(ctx, "list examples"); err != nil {
+		return nil, err
+	}
+
+	r.log.Debug("listing examples", logger.Int("limit", limit), logger.Int("offset", offset))
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Sort before paging so pages are stable between calls
+	sorted := make([]*models.Example, 0, len(r.examples))
+	for _, example := range r.examples {
+		sorted = append(sorted, example)
+	}
+	sortByCreation(sorted)
+
+	if offset > 0 {
+		if offset >= len(sorted) {
+			return []*models.Example{}, nil
+		}
+		sorted = sorted[offset:]
+	}
+	if limit > 0 && len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+
+	return copyExamples(sorted), nil
+}
+
+// CountExamples returns the number of stored examples
+func (r *MemoryRepository) CountExamples(ctx context.Context) (int, error) {
+	if err := checkContext(ctx, "count examples"); err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.examples), nil
+}
+
+// ListExamplesAfter lists examples after a cursor in creation order
+func (r *MemoryRepository) ListExamplesAfter(ctx context.Context, cursor string, limit int) ([]*models.Example, string, error) {
+	if err := checkContext(ctx, "list examples after cursor"); err != nil {
+		return nil, "", err
+	}
+
+	r.log.Debug("listing examples after cursor", logger.String("cursor", cursor), logger.Int("limit", limit))
+
+	var key *cursorKey
+	if cursor != "" {
+		k, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		key = &k
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sorted := make([]*models.Example, 0, len(r.examples))
+	for _, example := range r.examples {
+		if key == nil || key.after(example) {
+			sorted = append(sorted, example)
+		}
+	}
+
+	sortByCreation(sorted)
+
+	if limit <= 0 || len(sorted) <= limit {
+		return copyExamples(sorted), "", nil
+	}
+
+	page := sorted[:limit]
+	return copyExamples(page), encodeCursor(page[len(page)-1]), nil
+}
+
+// IterateExamples calls fn for every example in creation order
+func (r *MemoryRepository) IterateExamples(ctx context.Context, fn func(*models.Example) error) error {
+	if err := checkContext(ctx, "iterate examples"); err != nil {
+		return err
+	}
+
+	r.log.Debug("iterating examples")
+
+	// Take a snapshot so fn can call back into the repository
+	r.mu.RLock()
+	sorted := make([]*models.Example, 0, len(r.examples))
+	for _, example := range r.examples {
+		sorted = append(sorted, copyExample(example))
+	}
+	r.mu.RUnlock()
+	sortByCreation(sorted)
+
+	for _, example := range sorted {
+		if err := checkContext(ctx, "iterate examples"); err != nil {
+			return err
+		}
+		if err := fn(example); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkContext returns the context's error, wrapped with the operation, if
+// ctx is already done, the way a database driver would fail the call
+func checkContext(ctx context.Context, op string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// copyExample returns a copy of example that can be changed independently
+func copyExample(example *models.Example) *models.Example {
+	c := *example
+	return &c
+}
+
+// copyExamples returns a copy of every example in examples
+func copyExamples(examples []*models.Example) []*models.Example {
+	copies := make([]*models.Example, len(examples))
+	for i, example := range examples {
+		copies[i] = copyExample(example)
+	}
+	return copies
+}
+
+// sortByCreation sorts examples by creation time, then ID
+func sortByCreation(examples []*models.Example) {
+	sort.Slice(examples, func(i, j int) bool {
+		if !examples[i].CreatedAt.Equal(examples[j].CreatedAt) {
+			return examples[i].CreatedAt.Before(examples[j].CreatedAt)
+		}
+		return examples[i].ID < examples[j].ID
+	})
+}
+
 // CreateExample creates a new example
-func (r *MemoryRepository) CreateExample(_ context.Context, example *models.Example) error {
+func (r *MemoryRepository) CreateExample(ctx context.Context, example *models.Example) error {
+	if err := checkContext(ctx, "create example"); err != nil {
+		return err
+	}
+
 	r.log.Debug("creating example", logger.String("id", example.ID))
 
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	if _, ok := r.examples[example.ID]; ok {
 		return ErrAlreadyExists
 	}
 
-	r.examples[example.ID] = example
+	r.examples[example.ID] = copyExample(example)
 
 	return nil
 }
 
 // UpdateExample updates an example
-func (r *MemoryRepository) UpdateExample(_ context.Context, example *models.Example) error {
+func (r *MemoryRepository) UpdateExample(ctx context.Context, example *models.Example) error {
+	if err := checkContext(ctx, "update example"); err != nil {
+		return err
+	}
+
 	r.log.Debug("updating example", logger.String("id", example.ID))
 
-	if _, ok := r.examples[example.ID]; !ok {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.examples[example.ID]
+	if !ok {
 		return ErrNotFound
 	}
 
-	example.UpdatedAt = time.Now()
-	r.examples[example.ID] = example
+	example.UpdatedAt = nextUpdate(existing.UpdatedAt)
+	r.examples[example.ID] = copyExample(example)
 
 	return nil
 }
 
+// UpdateExampleIfUnmodified updates an example if it is still at version
+func (r *MemoryRepository) UpdateExampleIfUnmodified(ctx context.Context, example *models.Example, version time.Time) error {
+	if err := checkContext(ctx, "update example"); err != nil {
+		return err
+	}
+
+	r.log.Debug("updating example if unmodified", logger.String("id", example.ID))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.examples[example.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if !existing.UpdatedAt.Equal(version) {
+		return ErrModified
+	}
+
+	example.UpdatedAt = nextUpdate(existing.UpdatedAt)
+	r.examples[example.ID] = copyExample(example)
+
+	return nil
+}
+
+// nextUpdate returns the update time of an example last updated at prev. It
+// is always after prev, so the update time identifies each version even when
+// the clock hasn't advanced.
+func nextUpdate(prev time.Time) time.Time {
+	now := time.Now()
+	if !now.After(prev) {
+		now = prev.Add(time.Nanosecond)
+	}
+	return now
+}
+
+// ArchiveExamples archives every example matching filter
+func (r *MemoryRepository) ArchiveExamples(ctx context.Context, filter models.ArchiveFilter) ([]string, error) {
+	if err := checkContext(ctx, "archive examples"); err != nil {
+		return nil, err
+	}
+
+	r.log.Debug("archiving examples", logger.String("status", filter.Status))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	archived := make([]*models.Example, 0)
+	for _, example := range r.examples {
+		if !filter.Matches(example) {
+			continue
+		}
+
+		example.Status = models.StatusArchived
+		example.UpdatedAt = nextUpdate(example.UpdatedAt)
+		archived = append(archived, example)
+	}
+
+	sortByCreation(archived)
+	ids := make([]string, len(archived))
+	for i, example := range archived {
+		ids[i] = example.ID
+	}
+
+	return ids, nil
+}
+
+// UpsertExample creates or replaces an example. A replaced example keeps its
+// original creation time.
+func (r *MemoryRepository) UpsertExample(ctx context.Context, example *models.Example) (bool, error) {
+	if err := checkContext(ctx, "upsert example"); err != nil {
+		return false, err
+	}
+
+	r.log.Debug("upserting example", logger.String("id", example.ID))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.examples[example.ID]
+	if ok {
+		example.CreatedAt = existing.CreatedAt
+		example.UpdatedAt = nextUpdate(existing.UpdatedAt)
+	}
+	r.examples[example.ID] = copyExample(example)
+
+	return !ok, nil
+}
+
 // DeleteExample deletes an example
-func (r *MemoryRepository) DeleteExample(_ context.Context, id string) error {
+func (r *MemoryRepository) DeleteExample(ctx context.Context, id string) error {
+	if err := checkContext(ctx, "delete example"); err != nil {
+		return err
+	}
+
 	r.log.Debug("deleting example", logger.String("id", id))
 
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	if _, ok := r.examples[id]; !ok {
 		return ErrNotFound
 	}
@@ -104,8 +485,79 @@ func (r *MemoryRepository) DeleteExample(_ context.Context, id string) error {
 	return nil
 }
 
+// Reset removes all examples
+func (r *MemoryRepository) Reset(ctx context.Context) error {
+	if err := checkContext(ctx, "reset examples"); err != nil {
+		return err
+	}
+
+	r.log.Debug("resetting examples")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.examples = make(map[string]*models.Example)
+
+	return nil
+}
+
+// memorySnapshot is the serialized form of a MemoryRepository
+type memorySnapshot struct {
+	Examples []*models.Example `json:"examples"`
+}
+
+// Snapshot serializes every example in the store, in creation order, so the
+// store can later be rolled back with Restore
+func (r *MemoryRepository) Snapshot() []byte {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	examples := make([]*models.Example, 0, len(r.examples))
+	for _, example := range r.examples {
+		examples = append(examples, example)
+	}
+	sortByCreation(examples)
+
+	// Examples only hold plain fields, so marshaling can't fail
+	data, _ := json.Marshal(memorySnapshot{Examples: examples})
+	return data
+}
+
+// Restore replaces the contents of the store with a snapshot taken by
+// Snapshot. The store is left unchanged if the snapshot is invalid.
+func (r *MemoryRepository) Restore(data []byte) error {
+	var snapshot memorySnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("%w: snapshot: %v", ErrInvalidData, err)
+	}
+
+	examples := make(map[string]*models.Example, len(snapshot.Examples))
+	for _, example := range snapshot.Examples {
+		if example == nil || example.ID == "" {
+			return fmt.Errorf("%w: snapshot example without an ID", ErrInvalidData)
+		}
+		if _, ok := examples[example.ID]; ok {
+			return fmt.Errorf("%w: snapshot has duplicate example ID %q", ErrInvalidData, example.ID)
+		}
+		examples[example.ID] = example
+	}
+
+	r.log.Debug("restoring examples", logger.Int("count", len(examples)))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.examples = examples
+
+	return nil
+}
+
 // Ping checks database connectivity
-func (r *MemoryRepository) Ping(_ context.Context) error {
+func (r *MemoryRepository) Ping(ctx context.Context) error {
+	if err := checkContext(ctx, "ping"); err != nil {
+		return err
+	}
+
 	// For memory repository, this always succeeds
 	return nil
 }
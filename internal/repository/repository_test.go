@@ -2,6 +2,8 @@ package repository_test
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -108,6 +110,140 @@ func TestMemoryRepository(t *testing.T) {
 		examples, err = repo.ListExamples(ctx, 0, 0)
 		require.NoError(t, err)
 		assert.Len(t, examples, 5)
+
+		// Count across all pages
+		count, err := repo.CountExamples(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 5, count)
+	})
+
+	// Test ListExamples orders by creation time, then ID, the same way every call
+	t.Run("ListExamplesStableOrder", func(t *testing.T) {
+		repo := repository.NewMemoryRepository(log)
+
+		// c and a share a creation time, so their IDs break the tie
+		base := time.Now()
+		created := map[string]time.Duration{"e": 0, "c": time.Second, "a": time.Second, "d": 2 * time.Second, "b": 3 * time.Second}
+		for id, offset := range created {
+			example := models.NewExample(id, "Example "+id, "")
+			example.CreatedAt = base.Add(offset)
+			require.NoError(t, repo.CreateExample(ctx, example))
+		}
+
+		ids := func(examples []*models.Example) []string {
+			out := make([]string, len(examples))
+			for i, example := range examples {
+				out[i] = example.ID
+			}
+			return out
+		}
+
+		first, err := repo.ListExamples(ctx, 0, 0)
+		require.NoError(t, err)
+		second, err := repo.ListExamples(ctx, 0, 0)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"e", "a", "c", "d", "b"}, ids(first))
+		assert.Equal(t, ids(first), ids(second))
+
+		page, err := repo.ListExamples(ctx, 2, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "c"}, ids(page))
+	})
+
+	// Test ArchiveExamples archives only the matching examples and returns
+	// their IDs
+	t.Run("ArchiveExamples", func(t *testing.T) {
+		repo := repository.NewMemoryRepository(log)
+
+		cutoff := time.Now()
+		for _, e := range []struct {
+			id, status string
+			age        time.Duration
+		}{
+			{"old-inactive", models.StatusInactive, time.Hour},
+			{"new-inactive", models.StatusInactive, -time.Hour},
+			{"old-active", models.StatusActive, time.Hour},
+			{"old-archived", models.StatusArchived, time.Hour},
+		} {
+			example := models.NewExample(e.id, "Example "+e.id, "")
+			example.Status = e.status
+			example.CreatedAt = cutoff.Add(-e.age)
+			require.NoError(t, repo.CreateExample(ctx, example))
+		}
+
+		before, err := repo.GetExample(ctx, "old-inactive")
+		require.NoError(t, err)
+
+		ids, err := repo.ArchiveExamples(ctx, models.ArchiveFilter{Status: models.StatusInactive, OlderThan: cutoff})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"old-inactive"}, ids)
+
+		statuses := map[string]string{
+			"old-inactive": models.StatusArchived,
+			"new-inactive": models.StatusInactive,
+			"old-active":   models.StatusActive,
+			"old-archived": models.StatusArchived,
+		}
+		for id, status := range statuses {
+			example, err := repo.GetExample(ctx, id)
+			require.NoError(t, err)
+			assert.Equal(t, status, example.Status, id)
+		}
+
+		// Test an example fetched earlier isn't changed in place
+		assert.Equal(t, models.StatusInactive, before.Status)
+
+		// Test examples already archived aren't counted again
+		ids, err = repo.ArchiveExamples(ctx, models.ArchiveFilter{OlderThan: cutoff})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"old-active"}, ids)
+	})
+
+	// Test SearchExamples matches names and descriptions, most relevant first
+	t.Run("SearchExamples", func(t *testing.T) {
+		repo := repository.NewMemoryRepository(log)
+
+		base := time.Now()
+		for i, example := range []*models.Example{
+			models.NewExample("desc", "Plain", "Has a Widget inside"),
+			models.NewExample("contains", "Blue widget", ""),
+			models.NewExample("prefix", "Widget stand", ""),
+			models.NewExample("exact", "widget", ""),
+			models.NewExample("other", "Gadget", "Nothing to see"),
+		} {
+			example.CreatedAt = base.Add(time.Duration(i) * time.Second)
+			require.NoError(t, repo.CreateExample(ctx, example))
+		}
+
+		ids := func(examples []*models.Example) []string {
+			out := make([]string, len(examples))
+			for i, example := range examples {
+				out[i] = example.ID
+			}
+			return out
+		}
+
+		// Test a name match ignoring case, ranked above description matches
+		examples, err := repo.SearchExamples(ctx, "WIDGET", 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"exact", "prefix", "contains", "desc"}, ids(examples))
+
+		// Test a match on the description alone
+		examples, err = repo.SearchExamples(ctx, "to see", 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"other"}, ids(examples))
+
+		// Test the limit keeps the most relevant matches
+		examples, err = repo.SearchExamples(ctx, "widget", 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"exact", "prefix"}, ids(examples))
+
+		// Test no match returns an empty list
+		examples, err = repo.SearchExamples(ctx, "sprocket", 10)
+		require.NoError(t, err)
+		assert.NotNil(t, examples)
+		assert.Empty(t, examples)
 	})
 
 	// Test UpdateExample
@@ -153,6 +289,51 @@ func TestMemoryRepository(t *testing.T) {
 		assert.Equal(t, repository.ErrNotFound, err)
 	})
 
+	// Test UpsertExample creates an example with an unknown ID
+	t.Run("UpsertExampleCreates", func(t *testing.T) {
+		id := uuid.New().String()
+		example := &models.Example{
+			BaseModel: models.BaseModel{
+				ID:        id,
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+			},
+			Name: "Upsert Example",
+		}
+
+		created, err := repo.UpsertExample(ctx, example)
+		require.NoError(t, err)
+		assert.True(t, created)
+
+		retrieved, err := repo.GetExample(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "Upsert Example", retrieved.Name)
+	})
+
+	// Test UpsertExample replaces an existing example, keeping its creation time
+	t.Run("UpsertExampleUpdates", func(t *testing.T) {
+		id := uuid.New().String()
+		createdAt := time.Now().Add(-time.Hour)
+		err := repo.CreateExample(ctx, &models.Example{
+			BaseModel: models.BaseModel{ID: id, CreatedAt: createdAt, UpdatedAt: createdAt},
+			Name:      "Original",
+		})
+		require.NoError(t, err)
+
+		created, err := repo.UpsertExample(ctx, &models.Example{
+			BaseModel: models.BaseModel{ID: id, CreatedAt: time.Now(), UpdatedAt: time.Now()},
+			Name:      "Replaced",
+		})
+		require.NoError(t, err)
+		assert.False(t, created)
+
+		retrieved, err := repo.GetExample(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "Replaced", retrieved.Name)
+		assert.True(t, retrieved.CreatedAt.Equal(createdAt))
+		assert.True(t, retrieved.UpdatedAt.After(createdAt))
+	})
+
 	// Test DeleteExample
 	t.Run("DeleteExample", func(t *testing.T) {
 		// Create example first
@@ -190,3 +371,416 @@ func TestMemoryRepository(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestMemoryRepositoryCursor(t *testing.T) {
+	log := logger.Default()
+	ctx := context.Background()
+
+	// newRepo creates a repository with n examples, some sharing a creation time
+	newRepo := func(t *testing.T, n int) *repository.MemoryRepository {
+		repo := repository.NewMemoryRepository(log)
+		base := time.Now()
+		for i := 0; i < n; i++ {
+			created := base.Add(time.Duration(i/2) * time.Second)
+			example := &models.Example{
+				BaseModel: models.BaseModel{
+					ID:        uuid.New().String(),
+					CreatedAt: created,
+					UpdatedAt: created,
+				},
+				Name: "Cursor Example",
+			}
+			require.NoError(t, repo.CreateExample(ctx, example))
+		}
+		return repo
+	}
+
+	// walk follows cursors through every page and returns the IDs in order
+	walk := func(t *testing.T, repo *repository.MemoryRepository, limit int) []string {
+		var ids []string
+		cursor := ""
+		for pages := 0; ; pages++ {
+			require.Less(t, pages, 100, "pagination did not terminate")
+
+			examples, next, err := repo.ListExamplesAfter(ctx, cursor, limit)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(examples), limit)
+
+			for _, example := range examples {
+				ids = append(ids, example.ID)
+			}
+
+			if next == "" {
+				return ids
+			}
+			cursor = next
+		}
+	}
+
+	// Test walking all pages returns every example exactly once in order
+	t.Run("AllPages", func(t *testing.T) {
+		repo := newRepo(t, 11)
+
+		ids := walk(t, repo, 3)
+		require.Len(t, ids, 11)
+
+		seen := make(map[string]bool)
+		for _, id := range ids {
+			assert.False(t, seen[id], "duplicate example %s", id)
+			seen[id] = true
+		}
+
+		all, next, err := repo.ListExamplesAfter(ctx, "", 0)
+		require.NoError(t, err)
+		assert.Empty(t, next)
+		for i, example := range all {
+			assert.Equal(t, example.ID, ids[i])
+			if i > 0 {
+				prev := all[i-1]
+				assert.True(t, prev.CreatedAt.Before(example.CreatedAt) ||
+					(prev.CreatedAt.Equal(example.CreatedAt) && prev.ID < example.ID))
+			}
+		}
+	})
+
+	// Test an exact final page has no next cursor
+	t.Run("ExactLastPage", func(t *testing.T) {
+		repo := newRepo(t, 4)
+
+		examples, next, err := repo.ListExamplesAfter(ctx, "", 2)
+		require.NoError(t, err)
+		require.Len(t, examples, 2)
+		require.NotEmpty(t, next)
+
+		examples, next, err = repo.ListExamplesAfter(ctx, next, 2)
+		require.NoError(t, err)
+		assert.Len(t, examples, 2)
+		assert.Empty(t, next)
+	})
+
+	// Test deleting seen examples mid-scroll doesn't shift later pages
+	t.Run("DeleteMidScroll", func(t *testing.T) {
+		repo := newRepo(t, 6)
+
+		first, next, err := repo.ListExamplesAfter(ctx, "", 3)
+		require.NoError(t, err)
+		require.Len(t, first, 3)
+
+		for _, example := range first {
+			require.NoError(t, repo.DeleteExample(ctx, example.ID))
+		}
+
+		second, next, err := repo.ListExamplesAfter(ctx, next, 3)
+		require.NoError(t, err)
+		assert.Len(t, second, 3)
+		assert.Empty(t, next)
+	})
+
+	// Test an invalid cursor is rejected
+	t.Run("InvalidCursor", func(t *testing.T) {
+		repo := newRepo(t, 1)
+
+		for _, cursor := range []string{"not base64!", "bm90LWEtY3Vyc29y", "YWJjOmRlZg"} {
+			_, _, err := repo.ListExamplesAfter(ctx, cursor, 10)
+			assert.ErrorIs(t, err, repository.ErrInvalidCursor, cursor)
+		}
+	})
+}
+
+func TestMemoryRepositoryReset(t *testing.T) {
+	log := logger.Default()
+	ctx := context.Background()
+
+	// Test reset removes every example
+	t.Run("EmptiesStore", func(t *testing.T) {
+		repo := repository.NewMemoryRepository(log)
+		for i := 0; i < 3; i++ {
+			err := repo.CreateExample(ctx, &models.Example{
+				BaseModel: models.BaseModel{ID: uuid.New().String(), CreatedAt: time.Now()},
+				Name:      "Example",
+			})
+			require.NoError(t, err)
+		}
+
+		var resettable repository.Resettable = repo
+		require.NoError(t, resettable.Reset(ctx))
+
+		examples, err := repo.ListExamples(ctx, 0, 0)
+		require.NoError(t, err)
+		assert.Empty(t, examples)
+	})
+
+	// Test the caching repository resets the wrapped repository and its cache
+	t.Run("Caching", func(t *testing.T) {
+		repo := repository.NewMemoryRepository(log)
+		cached := repository.NewCachingRepository(repo, log, 10, time.Minute)
+
+		id := uuid.New().String()
+		err := cached.CreateExample(ctx, &models.Example{
+			BaseModel: models.BaseModel{ID: id, CreatedAt: time.Now()},
+			Name:      "Example",
+		})
+		require.NoError(t, err)
+		_, err = cached.GetExample(ctx, id)
+		require.NoError(t, err)
+
+		require.NoError(t, cached.Reset(ctx))
+
+		_, err = cached.GetExample(ctx, id)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
+
+func TestMemoryRepositoryIterate(t *testing.T) {
+	log := logger.Default()
+	ctx := context.Background()
+
+	repo := repository.NewMemoryRepository(log)
+	base := time.Now()
+	for i := 0; i < 5; i++ {
+		err := repo.CreateExample(ctx, &models.Example{
+			BaseModel: models.BaseModel{ID: uuid.New().String(), CreatedAt: base.Add(time.Duration(i) * time.Second)},
+			Name:      fmt.Sprintf("Example %d", i),
+		})
+		require.NoError(t, err)
+	}
+
+	// Test every example is visited in creation order
+	t.Run("CreationOrder", func(t *testing.T) {
+		var names []string
+		err := repo.IterateExamples(ctx, func(example *models.Example) error {
+			names = append(names, example.Name)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Example 0", "Example 1", "Example 2", "Example 3", "Example 4"}, names)
+	})
+
+	// Test iteration stops once the context is cancelled
+	t.Run("Cancelled", func(t *testing.T) {
+		cancelCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		visited := 0
+		err := repo.IterateExamples(cancelCtx, func(*models.Example) error {
+			visited++
+			if visited == 2 {
+				cancel()
+			}
+			return nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 2, visited)
+	})
+}
+
+func TestMemoryRepositoryGetByIDs(t *testing.T) {
+	log := logger.Default()
+	ctx := context.Background()
+
+	repo := repository.NewMemoryRepository(log)
+	ids := make([]string, 3)
+	for i := range ids {
+		ids[i] = uuid.New().String()
+		require.NoError(t, repo.CreateExample(ctx, models.NewExample(ids[i], fmt.Sprintf("Example %d", i), "")))
+	}
+
+	// Test every requested example is returned keyed by ID
+	t.Run("AllFound", func(t *testing.T) {
+		examples, err := repo.GetExamplesByIDs(ctx, ids)
+		require.NoError(t, err)
+		require.Len(t, examples, 3)
+		for i, id := range ids {
+			assert.Equal(t, fmt.Sprintf("Example %d", i), examples[id].Name)
+		}
+	})
+
+	// Test unknown IDs are left out without failing
+	t.Run("SomeMissing", func(t *testing.T) {
+		missing := uuid.New().String()
+		examples, err := repo.GetExamplesByIDs(ctx, []string{ids[0], missing, ids[2]})
+		require.NoError(t, err)
+		assert.Len(t, examples, 2)
+		assert.Contains(t, examples, ids[0])
+		assert.Contains(t, examples, ids[2])
+		assert.NotContains(t, examples, missing)
+	})
+
+	// Test no IDs get an empty result
+	t.Run("EmptyInput", func(t *testing.T) {
+		examples, err := repo.GetExamplesByIDs(ctx, nil)
+		require.NoError(t, err)
+		assert.Empty(t, examples)
+	})
+}
+
+func TestMemoryRepositoryContext(t *testing.T) {
+	log := logger.Default()
+	repo := repository.NewMemoryRepository(log)
+
+	id := uuid.New().String()
+	err := repo.CreateExample(context.Background(), &models.Example{
+		BaseModel: models.BaseModel{ID: id, CreatedAt: time.Now()},
+		Name:      "Example",
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Test a cancelled context fails reads with a context error
+	t.Run("GetExample", func(t *testing.T) {
+		example, err := repo.GetExample(ctx, id)
+		assert.Nil(t, example)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	// Test a cancelled context stops writes from happening
+	t.Run("DeleteExample", func(t *testing.T) {
+		err := repo.DeleteExample(ctx, id)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		_, err = repo.GetExample(context.Background(), id)
+		assert.NoError(t, err)
+	})
+}
+
+func TestMemoryRepositoryConcurrency(t *testing.T) {
+	log := logger.Default()
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository(log)
+
+	const workers = 20
+	const perWorker = 25
+
+	// Each worker creates examples, reads them back, lists, and deletes every other one
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				id := fmt.Sprintf("worker-%d-%d", w, i)
+				err := repo.CreateExample(ctx, &models.Example{
+					BaseModel: models.BaseModel{ID: id, CreatedAt: time.Now()},
+					Name:      id,
+				})
+				assert.NoError(t, err)
+
+				_, err = repo.GetExample(ctx, id)
+				assert.NoError(t, err)
+
+				_, err = repo.ListExamples(ctx, 10, 0)
+				assert.NoError(t, err)
+
+				if i%2 == 0 {
+					assert.NoError(t, repo.DeleteExample(ctx, id))
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	// Test the final count reflects every create and delete
+	examples, err := repo.ListExamples(ctx, 0, 0)
+	require.NoError(t, err)
+	assert.Len(t, examples, workers*(perWorker/2))
+}
+
+func TestMemoryRepositoryCopies(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository(logger.Default())
+
+	example := models.NewExample("a", "Example", "")
+	require.NoError(t, repo.CreateExample(ctx, example))
+
+	// Test changing a created example doesn't change the stored one
+	example.Name = "Changed"
+	stored, err := repo.GetExample(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "Example", stored.Name)
+
+	// Test changing returned examples doesn't change the stored one
+	stored.Name = "Changed"
+	listed, err := repo.ListExamples(ctx, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, "Example", listed[0].Name)
+
+	listed[0].Name = "Changed"
+	byName, err := repo.GetExampleByName(ctx, "Example")
+	require.NoError(t, err)
+	assert.Equal(t, "Example", byName.Name)
+}
+
+func TestMemoryRepositorySnapshot(t *testing.T) {
+	log := logger.Default()
+	ctx := context.Background()
+
+	repo := repository.NewMemoryRepository(log)
+	base := time.Now()
+	for i, name := range []string{"First", "Second", "Third"} {
+		err := repo.CreateExample(ctx, &models.Example{
+			BaseModel: models.BaseModel{ID: fmt.Sprintf("example-%d", i), CreatedAt: base.Add(time.Duration(i) * time.Second)},
+			Name:      name,
+		})
+		require.NoError(t, err)
+	}
+
+	snapshot := repo.Snapshot()
+
+	// names returns the names of every example in creation order
+	names := func(t *testing.T) []string {
+		t.Helper()
+
+		examples, err := repo.ListExamples(ctx, 0, 0)
+		require.NoError(t, err)
+		out := make([]string, 0, len(examples))
+		for _, example := range examples {
+			out = append(out, example.Name)
+		}
+		return out
+	}
+
+	// Test restoring rolls back creates, updates and deletes
+	t.Run("RollsBack", func(t *testing.T) {
+		require.NoError(t, repo.DeleteExample(ctx, "example-0"))
+		require.NoError(t, repo.UpdateExample(ctx, &models.Example{
+			BaseModel: models.BaseModel{ID: "example-1", CreatedAt: base.Add(time.Second)},
+			Name:      "Changed",
+		}))
+		require.NoError(t, repo.CreateExample(ctx, &models.Example{
+			BaseModel: models.BaseModel{ID: "example-new", CreatedAt: base.Add(time.Minute)},
+			Name:      "New",
+		}))
+		require.NotEqual(t, snapshot, repo.Snapshot())
+
+		require.NoError(t, repo.Restore(snapshot))
+
+		assert.Equal(t, snapshot, repo.Snapshot())
+		assert.ElementsMatch(t, []string{"First", "Second", "Third"}, names(t))
+		_, err := repo.GetExample(ctx, "example-new")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+
+	// Test a snapshot can be restored into a different repository
+	t.Run("OtherRepository", func(t *testing.T) {
+		other := repository.NewMemoryRepository(log)
+		require.NoError(t, other.Restore(snapshot))
+
+		assert.Equal(t, snapshot, other.Snapshot())
+	})
+
+	// Test invalid snapshots are rejected and leave the store unchanged
+	t.Run("Invalid", func(t *testing.T) {
+		for _, data := range []string{
+			`not json`,
+			`{"examples":[{"name":"no id"}]}`,
+			`{"examples":[{"id":"dup"},{"id":"dup"}]}`,
+		} {
+			err := repo.Restore([]byte(data))
+			assert.ErrorIs(t, err, repository.ErrInvalidData, data)
+		}
+		assert.Equal(t, snapshot, repo.Snapshot())
+	})
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/dBiTech/go-apiTemplate/internal/audit"
+	"github.com/dBiTech/go-apiTemplate/internal/models"
+	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+)
+
+// bulkRollbackTimeout bounds the rollback of an atomic batch, which runs
+// even when the request's context is done
+const bulkRollbackTimeout = 30 * time.Second
+
+// BulkResult is the outcome of creating a single example in a bulk request.
+// Exactly one of Example and Err is set.
+type BulkResult struct {
+	Example *models.Example
+	Err     error
+}
+
+// BulkCreateExamples creates each requested example and reports a result per
+// item in request order. When atomic is false, failed items are skipped and
+// the rest are still created. When atomic is true, any failure rolls back the
+// whole batch and every item that didn't fail itself reports ErrRolledBack.
+// The returned error is only set when a rollback could not be completed.
+func (s *Service) BulkCreateExamples(
+	ctx context.Context,
+	reqs []*models.ExampleRequest,
+	atomic bool,
+) ([]BulkResult, error) {
+	ctx, span := s.tracer().Start(ctx, "Service.BulkCreateExamples")
+	defer span.End()
+	span.SetAttributes(attribute.Int("count", len(reqs)), attribute.Bool("atomic", atomic))
+
+	s.log.Debug("bulk creating examples", logger.Int("count", len(reqs)), logger.Bool("atomic", atomic))
+
+	results := make([]BulkResult, len(reqs))
+
+	// Validate up front so an atomic batch fails before anything is written
+	invalid := false
+	for i, req := range reqs {
+		if err := validateExampleRequest(req); err != nil {
+			results[i].Err = err
+			invalid = true
+		}
+	}
+	if atomic && invalid {
+		markRolledBack(results)
+		return results, nil
+	}
+
+	created := make([]int, 0, len(reqs))
+	for i, req := range reqs {
+		if results[i].Err != nil {
+			continue
+		}
+
+		example := models.NewExample(uuid.New().String(), req.Name, req.Description)
+		if req.Status != "" {
+			example.Status = req.Status
+		}
+		err := s.checkNameAvailable(ctx, req.Name)
+		if err == nil {
+			err = s.repo.CreateExample(ctx, example)
+		}
+		if err != nil {
+			s.log.Error("failed to create example", logger.String("name", req.Name), logger.Error(err))
+			span.RecordError(err)
+			results[i].Err = translate(err)
+
+			if atomic {
+				return s.rollbackBulkCreate(ctx, results, created)
+			}
+			continue
+		}
+
+		results[i].Example = example
+		created = append(created, i)
+	}
+
+	if len(created) > 0 {
+		s.invalidateListCache()
+
+		for _, i := range created {
+			s.publish(ctx, EventExampleCreated, results[i].Example.ID)
+			s.audit(ctx, audit.ActionCreate, results[i].Example.ID)
+		}
+
+		if s.examplesCreated != nil {
+			s.examplesCreated.WithLabelValues().Add(float64(len(created)))
+		}
+	}
+
+	span.SetAttributes(attribute.Int("created", len(created)))
+	return results, nil
+}
+
+// rollbackBulkCreate deletes the examples created so far in an atomic batch
+// and marks every item that didn't fail itself as rolled back. The deletes
+// aren't cancelled with ctx, as the batch may have failed because ctx was done.
+func (s *Service) rollbackBulkCreate(ctx context.Context, results []BulkResult, created []int) ([]BulkResult, error) {
+	// Created examples may already have been observed by a concurrent list
+	defer s.invalidateListCache()
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), bulkRollbackTimeout)
+	defer cancel()
+
+	for _, i := range created {
+		id := results[i].Example.ID
+		if err := s.repo.DeleteExample(ctx, id); err != nil {
+			s.log.Error("failed to roll back example", logger.String("id", id), logger.Error(err))
+			return nil, fmt.Errorf("rolling back bulk create: %w", err)
+		}
+	}
+
+	markRolledBack(results)
+	return results, nil
+}
+
+// markRolledBack replaces every successful result with ErrRolledBack
+func markRolledBack(results []BulkResult) {
+	for i := range results {
+		if results[i].Err == nil {
+			results[i] = BulkResult{Err: ErrRolledBack}
+		}
+	}
+}
+
+// Length limits of example requests, matching the validate tags of
+// models.ExampleRequest
+const (
+	minExampleNameLength        = 3
+	maxExampleNameLength        = 100
+	maxExampleDescriptionLength = 500
+)
+
+// validateExampleRequest checks that an example request can be created,
+// applying the same rules as the OpenAPI spec of POST /examples
+func validateExampleRequest(req *models.ExampleRequest) error {
+	if req == nil {
+		return fmt.Errorf("%w: example is required", ErrInvalidRequest)
+	}
+	if req.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidRequest)
+	}
+	if n := utf8.RuneCountInString(req.Name); n < minExampleNameLength || n > maxExampleNameLength {
+		return fmt.Errorf("%w: name must be %d to %d characters", ErrInvalidRequest, minExampleNameLength, maxExampleNameLength)
+	}
+	if utf8.RuneCountInString(req.Description) > maxExampleDescriptionLength {
+		return fmt.Errorf("%w: description must be at most %d characters", ErrInvalidRequest, maxExampleDescriptionLength)
+	}
+	return validateStatus(req.Status)
+}
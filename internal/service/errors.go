@@ -0,0 +1,69 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/dBiTech/go-apiTemplate/internal/repository"
+)
+
+// Common service errors. Errors returned by the service match one of
+// ErrNotFound, ErrConflict, ErrValidation, ErrPreconditionFailed or
+// ErrInternal with errors.Is when they fall in that class, so callers needn't
+// know about the repository.
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrConflict           = errors.New("conflict")
+	ErrValidation         = errors.New("invalid request")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrInternal           = errors.New("internal error")
+
+	// ErrInvalidRequest is ErrValidation under its original name
+	ErrInvalidRequest = ErrValidation
+
+	ErrRolledBack = errors.New("rolled back")
+)
+
+// Error classifies an error from a lower layer as one of the service errors.
+// errors.Is matches both Kind and the wrapped error, and errors.As recovers
+// the Error to inspect its Kind.
+type Error struct {
+	Kind error
+	Err  error
+}
+
+// Error returns the message of the wrapped error
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the kind and the wrapped error
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// translate classifies repository errors as service errors, returning any
+// other error unchanged
+func translate(err error) error {
+	var serviceErr *Error
+	if err == nil || errors.As(err, &serviceErr) {
+		return err
+	}
+
+	var kind error
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		kind = ErrNotFound
+	case errors.Is(err, repository.ErrAlreadyExists):
+		kind = ErrConflict
+	case errors.Is(err, repository.ErrInvalidCursor), errors.Is(err, repository.ErrInvalidData):
+		kind = ErrValidation
+	case errors.Is(err, repository.ErrModified):
+		kind = ErrPreconditionFailed
+	case errors.Is(err, repository.ErrInternal):
+		kind = ErrInternal
+	default:
+		return err
+	}
+
+	return &Error{Kind: kind, Err: err}
+}
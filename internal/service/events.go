@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Example event types
+const (
+	EventExampleCreated = "example.created"
+	EventExampleUpdated = "example.updated"
+	EventExampleDeleted = "example.deleted"
+)
+
+// Event describes a change to an entity
+type Event struct {
+	Type      string    `json:"type"`
+	EntityID  string    `json:"entityId"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// EventPublisher publishes events, e.g. to a message bus
+type EventPublisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// NoopPublisher discards all events
+type NoopPublisher struct{}
+
+// Publish discards the event
+func (NoopPublisher) Publish(_ context.Context, _ Event) error {
+	return nil
+}
+
+// ChannelPublisher publishes events to an in-memory channel
+type ChannelPublisher struct {
+	events chan Event
+}
+
+// NewChannelPublisher creates a channel publisher with the given buffer size
+func NewChannelPublisher(buffer int) *ChannelPublisher {
+	return &ChannelPublisher{
+		events: make(chan Event, buffer),
+	}
+}
+
+// Publish sends the event on the channel, blocking until there is room or
+// the context is done
+func (p *ChannelPublisher) Publish(ctx context.Context, event Event) error {
+	select {
+	case p.events <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Events returns the channel events are published to
+func (p *ChannelPublisher) Events() <-chan Event {
+	return p.events
+}
+
+// Broadcaster publishes every event to each current subscriber, e.g. clients
+// watching for changes. A subscriber that falls more than its buffer behind
+// misses events rather than holding up writes.
+type Broadcaster struct {
+	buffer int
+
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+	closed      bool
+}
+
+// NewBroadcaster creates a broadcaster whose subscribers buffer up to buffer
+// events each
+func NewBroadcaster(buffer int) *Broadcaster {
+	return &Broadcaster{
+		buffer:      buffer,
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// Publish sends the event to every subscriber with room for it
+func (b *Broadcaster) Publish(_ context.Context, event Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+	return nil
+}
+
+// Subscribe returns a channel receiving events published from now on, and the
+// function that ends the subscription. The channel is closed when the
+// subscription ends or the broadcaster is closed, and is already closed if
+// the broadcaster was.
+func (b *Broadcaster) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, b.buffer)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	b.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Close ends every subscription, telling subscribers such as open streams to
+// finish, and refuses new ones. It is called on shutdown.
+func (b *Broadcaster) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for ch := range b.subscribers {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+// Subscribers returns the number of current subscribers
+func (b *Broadcaster) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.subscribers)
+}
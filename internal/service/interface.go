@@ -4,7 +4,9 @@ package service
 
 import (
 	"context"
+	"time"
 
+	"github.com/dBiTech/go-apiTemplate/internal/auth"
 	"github.com/dBiTech/go-apiTemplate/internal/models"
 )
 
@@ -12,16 +14,26 @@ import (
 type Interface interface {
 	// Examples
 	GetExample(ctx context.Context, id string) (*models.Example, error)
+	GetExamplesByIDs(ctx context.Context, ids []string) (map[string]*models.Example, error)
 	ListExamples(ctx context.Context, limit, offset int) ([]*models.Example, error)
+	CountExamples(ctx context.Context) (int, error)
+	SearchExamples(ctx context.Context, query string, limit int) ([]*models.Example, error)
+	ArchiveExamples(ctx context.Context, filter models.ArchiveFilter) (int, error)
+	ListExamplesAfter(ctx context.Context, cursor string, limit int) ([]*models.Example, string, error)
+	ExportExamples(ctx context.Context, fn func(*models.Example) error) error
 	CreateExample(ctx context.Context, req *models.ExampleRequest) (*models.Example, error)
 	UpdateExample(ctx context.Context, id string, req *models.ExampleRequest) (*models.Example, error)
+	UpdateExampleIfUnmodified(ctx context.Context, id string, req *models.ExampleRequest, version time.Time) (*models.Example, error)
+	UpsertExample(ctx context.Context, id string, req *models.ExampleRequest) (*models.Example, bool, error)
 	DeleteExample(ctx context.Context, id string) error
+	BulkCreateExamples(ctx context.Context, reqs []*models.ExampleRequest, atomic bool) ([]BulkResult, error)
+	ResetExamples(ctx context.Context) error
 
 	// Protected Resources
-	ListProtectedResources(ctx context.Context) ([]*models.ProtectedResource, error)
+	ListProtectedResources(ctx context.Context, limit, offset int, ownerID string) ([]*models.ProtectedResource, error)
 
 	// User Profile
-	GetUserProfile(ctx context.Context, userID string) (*models.UserProfile, error)
+	GetUserProfile(ctx context.Context, claims *auth.Claims) (*models.UserProfile, error)
 }
 
 // Ensure Service implements Interface
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/dBiTech/go-apiTemplate/internal/models"
+)
+
+// listCache caches ListExamples results for a short TTL and coalesces
+// concurrent identical queries into a single repository call. Callers get
+// their own copies of the cached examples.
+type listCache struct {
+	ttl   time.Duration
+	group singleflight.Group
+
+	mu         sync.Mutex
+	generation uint64
+	order      *list.List
+	entries    map[string]*list.Element
+}
+
+// listCacheEntry is a cached page of examples
+type listCacheEntry struct {
+	key       string
+	examples  []*models.Example
+	expiresAt time.Time
+}
+
+// newListCache creates a new list cache with the given TTL
+func newListCache(ttl time.Duration) *listCache {
+	return &listCache{
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// listCacheKey builds the cache key for a page of examples
+func listCacheKey(limit, offset int) string {
+	return strconv.Itoa(limit) + ":" + strconv.Itoa(offset)
+}
+
+// get returns the cached page for the given pagination params, calling load
+// at most once across concurrent callers when the page is missing or expired.
+// The shared load isn't cancelled with any one caller's ctx; each caller
+// stops waiting for it when its own ctx is done.
+func (c *listCache) get(
+	ctx context.Context,
+	limit, offset int,
+	load func(ctx context.Context) ([]*models.Example, error),
+) ([]*models.Example, error) {
+	key := listCacheKey(limit, offset)
+
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		if entry := elem.Value.(*listCacheEntry); time.Now().Before(entry.expiresAt) {
+			c.mu.Unlock()
+			return copyExamples(entry.examples), nil
+		}
+	}
+	generation := c.generation
+	c.mu.Unlock()
+
+	// Include the generation in the flight key so callers arriving after an
+	// invalidation never join a load that started before it
+	flightKey := key + "@" + strconv.FormatUint(generation, 10)
+
+	loadCtx := context.WithoutCancel(ctx)
+	ch := c.group.DoChan(flightKey, func() (interface{}, error) {
+		examples, err := load(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		c.mu.Lock()
+		if c.generation == generation {
+			c.add(key, examples)
+		}
+		c.mu.Unlock()
+
+		return examples, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-ch:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return copyExamples(result.Val.([]*models.Example)), nil
+	}
+}
+
+// add caches examples under key and evicts the expired entries. Every entry
+// lives for the same TTL, so the list stays in expiry order. The caller must
+// hold c.mu.
+func (c *listCache) add(key string, examples []*models.Example) {
+	now := time.Now()
+	for elem := c.order.Front(); elem != nil; elem = c.order.Front() {
+		entry := elem.Value.(*listCacheEntry)
+		if now.Before(entry.expiresAt) {
+			break
+		}
+		c.order.Remove(elem)
+		delete(c.entries, entry.key)
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		c.order.Remove(elem)
+	}
+	c.entries[key] = c.order.PushBack(&listCacheEntry{
+		key:       key,
+		examples:  examples,
+		expiresAt: now.Add(c.ttl),
+	})
+}
+
+// invalidate drops all cached pages
+func (c *listCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.order.Init()
+	c.entries = make(map[string]*list.Element)
+}
+
+// copyExamples returns a copy of every example in examples
+func copyExamples(examples []*models.Example) []*models.Example {
+	copies := make([]*models.Example, len(examples))
+	for i, example := range examples {
+		c := *example
+		copies[i] = &c
+	}
+	return copies
+}
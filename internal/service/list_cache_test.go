@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dBiTech/go-apiTemplate/internal/models"
+)
+
+func TestListCacheEviction(t *testing.T) {
+	c := newListCache(10 * time.Millisecond)
+	ctx := context.Background()
+	load := func(context.Context) ([]*models.Example, error) {
+		return []*models.Example{}, nil
+	}
+
+	for offset := 0; offset < 5; offset++ {
+		_, err := c.get(ctx, 10, offset, load)
+		require.NoError(t, err)
+	}
+	require.Len(t, c.entries, 5)
+
+	// Test expired pages are evicted when another page is cached
+	time.Sleep(20 * time.Millisecond)
+	_, err := c.get(ctx, 10, 5, load)
+	require.NoError(t, err)
+
+	assert.Len(t, c.entries, 1)
+	assert.Equal(t, 1, c.order.Len())
+}
@@ -2,36 +2,109 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/dBiTech/go-apiTemplate/internal/audit"
+	"github.com/dBiTech/go-apiTemplate/internal/auth"
 	"github.com/dBiTech/go-apiTemplate/internal/models"
 	"github.com/dBiTech/go-apiTemplate/internal/repository"
 	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+	"github.com/dBiTech/go-apiTemplate/pkg/metrics"
 	"github.com/dBiTech/go-apiTemplate/pkg/telemetry"
 )
 
+// auditResourceExample is the resource type of audit entries for examples
+const auditResourceExample = "example"
+
 // Service provides business logic operations
 type Service struct {
 	repo repository.Repository
 	log  logger.Logger
 	tel  *telemetry.Telemetry
+
+	examplesCreated *prometheus.CounterVec
+	listCache       *listCache
+	events          EventPublisher
+	auditor         audit.Auditor
+	uniqueNames     bool
+}
+
+// Option configures optional dependencies of a Service
+type Option func(*Service)
+
+// WithMetrics registers the service's business metrics against m
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(s *Service) {
+		s.examplesCreated = m.NewCounter("examples_created_total", "Total number of examples created.", nil)
+	}
+}
+
+// WithListCache enables a short-lived cache in front of ListExamples that
+// coalesces identical queries and is invalidated on any example mutation
+func WithListCache(ttl time.Duration) Option {
+	return func(s *Service) {
+		if ttl > 0 {
+			s.listCache = newListCache(ttl)
+		}
+	}
+}
+
+// WithEventPublisher publishes an event after every successful example write
+func WithEventPublisher(p EventPublisher) Option {
+	return func(s *Service) {
+		s.events = p
+	}
+}
+
+// WithAuditor records an audit entry after every successful example write
+func WithAuditor(a audit.Auditor) Option {
+	return func(s *Service) {
+		s.auditor = a
+	}
+}
+
+// WithUniqueNames rejects creating or renaming an example to a name that's
+// already taken with repository.ErrAlreadyExists. The check is made before the
+// write rather than atomically with it, so concurrent writes of the same name
+// can both succeed; a database repository should back it with a unique
+// constraint.
+func WithUniqueNames(enabled bool) Option {
+	return func(s *Service) {
+		s.uniqueNames = enabled
+	}
 }
 
-// New creates a new service instance
-func New(repo repository.Repository, log logger.Logger, tel *telemetry.Telemetry) *Service {
-	return &Service{
-		repo: repo,
-		log:  log,
-		tel:  tel,
+// New creates a new service instance. A nil tel traces with the global
+// OpenTelemetry tracer provider.
+func New(repo repository.Repository, log logger.Logger, tel *telemetry.Telemetry, opts ...Option) *Service {
+	s := &Service{
+		repo:    repo,
+		log:     log,
+		tel:     tel,
+		events:  NoopPublisher{},
+		auditor: audit.NoopAuditor{},
 	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
 }
 
 // GetExample gets an example by ID
 func (s *Service) GetExample(ctx context.Context, id string) (*models.Example, error) {
-	ctx, span := s.tel.Tracer("service").Start(ctx, "Service.GetExample")
+	ctx, span := s.tracer().Start(ctx, "Service.GetExample")
 	defer span.End()
 	span.SetAttributes(attribute.String("example.id", id))
 
@@ -41,48 +114,184 @@ func (s *Service) GetExample(ctx context.Context, id string) (*models.Example, e
 	if err != nil {
 		s.log.Error("failed to get example", logger.String("id", id), logger.Error(err))
 		span.RecordError(err)
-		return nil, err
+		return nil, translate(err)
 	}
 
 	return example, nil
 }
 
+// GetExamplesByIDs gets the examples with the given IDs, keyed by ID. IDs
+// that don't exist are left out.
+func (s *Service) GetExamplesByIDs(ctx context.Context, ids []string) (map[string]*models.Example, error) {
+	ctx, span := s.tracer().Start(ctx, "Service.GetExamplesByIDs")
+	defer span.End()
+	span.SetAttributes(attribute.Int("count", len(ids)))
+
+	s.log.Debug("getting examples by IDs", logger.Int("count", len(ids)))
+
+	examples, err := s.repo.GetExamplesByIDs(ctx, ids)
+	if err != nil {
+		s.log.Error("failed to get examples by IDs", logger.Error(err))
+		span.RecordError(err)
+		return nil, translate(err)
+	}
+
+	span.SetAttributes(attribute.Int("found", len(examples)))
+	return examples, nil
+}
+
+// SearchExamples finds up to limit examples matching query in their name or
+// description, most relevant first. An empty query is invalid.
+func (s *Service) SearchExamples(ctx context.Context, query string, limit int) ([]*models.Example, error) {
+	ctx, span := s.tracer().Start(ctx, "Service.SearchExamples")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("query", query),
+		attribute.Int("limit", limit),
+	)
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("%w: query is required", ErrInvalidRequest)
+	}
+
+	s.log.Debug("searching examples", logger.String("query", query), logger.Int("limit", limit))
+
+	examples, err := s.repo.SearchExamples(ctx, query, limit)
+	if err != nil {
+		s.log.Error("failed to search examples", logger.Error(err))
+		span.RecordError(err)
+		return nil, translate(err)
+	}
+
+	span.SetAttributes(attribute.Int("count", len(examples)))
+	return examples, nil
+}
+
 // ListExamples lists examples
 func (s *Service) ListExamples(ctx context.Context, limit, offset int) ([]*models.Example, error) {
-	ctx, span := s.tel.Tracer("service").Start(ctx, "Service.ListExamples")
+	ctx, span := s.tracer().Start(ctx, "Service.ListExamples")
 	defer span.End()
 	span.SetAttributes(attribute.Int("limit", limit), attribute.Int("offset", offset))
 
 	s.log.Debug("listing examples", logger.Int("limit", limit), logger.Int("offset", offset))
 
-	examples, err := s.repo.ListExamples(ctx, limit, offset)
+	var examples []*models.Example
+	var err error
+	if s.listCache != nil {
+		examples, err = s.listCache.get(ctx, limit, offset, func(ctx context.Context) ([]*models.Example, error) {
+			return s.repo.ListExamples(ctx, limit, offset)
+		})
+	} else {
+		examples, err = s.repo.ListExamples(ctx, limit, offset)
+	}
 	if err != nil {
 		s.log.Error("failed to list examples", logger.Error(err))
 		span.RecordError(err)
-		return nil, err
+		return nil, translate(err)
 	}
 
 	span.SetAttributes(attribute.Int("count", len(examples)))
 	return examples, nil
 }
 
+// CountExamples returns the number of examples across all pages
+func (s *Service) CountExamples(ctx context.Context) (int, error) {
+	ctx, span := s.tracer().Start(ctx, "Service.CountExamples")
+	defer span.End()
+
+	count, err := s.repo.CountExamples(ctx)
+	if err != nil {
+		s.log.Error("failed to count examples", logger.Error(err))
+		span.RecordError(err)
+		return 0, translate(err)
+	}
+
+	span.SetAttributes(attribute.Int("count", count))
+	return count, nil
+}
+
+// ListExamplesAfter lists examples in creation order starting after a cursor
+func (s *Service) ListExamplesAfter(ctx context.Context, cursor string, limit int) ([]*models.Example, string, error) {
+	ctx, span := s.tracer().Start(ctx, "Service.ListExamplesAfter")
+	defer span.End()
+	span.SetAttributes(attribute.String("cursor", cursor), attribute.Int("limit", limit))
+
+	s.log.Debug("listing examples after cursor", logger.String("cursor", cursor), logger.Int("limit", limit))
+
+	examples, nextCursor, err := s.repo.ListExamplesAfter(ctx, cursor, limit)
+	if err != nil {
+		s.log.Error("failed to list examples", logger.Error(err))
+		span.RecordError(err)
+		return nil, "", translate(err)
+	}
+
+	span.SetAttributes(attribute.Int("count", len(examples)))
+	return examples, nextCursor, nil
+}
+
+// ExportExamples calls fn for every example in creation order without
+// loading them all at once. It stops at the first error from fn or when ctx is done.
+func (s *Service) ExportExamples(ctx context.Context, fn func(*models.Example) error) error {
+	ctx, span := s.tracer().Start(ctx, "Service.ExportExamples")
+	defer span.End()
+
+	s.log.Debug("exporting examples")
+
+	count := 0
+	err := s.repo.IterateExamples(ctx, func(example *models.Example) error {
+		count++
+		return fn(example)
+	})
+	span.SetAttributes(attribute.Int("count", count))
+	if err != nil {
+		s.log.Warn("export stopped early", logger.Int("count", count), logger.Error(err))
+		span.RecordError(err)
+		return translate(err)
+	}
+
+	return nil
+}
+
 // CreateExample creates a new example
 func (s *Service) CreateExample(ctx context.Context, req *models.ExampleRequest) (*models.Example, error) {
-	ctx, span := s.tel.Tracer("service").Start(ctx, "Service.CreateExample")
+	ctx, span := s.tracer().Start(ctx, "Service.CreateExample")
 	defer span.End()
 	span.SetAttributes(attribute.String("example.name", req.Name))
 
 	s.log.Debug("creating example", logger.String("name", req.Name))
 
+	if err := validateStatus(req.Status); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
 	// Generate a new UUID
 	id := uuid.New().String()
 
 	example := models.NewExample(id, req.Name, req.Description)
+	if req.Status != "" {
+		example.Status = req.Status
+	}
+
+	if err := s.checkNameAvailable(ctx, req.Name); err != nil {
+		s.log.Debug("example name unavailable", logger.String("name", req.Name), logger.Error(err))
+		span.RecordError(err)
+		return nil, translate(err)
+	}
 
 	if err := s.repo.CreateExample(ctx, example); err != nil {
 		s.log.Error("failed to create example", logger.String("name", req.Name), logger.Error(err))
 		span.RecordError(err)
-		return nil, err
+		return nil, translate(err)
+	}
+
+	s.invalidateListCache()
+	s.publish(ctx, EventExampleCreated, example.ID)
+	s.audit(ctx, audit.ActionCreate, example.ID)
+
+	if s.examplesCreated != nil {
+		s.examplesCreated.WithLabelValues().Inc()
 	}
 
 	span.SetAttributes(attribute.String("example.id", example.ID))
@@ -91,7 +300,20 @@ func (s *Service) CreateExample(ctx context.Context, req *models.ExampleRequest)
 
 // UpdateExample updates an existing example
 func (s *Service) UpdateExample(ctx context.Context, id string, req *models.ExampleRequest) (*models.Example, error) {
-	ctx, span := s.tel.Tracer("service").Start(ctx, "Service.UpdateExample")
+	return s.updateExample(ctx, "Service.UpdateExample", id, req, nil)
+}
+
+// UpdateExampleIfUnmodified updates an existing example only if it was last
+// updated at version, failing with ErrPreconditionFailed otherwise. The
+// check and the write are atomic, so concurrent updates can't both succeed.
+func (s *Service) UpdateExampleIfUnmodified(ctx context.Context, id string, req *models.ExampleRequest, version time.Time) (*models.Example, error) {
+	return s.updateExample(ctx, "Service.UpdateExampleIfUnmodified", id, req, &version)
+}
+
+// updateExample updates an existing example, conditionally on its version
+// when version is set
+func (s *Service) updateExample(ctx context.Context, spanName, id string, req *models.ExampleRequest, version *time.Time) (*models.Example, error) {
+	ctx, span := s.tracer().Start(ctx, spanName)
 	defer span.End()
 	span.SetAttributes(
 		attribute.String("example.id", id),
@@ -103,31 +325,124 @@ func (s *Service) UpdateExample(ctx context.Context, id string, req *models.Exam
 		logger.String("name", req.Name),
 	)
 
+	if err := validateStatus(req.Status); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
 	// Get existing example
 	example, err := s.repo.GetExample(ctx, id)
 	if err != nil {
 		s.log.Error("failed to get example for update", logger.String("id", id), logger.Error(err))
 		span.RecordError(err)
-		return nil, err
+		return nil, translate(err)
+	}
+	if version != nil && !example.UpdatedAt.Equal(*version) {
+		return nil, translate(repository.ErrModified)
+	}
+
+	if err := s.checkNameAvailableFor(ctx, req.Name, id); err != nil {
+		s.log.Debug("example name unavailable", logger.String("name", req.Name), logger.Error(err))
+		span.RecordError(err)
+		return nil, translate(err)
 	}
 
 	// Update fields
 	example.Name = req.Name
 	example.Description = req.Description
+	if req.Status != "" {
+		example.Status = req.Status
+	}
 	example.UpdatedAt = time.Now()
 
-	if err := s.repo.UpdateExample(ctx, example); err != nil {
+	if version != nil {
+		err = s.repo.UpdateExampleIfUnmodified(ctx, example, *version)
+	} else {
+		err = s.repo.UpdateExample(ctx, example)
+	}
+	if err != nil {
 		s.log.Error("failed to update example", logger.String("id", id), logger.Error(err))
 		span.RecordError(err)
-		return nil, err
+		return nil, translate(err)
 	}
 
+	s.invalidateListCache()
+	s.publish(ctx, EventExampleUpdated, example.ID)
+	s.audit(ctx, audit.ActionUpdate, example.ID)
+
 	return example, nil
 }
 
+// UpsertExample updates the example with the given ID, creating it if it
+// doesn't exist. It reports whether the example was created.
+func (s *Service) UpsertExample(ctx context.Context, id string, req *models.ExampleRequest) (*models.Example, bool, error) {
+	ctx, span := s.tracer().Start(ctx, "Service.UpsertExample")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("example.id", id),
+		attribute.String("example.name", req.Name),
+	)
+
+	s.log.Debug("upserting example",
+		logger.String("id", id),
+		logger.String("name", req.Name),
+	)
+
+	if err := validateStatus(req.Status); err != nil {
+		span.RecordError(err)
+		return nil, false, err
+	}
+
+	if err := s.checkNameAvailableFor(ctx, req.Name, id); err != nil {
+		s.log.Debug("example name unavailable", logger.String("name", req.Name), logger.Error(err))
+		span.RecordError(err)
+		return nil, false, translate(err)
+	}
+
+	// Start from the stored example so fields the request leaves out, such
+	// as the status, are kept
+	example, err := s.repo.GetExample(ctx, id)
+	switch {
+	case err == nil:
+		example.Name = req.Name
+		example.Description = req.Description
+	case errors.Is(err, repository.ErrNotFound):
+		example = models.NewExample(id, req.Name, req.Description)
+	default:
+		s.log.Error("failed to get example for upsert", logger.String("id", id), logger.Error(err))
+		span.RecordError(err)
+		return nil, false, translate(err)
+	}
+	if req.Status != "" {
+		example.Status = req.Status
+	}
+
+	created, err := s.repo.UpsertExample(ctx, example)
+	if err != nil {
+		s.log.Error("failed to upsert example", logger.String("id", id), logger.Error(err))
+		span.RecordError(err)
+		return nil, false, translate(err)
+	}
+
+	s.invalidateListCache()
+	if created {
+		s.publish(ctx, EventExampleCreated, example.ID)
+		s.audit(ctx, audit.ActionCreate, example.ID)
+		if s.examplesCreated != nil {
+			s.examplesCreated.WithLabelValues().Inc()
+		}
+	} else {
+		s.publish(ctx, EventExampleUpdated, example.ID)
+		s.audit(ctx, audit.ActionUpdate, example.ID)
+	}
+
+	span.SetAttributes(attribute.Bool("example.created", created))
+	return example, created, nil
+}
+
 // DeleteExample deletes an example
 func (s *Service) DeleteExample(ctx context.Context, id string) error {
-	ctx, span := s.tel.Tracer("service").Start(ctx, "Service.DeleteExample")
+	ctx, span := s.tracer().Start(ctx, "Service.DeleteExample")
 	defer span.End()
 	span.SetAttributes(attribute.String("example.id", id))
 
@@ -136,27 +451,188 @@ func (s *Service) DeleteExample(ctx context.Context, id string) error {
 	if err := s.repo.DeleteExample(ctx, id); err != nil {
 		s.log.Error("failed to delete example", logger.String("id", id), logger.Error(err))
 		span.RecordError(err)
-		return err
+		return translate(err)
 	}
 
+	s.invalidateListCache()
+	s.publish(ctx, EventExampleDeleted, id)
+	s.audit(ctx, audit.ActionDelete, id)
+
 	return nil
 }
 
-// GetUserProfile gets a user profile by ID
-func (s *Service) GetUserProfile(ctx context.Context, userID string) (*models.UserProfile, error) {
-	_, span := s.tel.Tracer("service").Start(ctx, "Service.GetUserProfile")
+// ResetExamples removes all examples. It fails with repository.ErrNotResettable
+// if the repository doesn't support it.
+func (s *Service) ResetExamples(ctx context.Context) error {
+	ctx, span := s.tracer().Start(ctx, "Service.ResetExamples")
 	defer span.End()
-	span.SetAttributes(attribute.String("user.id", userID))
 
-	s.log.Debug("getting user profile", logger.String("userID", userID))
+	s.log.Warn("resetting all examples")
 
-	// This is a mock implementation. In a real app, you would fetch from a database
+	resettable, ok := s.repo.(repository.Resettable)
+	if !ok {
+		return repository.ErrNotResettable
+	}
+
+	if err := resettable.Reset(ctx); err != nil {
+		s.log.Error("failed to reset examples", logger.Error(err))
+		span.RecordError(err)
+		return translate(err)
+	}
+
+	s.invalidateListCache()
+	s.audit(ctx, audit.ActionReset, "")
+
+	return nil
+}
+
+// ArchiveExamples archives every example matching filter and returns how
+// many were archived. The filter must set a status, a creation cutoff or
+// both. Each archived example is published as updated and audited.
+func (s *Service) ArchiveExamples(ctx context.Context, filter models.ArchiveFilter) (int, error) {
+	ctx, span := s.tracer().Start(ctx, "Service.ArchiveExamples")
+	defer span.End()
+	span.SetAttributes(attribute.String("filter.status", filter.Status))
+	if !filter.OlderThan.IsZero() {
+		span.SetAttributes(attribute.String("filter.olderThan", filter.OlderThan.Format(time.RFC3339)))
+	}
+
+	s.log.Debug("archiving examples", logger.String("status", filter.Status))
+
+	if err := validateArchiveFilter(filter); err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+
+	ids, err := s.repo.ArchiveExamples(ctx, filter)
+	if err != nil {
+		s.log.Error("failed to archive examples", logger.Error(err))
+		span.RecordError(err)
+		return 0, translate(err)
+	}
+
+	span.SetAttributes(attribute.Int("count", len(ids)))
+	if len(ids) > 0 {
+		s.invalidateListCache()
+	}
+	for _, id := range ids {
+		s.publish(ctx, EventExampleUpdated, id)
+		s.audit(ctx, audit.ActionArchive, id)
+	}
+
+	return len(ids), nil
+}
+
+// validateArchiveFilter checks that a filter selects examples by at least one
+// criterion and that its status can be archived
+func validateArchiveFilter(filter models.ArchiveFilter) error {
+	if filter.Status == "" && filter.OlderThan.IsZero() {
+		return fmt.Errorf("%w: filter needs a status or olderThan", ErrInvalidRequest)
+	}
+	if filter.Status != "" && filter.Status != models.StatusActive && filter.Status != models.StatusInactive {
+		return fmt.Errorf("%w: status must be %s or %s", ErrInvalidRequest, models.StatusActive, models.StatusInactive)
+	}
+	return nil
+}
+
+// validateStatus checks that a requested status is empty, leaving the
+// default, or one of models.ExampleStatuses
+func validateStatus(status string) error {
+	if status != "" && !models.ValidExampleStatus(status) {
+		return fmt.Errorf("%w: status must be one of %s", ErrInvalidRequest, strings.Join(models.ExampleStatuses, ", "))
+	}
+	return nil
+}
+
+// checkNameAvailable returns repository.ErrAlreadyExists if unique names are
+// enforced and an example with name exists. The check isn't atomic with the
+// create that follows; a database implementation should back it with a
+// unique constraint.
+func (s *Service) checkNameAvailable(ctx context.Context, name string) error {
+	return s.checkNameAvailableFor(ctx, name, "")
+}
+
+// checkNameAvailableFor is like checkNameAvailable but allows the name to be
+// held by the example with the given ID
+func (s *Service) checkNameAvailableFor(ctx context.Context, name, id string) error {
+	if !s.uniqueNames {
+		return nil
+	}
+
+	existing, err := s.repo.GetExampleByName(ctx, name)
+	switch {
+	case err == nil:
+		if id != "" && existing.ID == id {
+			return nil
+		}
+		return repository.ErrAlreadyExists
+	case errors.Is(err, repository.ErrNotFound):
+		return nil
+	default:
+		return err
+	}
+}
+
+// tracer returns the service's tracer, falling back to the global one when
+// the service was created without telemetry
+func (s *Service) tracer() trace.Tracer {
+	if s.tel == nil {
+		return otel.Tracer("service")
+	}
+	return s.tel.Tracer("service")
+}
+
+// invalidateListCache drops cached list results after an example mutation
+func (s *Service) invalidateListCache() {
+	if s.listCache != nil {
+		s.listCache.invalidate()
+	}
+}
+
+// publish publishes an event for the entity, logging rather than failing if
+// the publisher errors since the write has already succeeded
+func (s *Service) publish(ctx context.Context, eventType, entityID string) {
+	event := Event{
+		Type:      eventType,
+		EntityID:  entityID,
+		Timestamp: time.Now(),
+	}
+
+	if err := s.events.Publish(ctx, event); err != nil {
+		s.log.Warn("failed to publish event",
+			logger.String("type", eventType),
+			logger.String("id", entityID),
+			logger.Error(err),
+		)
+	}
+}
+
+// audit records an audit entry for action on the example with the given ID
+func (s *Service) audit(ctx context.Context, action, id string) {
+	s.auditor.Record(ctx, audit.NewEntry(ctx, action, auditResourceExample, id))
+}
+
+// GetUserProfile builds the profile of the user identified by claims. Roles and
+// scopes come from the token; remaining fields are looked up separately.
+func (s *Service) GetUserProfile(ctx context.Context, claims *auth.Claims) (*models.UserProfile, error) {
+	if claims == nil || claims.UserID == "" {
+		return nil, ErrInvalidRequest
+	}
+
+	_, span := s.tracer().Start(ctx, "Service.GetUserProfile")
+	defer span.End()
+	span.SetAttributes(attribute.String("user.id", claims.UserID))
+
+	s.log.Debug("getting user profile", logger.String("userID", claims.UserID))
+
+	// This is a mock lookup for fields the token doesn't carry. In a real app,
+	// you would fetch these from a database
 	profile := &models.UserProfile{
-		ID:       userID,
-		Username: "user" + userID,
-		Email:    "user" + userID + "@example.com",
-		Roles:    []string{"user"},
-		Scopes:   []string{"read", "write"},
+		ID:       claims.UserID,
+		Username: "user" + claims.UserID,
+		Email:    "user" + claims.UserID + "@example.com",
+		Roles:    claims.Roles,
+		Scopes:   claims.Scopes,
 	}
 
 	return profile, nil
@@ -164,7 +640,7 @@ func (s *Service) GetUserProfile(ctx context.Context, userID string) (*models.Us
 
 // GetProtectedResource gets a protected resource by ID
 func (s *Service) GetProtectedResource(ctx context.Context, id string) (*models.ProtectedResource, error) {
-	_, span := s.tel.Tracer("service").Start(ctx, "Service.GetProtectedResource")
+	_, span := s.tracer().Start(ctx, "Service.GetProtectedResource")
 	defer span.End()
 	span.SetAttributes(attribute.String("resource.id", id))
 
@@ -182,30 +658,76 @@ func (s *Service) GetProtectedResource(ctx context.Context, id string) (*models.
 	return resource, nil
 }
 
-// ListProtectedResources lists protected resources
-func (s *Service) ListProtectedResources(ctx context.Context) ([]*models.ProtectedResource, error) {
-	_, span := s.tel.Tracer("service").Start(ctx, "Service.ListProtectedResources")
+// ListProtectedResources lists up to limit protected resources starting at
+// offset, optionally only those owned by ownerID. Only resources whose scope
+// is among the caller's scopes in ctx are listed, so a caller without scopes
+// sees none. A limit of 0 or less returns all remaining resources, and a
+// negative offset fails with ErrInvalidRequest.
+func (s *Service) ListProtectedResources(ctx context.Context, limit, offset int, ownerID string) ([]*models.ProtectedResource, error) {
+	_, span := s.tracer().Start(ctx, "Service.ListProtectedResources")
 	defer span.End()
+	span.SetAttributes(
+		attribute.Int("limit", limit),
+		attribute.Int("offset", offset),
+		attribute.String("owner.id", ownerID),
+	)
 
-	s.log.Debug("listing protected resources")
+	if offset < 0 {
+		err := fmt.Errorf("%w: offset must not be negative", ErrInvalidRequest)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	s.log.Debug("listing protected resources",
+		logger.Int("limit", limit),
+		logger.Int("offset", offset),
+		logger.String("ownerID", ownerID),
+	)
 
 	// This is a mock implementation. In a real app, you would fetch from a database
-	resources := []*models.ProtectedResource{
+	all := []*models.ProtectedResource{
 		{
-			ID:        uuid.New().String(),
+			ID:        "protected-resource-1",
 			Name:      "Protected Resource 1",
 			Content:   "This is protected resource 1.",
 			CreatedAt: time.Now(),
 			OwnerID:   "user123",
+			Scope:     "read",
 		},
 		{
-			ID:        uuid.New().String(),
+			ID:        "protected-resource-2",
 			Name:      "Protected Resource 2",
 			Content:   "This is protected resource 2.",
 			CreatedAt: time.Now(),
 			OwnerID:   "user456",
+			Scope:     "read",
+		},
+		{
+			ID:        "protected-resource-3",
+			Name:      "Protected Resource 3",
+			Content:   "This is protected resource 3, for admins only.",
+			CreatedAt: time.Now(),
+			OwnerID:   "user123",
+			Scope:     "admin",
 		},
 	}
 
+	scopes, _ := auth.GetScopes(ctx)
+
+	resources := make([]*models.ProtectedResource, 0, len(all))
+	for _, resource := range all {
+		if (ownerID == "" || resource.OwnerID == ownerID) && slices.Contains(scopes, resource.Scope) {
+			resources = append(resources, resource)
+		}
+	}
+
+	if offset >= len(resources) {
+		return []*models.ProtectedResource{}, nil
+	}
+	resources = resources[offset:]
+	if limit > 0 && limit < len(resources) {
+		resources = resources[:limit]
+	}
+
 	return resources, nil
 }
@@ -2,17 +2,27 @@ package service_test
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/dBiTech/go-apiTemplate/internal/audit"
+	"github.com/dBiTech/go-apiTemplate/internal/auth"
+	"github.com/dBiTech/go-apiTemplate/internal/middleware"
 	"github.com/dBiTech/go-apiTemplate/internal/models"
 	"github.com/dBiTech/go-apiTemplate/internal/repository"
 	"github.com/dBiTech/go-apiTemplate/internal/service"
 	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+	"github.com/dBiTech/go-apiTemplate/pkg/metrics"
 	"github.com/dBiTech/go-apiTemplate/pkg/telemetry"
 )
 
@@ -29,6 +39,38 @@ func (m *MockRepository) GetExample(_ context.Context, id string) (*models.Examp
 	return args.Get(0).(*models.Example), args.Error(1)
 }
 
+func (m *MockRepository) GetExampleByName(_ context.Context, name string) (*models.Example, error) {
+	args := m.Called(mock.Anything, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Example), args.Error(1)
+}
+
+func (m *MockRepository) GetExamplesByIDs(_ context.Context, ids []string) (map[string]*models.Example, error) {
+	args := m.Called(mock.Anything, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*models.Example), args.Error(1)
+}
+
+func (m *MockRepository) ArchiveExamples(_ context.Context, filter models.ArchiveFilter) ([]string, error) {
+	args := m.Called(mock.Anything, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockRepository) SearchExamples(_ context.Context, query string, limit int) ([]*models.Example, error) {
+	args := m.Called(mock.Anything, query, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Example), args.Error(1)
+}
+
 func (m *MockRepository) ListExamples(_ context.Context, limit, offset int) ([]*models.Example, error) {
 	args := m.Called(mock.Anything, limit, offset)
 	if args.Get(0) == nil {
@@ -37,6 +79,31 @@ func (m *MockRepository) ListExamples(_ context.Context, limit, offset int) ([]*
 	return args.Get(0).([]*models.Example), args.Error(1)
 }
 
+func (m *MockRepository) CountExamples(_ context.Context) (int, error) {
+	args := m.Called(mock.Anything)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) ListExamplesAfter(_ context.Context, cursor string, limit int) ([]*models.Example, string, error) {
+	args := m.Called(mock.Anything, cursor, limit)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).([]*models.Example), args.String(1), args.Error(2)
+}
+
+func (m *MockRepository) IterateExamples(_ context.Context, fn func(*models.Example) error) error {
+	args := m.Called(mock.Anything, mock.Anything)
+	if examples, ok := args.Get(0).([]*models.Example); ok {
+		for _, example := range examples {
+			if err := fn(example); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
 func (m *MockRepository) CreateExample(_ context.Context, example *models.Example) error {
 	args := m.Called(mock.Anything, example)
 	return args.Error(0)
@@ -47,6 +114,16 @@ func (m *MockRepository) UpdateExample(_ context.Context, example *models.Exampl
 	return args.Error(0)
 }
 
+func (m *MockRepository) UpdateExampleIfUnmodified(_ context.Context, example *models.Example, version time.Time) error {
+	args := m.Called(mock.Anything, example, version)
+	return args.Error(0)
+}
+
+func (m *MockRepository) UpsertExample(_ context.Context, example *models.Example) (bool, error) {
+	args := m.Called(mock.Anything, example)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockRepository) DeleteExample(_ context.Context, id string) error {
 	args := m.Called(mock.Anything, id)
 	return args.Error(0)
@@ -106,7 +183,8 @@ func TestService(t *testing.T) {
 
 		// Assert expectations
 		require.Error(t, err)
-		assert.Equal(t, repository.ErrNotFound, err)
+		assert.ErrorIs(t, err, service.ErrNotFound)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
 		assert.Nil(t, result)
 		mockRepo.AssertExpectations(t)
 	})
@@ -131,6 +209,17 @@ func TestService(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	// Test CountExamples
+	t.Run("CountExamples", func(t *testing.T) {
+		mockRepo.On("CountExamples", mock.Anything).Return(2, nil).Once()
+
+		count, err := svc.CountExamples(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+		mockRepo.AssertExpectations(t)
+	})
+
 	// Test CreateExample
 	t.Run("CreateExample", func(t *testing.T) {
 		req := &models.ExampleRequest{
@@ -196,3 +285,1120 @@ func TestService(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestNilTelemetry(t *testing.T) {
+	log := logger.Default()
+	ctx := context.Background()
+
+	repo := repository.NewMemoryRepository(log)
+	svc := service.New(repo, log, nil)
+
+	example := models.NewExample(uuid.New().String(), "Example", "")
+	require.NoError(t, repo.CreateExample(ctx, example))
+
+	// Test the service traces with the global tracer instead of panicking
+	require.NotPanics(t, func() {
+		got, err := svc.GetExample(ctx, example.ID)
+		require.NoError(t, err)
+		assert.Equal(t, example.ID, got.ID)
+	})
+}
+
+func TestServiceMetrics(t *testing.T) {
+	log := logger.Default()
+
+	tel, err := telemetry.New(context.Background(), telemetry.Config{Enabled: false}, log)
+	require.NoError(t, err)
+
+	m := metrics.NewMetrics("test", metrics.WithGoCollector(false), metrics.WithProcessCollector(false))
+
+	mockRepo := new(MockRepository)
+	svc := service.New(mockRepo, log, tel, service.WithMetrics(m))
+
+	mockRepo.On("CreateExample", mock.Anything, mock.Anything).Return(nil)
+
+	_, err = svc.CreateExample(context.Background(), &models.ExampleRequest{Name: "Counted Example"})
+	require.NoError(t, err)
+
+	// Scrape the metrics endpoint
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, req)
+
+	assert.Contains(t, w.Body.String(), "test_examples_created_total 1")
+}
+
+func TestServiceListCache(t *testing.T) {
+	log := logger.Default()
+
+	tel, err := telemetry.New(context.Background(), telemetry.Config{Enabled: false}, log)
+	require.NoError(t, err)
+
+	mockRepo := new(MockRepository)
+	svc := service.New(mockRepo, log, tel, service.WithListCache(time.Minute))
+
+	ctx := context.Background()
+	expected := []*models.Example{
+		{BaseModel: models.BaseModel{ID: uuid.New().String()}, Name: "Example 1"},
+	}
+
+	mockRepo.On("ListExamples", mock.Anything, 10, 0).Return(expected, nil)
+	mockRepo.On("ListExamples", mock.Anything, 10, 10).Return([]*models.Example{}, nil)
+	mockRepo.On("CreateExample", mock.Anything, mock.Anything).Return(nil)
+
+	// Test repeated identical queries hit the repository once
+	t.Run("IdenticalQueries", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			result, err := svc.ListExamples(ctx, 10, 0)
+			require.NoError(t, err)
+			assert.Equal(t, expected, result)
+		}
+
+		mockRepo.AssertNumberOfCalls(t, "ListExamples", 1)
+	})
+
+	// Test pagination params are part of the cache key
+	t.Run("DifferentPagination", func(t *testing.T) {
+		_, err := svc.ListExamples(ctx, 10, 10)
+		require.NoError(t, err)
+
+		mockRepo.AssertNumberOfCalls(t, "ListExamples", 2)
+	})
+
+	// Test a create invalidates the cache
+	t.Run("CreateInvalidates", func(t *testing.T) {
+		_, err := svc.CreateExample(ctx, &models.ExampleRequest{Name: "New Example"})
+		require.NoError(t, err)
+
+		_, err = svc.ListExamples(ctx, 10, 0)
+		require.NoError(t, err)
+
+		mockRepo.AssertNumberOfCalls(t, "ListExamples", 3)
+	})
+
+	// Test modifying a returned example doesn't change the cached copy
+	t.Run("ReturnsCopies", func(t *testing.T) {
+		result, err := svc.ListExamples(ctx, 10, 0)
+		require.NoError(t, err)
+		result[0].Name = "Modified"
+
+		cached, err := svc.ListExamples(ctx, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, "Example 1", cached[0].Name)
+		mockRepo.AssertNumberOfCalls(t, "ListExamples", 3)
+	})
+
+	// Test concurrent identical queries are coalesced
+	t.Run("ConcurrentQueries", func(t *testing.T) {
+		slowRepo := new(MockRepository)
+		slowSvc := service.New(slowRepo, log, tel, service.WithListCache(time.Minute))
+
+		slowRepo.On("ListExamples", mock.Anything, 5, 0).
+			After(50*time.Millisecond).
+			Return(expected, nil)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := slowSvc.ListExamples(ctx, 5, 0)
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		slowRepo.AssertNumberOfCalls(t, "ListExamples", 1)
+	})
+
+	// Test cancelling the caller that started a load doesn't fail the
+	// callers that joined it
+	t.Run("LeaderCancelled", func(t *testing.T) {
+		blocking := &blockingListRepository{
+			Repository: repository.NewMemoryRepository(log),
+			started:    make(chan struct{}, 1),
+			release:    make(chan struct{}),
+		}
+		blockingSvc := service.New(blocking, log, tel, service.WithListCache(time.Minute))
+
+		leaderCtx, cancel := context.WithCancel(ctx)
+		leaderErr := make(chan error)
+		go func() {
+			_, err := blockingSvc.ListExamples(leaderCtx, 5, 0)
+			leaderErr <- err
+		}()
+		<-blocking.started
+
+		followerErr := make(chan error)
+		go func() {
+			_, err := blockingSvc.ListExamples(ctx, 5, 0)
+			followerErr <- err
+		}()
+		time.Sleep(20 * time.Millisecond)
+
+		cancel()
+		assert.ErrorIs(t, <-leaderErr, context.Canceled)
+
+		close(blocking.release)
+		assert.NoError(t, <-followerErr)
+	})
+}
+
+// blockingListRepository blocks ListExamples until release is closed or ctx
+// is done, signalling started when a call begins
+type blockingListRepository struct {
+	repository.Repository
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *blockingListRepository) ListExamples(ctx context.Context, limit, offset int) ([]*models.Example, error) {
+	select {
+	case r.started <- struct{}{}:
+	default:
+	}
+
+	select {
+	case <-r.release:
+		return r.Repository.ListExamples(ctx, limit, offset)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestServiceConcurrentUpdates(t *testing.T) {
+	log := logger.Default()
+
+	tel, err := telemetry.New(context.Background(), telemetry.Config{
+		ServiceName: "test-service",
+		Enabled:     false,
+	}, log)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	svc := service.New(repository.NewMemoryRepository(log), log, tel)
+
+	example, err := svc.CreateExample(ctx, &models.ExampleRequest{Name: "Example"})
+	require.NoError(t, err)
+
+	// Test updating while listing and getting doesn't race on the stored
+	// example; run with -race to check
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				_, err := svc.UpdateExample(ctx, example.ID, &models.ExampleRequest{
+					Name:        example.Name,
+					Description: fmt.Sprintf("update %d-%d", w, i),
+				})
+				assert.NoError(t, err)
+			}
+		}(w)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				examples, err := svc.ListExamples(ctx, 0, 0)
+				assert.NoError(t, err)
+				for _, e := range examples {
+					_ = e.Description + e.UpdatedAt.String()
+				}
+
+				got, err := svc.GetExample(ctx, example.ID)
+				assert.NoError(t, err)
+				_ = got.Description
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestUpdateExampleIfUnmodified(t *testing.T) {
+	log := logger.Default()
+
+	tel, err := telemetry.New(context.Background(), telemetry.Config{
+		ServiceName: "test-service",
+		Enabled:     false,
+	}, log)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	// Test the update applies at the current version and not at an older one
+	t.Run("Version", func(t *testing.T) {
+		svc := service.New(repository.NewMemoryRepository(log), log, tel)
+		example, err := svc.CreateExample(ctx, &models.ExampleRequest{Name: "Example"})
+		require.NoError(t, err)
+		version := example.UpdatedAt
+
+		updated, err := svc.UpdateExampleIfUnmodified(ctx, example.ID, &models.ExampleRequest{Name: "First"}, version)
+		require.NoError(t, err)
+		assert.True(t, updated.UpdatedAt.After(version))
+
+		_, err = svc.UpdateExampleIfUnmodified(ctx, example.ID, &models.ExampleRequest{Name: "Second"}, version)
+		assert.ErrorIs(t, err, service.ErrPreconditionFailed)
+
+		current, err := svc.GetExample(ctx, example.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "First", current.Name)
+	})
+
+	// Test only one of several concurrent updates at the same version wins
+	t.Run("Concurrent", func(t *testing.T) {
+		svc := service.New(repository.NewMemoryRepository(log), log, tel)
+		example, err := svc.CreateExample(ctx, &models.ExampleRequest{Name: "Example"})
+		require.NoError(t, err)
+
+		const updaters = 8
+		errs := make(chan error, updaters)
+		var wg sync.WaitGroup
+		for i := 0; i < updaters; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, err := svc.UpdateExampleIfUnmodified(ctx, example.ID, &models.ExampleRequest{
+					Name: fmt.Sprintf("Update %d", i),
+				}, example.UpdatedAt)
+				errs <- err
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+
+		succeeded := 0
+		for err := range errs {
+			if err == nil {
+				succeeded++
+			} else {
+				assert.ErrorIs(t, err, service.ErrPreconditionFailed)
+			}
+		}
+		assert.Equal(t, 1, succeeded)
+	})
+}
+
+func TestBulkCreateExamples(t *testing.T) {
+	log := logger.Default()
+
+	tel, err := telemetry.New(context.Background(), telemetry.Config{
+		ServiceName: "test-service",
+		Enabled:     false,
+	}, log)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	reqs := []*models.ExampleRequest{
+		{Name: "First Example"},
+		{Name: "Second Example"},
+		{Name: "Third Example"},
+	}
+
+	// Test every item is created
+	t.Run("AllSuccess", func(t *testing.T) {
+		repo := repository.NewMemoryRepository(log)
+		svc := service.New(repo, log, tel)
+
+		results, err := svc.BulkCreateExamples(ctx, reqs, false)
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+
+		for i, result := range results {
+			require.NoError(t, result.Err)
+			assert.Equal(t, reqs[i].Name, result.Example.Name)
+		}
+
+		examples, err := repo.ListExamples(ctx, 0, 0)
+		require.NoError(t, err)
+		assert.Len(t, examples, 3)
+	})
+
+	// Test best-effort mode creates the valid items and reports the rest
+	t.Run("PartialFailure", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.New(mockRepo, log, tel)
+
+		mockRepo.On("CreateExample", mock.Anything, mock.MatchedBy(func(e *models.Example) bool {
+			return e.Name == "First Example"
+		})).Return(nil)
+		mockRepo.On("CreateExample", mock.Anything, mock.MatchedBy(func(e *models.Example) bool {
+			return e.Name == "Third Example"
+		})).Return(repository.ErrAlreadyExists)
+
+		results, err := svc.BulkCreateExamples(ctx, []*models.ExampleRequest{
+			{Name: "First Example"},
+			{Name: ""},
+			{Name: "Third Example"},
+		}, false)
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+
+		require.NoError(t, results[0].Err)
+		assert.Equal(t, "First Example", results[0].Example.Name)
+		assert.ErrorIs(t, results[1].Err, service.ErrInvalidRequest)
+		assert.ErrorIs(t, results[2].Err, repository.ErrAlreadyExists)
+		assert.Nil(t, results[2].Example)
+
+		mockRepo.AssertNumberOfCalls(t, "CreateExample", 2)
+		mockRepo.AssertNotCalled(t, "DeleteExample", mock.Anything, mock.Anything)
+	})
+
+	// Test items are held to the length rules of a single create
+	t.Run("InvalidLength", func(t *testing.T) {
+		repo := repository.NewMemoryRepository(log)
+		svc := service.New(repo, log, tel)
+
+		results, err := svc.BulkCreateExamples(ctx, []*models.ExampleRequest{
+			{Name: "ab"},
+			{Name: strings.Repeat("a", 101)},
+			{Name: "Long Description", Description: strings.Repeat("a", 501)},
+			{Name: "Valid Example", Description: strings.Repeat("a", 500)},
+		}, false)
+		require.NoError(t, err)
+		require.Len(t, results, 4)
+
+		for _, result := range results[:3] {
+			assert.ErrorIs(t, result.Err, service.ErrInvalidRequest)
+		}
+		assert.NoError(t, results[3].Err)
+	})
+
+	// Test atomic mode rolls back created items when a later item fails
+	t.Run("AtomicRollback", func(t *testing.T) {
+		// Make the last item fail after the first two have been created
+		repo := repository.NewMemoryRepository(log)
+		svc := service.New(&failOnNameRepository{Repository: repo, name: "Third Example"}, log, tel)
+
+		results, err := svc.BulkCreateExamples(ctx, reqs, true)
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+
+		assert.ErrorIs(t, results[0].Err, service.ErrRolledBack)
+		assert.ErrorIs(t, results[1].Err, service.ErrRolledBack)
+		assert.ErrorIs(t, results[2].Err, repository.ErrInternal)
+		for _, result := range results {
+			assert.Nil(t, result.Example)
+		}
+
+		examples, err := repo.ListExamples(ctx, 0, 0)
+		require.NoError(t, err)
+		assert.Empty(t, examples)
+	})
+
+	// Test the rollback completes when the request is cancelled mid-batch
+	t.Run("AtomicRollbackCancelled", func(t *testing.T) {
+		reqCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		repo := repository.NewMemoryRepository(log)
+		svc := service.New(&failOnNameRepository{Repository: repo, name: "Third Example", onFail: cancel}, log, tel)
+
+		results, err := svc.BulkCreateExamples(reqCtx, reqs, true)
+		require.NoError(t, err)
+		assert.ErrorIs(t, results[0].Err, service.ErrRolledBack)
+
+		examples, err := repo.ListExamples(ctx, 0, 0)
+		require.NoError(t, err)
+		assert.Empty(t, examples)
+	})
+
+	// Test atomic mode writes nothing when an item is invalid
+	t.Run("AtomicInvalid", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.New(mockRepo, log, tel)
+
+		results, err := svc.BulkCreateExamples(ctx, []*models.ExampleRequest{
+			{Name: "First Example"},
+			{Name: ""},
+		}, true)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+
+		assert.ErrorIs(t, results[0].Err, service.ErrRolledBack)
+		assert.ErrorIs(t, results[1].Err, service.ErrInvalidRequest)
+		mockRepo.AssertNotCalled(t, "CreateExample", mock.Anything, mock.Anything)
+	})
+}
+
+// failOnNameRepository fails to create examples with the given name, calling
+// onFail first when set
+type failOnNameRepository struct {
+	repository.Repository
+	name   string
+	onFail func()
+}
+
+func (r *failOnNameRepository) CreateExample(ctx context.Context, example *models.Example) error {
+	if example.Name == r.name {
+		if r.onFail != nil {
+			r.onFail()
+		}
+		return repository.ErrInternal
+	}
+	return r.Repository.CreateExample(ctx, example)
+}
+
+func TestServiceEvents(t *testing.T) {
+	log := logger.Default()
+
+	tel, err := telemetry.New(context.Background(), telemetry.Config{
+		ServiceName: "test-service",
+		Enabled:     false,
+	}, log)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	// nextEvent returns the next published event or fails the test
+	nextEvent := func(t *testing.T, publisher *service.ChannelPublisher) service.Event {
+		t.Helper()
+
+		select {
+		case event := <-publisher.Events():
+			return event
+		case <-time.After(time.Second):
+			require.FailNow(t, "no event published")
+			return service.Event{}
+		}
+	}
+
+	// assertNoEvent fails the test if an event was published
+	assertNoEvent := func(t *testing.T, publisher *service.ChannelPublisher) {
+		t.Helper()
+
+		select {
+		case event := <-publisher.Events():
+			assert.Fail(t, "unexpected event", "%+v", event)
+		default:
+		}
+	}
+
+	// Test each write publishes an event for the example
+	t.Run("Published", func(t *testing.T) {
+		publisher := service.NewChannelPublisher(10)
+		svc := service.New(repository.NewMemoryRepository(log), log, tel, service.WithEventPublisher(publisher))
+
+		before := time.Now()
+
+		example, err := svc.CreateExample(ctx, &models.ExampleRequest{Name: "New Example"})
+		require.NoError(t, err)
+
+		event := nextEvent(t, publisher)
+		assert.Equal(t, service.EventExampleCreated, event.Type)
+		assert.Equal(t, example.ID, event.EntityID)
+		assert.False(t, event.Timestamp.Before(before))
+
+		_, err = svc.UpdateExample(ctx, example.ID, &models.ExampleRequest{Name: "Updated Example"})
+		require.NoError(t, err)
+
+		event = nextEvent(t, publisher)
+		assert.Equal(t, service.EventExampleUpdated, event.Type)
+		assert.Equal(t, example.ID, event.EntityID)
+
+		require.NoError(t, svc.DeleteExample(ctx, example.ID))
+
+		event = nextEvent(t, publisher)
+		assert.Equal(t, service.EventExampleDeleted, event.Type)
+		assert.Equal(t, example.ID, event.EntityID)
+
+		assertNoEvent(t, publisher)
+	})
+
+	// Test archiving publishes an update for each archived example
+	t.Run("Archive", func(t *testing.T) {
+		publisher := service.NewChannelPublisher(10)
+		svc := service.New(repository.NewMemoryRepository(log), log, tel, service.WithEventPublisher(publisher))
+
+		example, err := svc.CreateExample(ctx, &models.ExampleRequest{Name: "Inactive", Status: models.StatusInactive})
+		require.NoError(t, err)
+		nextEvent(t, publisher)
+
+		_, err = svc.ArchiveExamples(ctx, models.ArchiveFilter{Status: models.StatusInactive})
+		require.NoError(t, err)
+
+		event := nextEvent(t, publisher)
+		assert.Equal(t, service.EventExampleUpdated, event.Type)
+		assert.Equal(t, example.ID, event.EntityID)
+		assertNoEvent(t, publisher)
+	})
+
+	// Test nothing is published when the repository write fails
+	t.Run("NotPublishedOnFailure", func(t *testing.T) {
+		publisher := service.NewChannelPublisher(10)
+		mockRepo := new(MockRepository)
+		svc := service.New(mockRepo, log, tel, service.WithEventPublisher(publisher))
+
+		id := uuid.New().String()
+		mockRepo.On("CreateExample", mock.Anything, mock.Anything).Return(repository.ErrInternal)
+		mockRepo.On("GetExample", mock.Anything, id).Return(models.NewExample(id, "Example", ""), nil)
+		mockRepo.On("UpdateExample", mock.Anything, mock.Anything).Return(repository.ErrInternal)
+		mockRepo.On("DeleteExample", mock.Anything, id).Return(repository.ErrNotFound)
+
+		_, err := svc.CreateExample(ctx, &models.ExampleRequest{Name: "New Example"})
+		require.Error(t, err)
+
+		_, err = svc.UpdateExample(ctx, id, &models.ExampleRequest{Name: "Updated Example"})
+		require.Error(t, err)
+
+		require.Error(t, svc.DeleteExample(ctx, id))
+
+		assertNoEvent(t, publisher)
+	})
+
+	// Test bulk creates publish an event per created example only
+	t.Run("BulkCreate", func(t *testing.T) {
+		publisher := service.NewChannelPublisher(10)
+		svc := service.New(repository.NewMemoryRepository(log), log, tel, service.WithEventPublisher(publisher))
+
+		results, err := svc.BulkCreateExamples(ctx, []*models.ExampleRequest{
+			{Name: "First Example"},
+			{Name: ""},
+		}, false)
+		require.NoError(t, err)
+
+		event := nextEvent(t, publisher)
+		assert.Equal(t, service.EventExampleCreated, event.Type)
+		assert.Equal(t, results[0].Example.ID, event.EntityID)
+		assertNoEvent(t, publisher)
+
+		_, err = svc.BulkCreateExamples(ctx, []*models.ExampleRequest{
+			{Name: "First Example"},
+			{Name: ""},
+		}, true)
+		require.NoError(t, err)
+		assertNoEvent(t, publisher)
+	})
+}
+
+func TestBroadcaster(t *testing.T) {
+	ctx := context.Background()
+	event := service.Event{Type: service.EventExampleCreated, EntityID: "a"}
+
+	// Test every subscriber receives a published event
+	t.Run("FanOut", func(t *testing.T) {
+		b := service.NewBroadcaster(1)
+		first, unsubscribeFirst := b.Subscribe()
+		defer unsubscribeFirst()
+		second, unsubscribeSecond := b.Subscribe()
+		defer unsubscribeSecond()
+
+		require.NoError(t, b.Publish(ctx, event))
+
+		assert.Equal(t, event, <-first)
+		assert.Equal(t, event, <-second)
+	})
+
+	// Test a full subscriber misses events instead of blocking Publish
+	t.Run("SlowSubscriber", func(t *testing.T) {
+		b := service.NewBroadcaster(1)
+		events, unsubscribe := b.Subscribe()
+		defer unsubscribe()
+
+		require.NoError(t, b.Publish(ctx, event))
+		require.NoError(t, b.Publish(ctx, service.Event{Type: service.EventExampleDeleted, EntityID: "a"}))
+
+		assert.Equal(t, event, <-events)
+		assert.Empty(t, events)
+	})
+
+	// Test unsubscribing closes the channel and stops delivery
+	t.Run("Unsubscribe", func(t *testing.T) {
+		b := service.NewBroadcaster(1)
+		events, unsubscribe := b.Subscribe()
+
+		unsubscribe()
+		unsubscribe()
+		require.NoError(t, b.Publish(ctx, event))
+
+		_, ok := <-events
+		assert.False(t, ok)
+	})
+
+	// Test closing ends every subscription and refuses new ones
+	t.Run("Close", func(t *testing.T) {
+		b := service.NewBroadcaster(1)
+		events, unsubscribe := b.Subscribe()
+
+		b.Close()
+		unsubscribe()
+
+		_, ok := <-events
+		assert.False(t, ok)
+		assert.Zero(t, b.Subscribers())
+
+		late, _ := b.Subscribe()
+		_, ok = <-late
+		assert.False(t, ok)
+	})
+}
+
+func TestListProtectedResources(t *testing.T) {
+	log := logger.Default()
+
+	tel, err := telemetry.New(context.Background(), telemetry.Config{Enabled: false}, log)
+	require.NoError(t, err)
+
+	svc := service.New(new(MockRepository), log, tel)
+
+	// withScopes returns a context carrying the caller's token scopes
+	withScopes := func(scopes ...string) context.Context {
+		return context.WithValue(context.Background(), auth.ScopesContextKey, scopes)
+	}
+	ctx := withScopes("read")
+
+	// Test filtering by owner returns only that owner's resources
+	t.Run("FilterByOwner", func(t *testing.T) {
+		resources, err := svc.ListProtectedResources(ctx, 0, 0, "user456")
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		assert.Equal(t, "user456", resources[0].OwnerID)
+		assert.Equal(t, "Protected Resource 2", resources[0].Name)
+	})
+
+	// Test limit and offset slice the resources in a stable order
+	t.Run("LimitOffset", func(t *testing.T) {
+		first, err := svc.ListProtectedResources(ctx, 1, 0, "")
+		require.NoError(t, err)
+		require.Len(t, first, 1)
+		assert.Equal(t, "Protected Resource 1", first[0].Name)
+
+		second, err := svc.ListProtectedResources(ctx, 1, 1, "")
+		require.NoError(t, err)
+		require.Len(t, second, 1)
+		assert.Equal(t, "Protected Resource 2", second[0].Name)
+
+		past, err := svc.ListProtectedResources(ctx, 1, 5, "")
+		require.NoError(t, err)
+		assert.Empty(t, past)
+	})
+
+	// Test a negative offset is rejected rather than panicking
+	t.Run("NegativeOffset", func(t *testing.T) {
+		resources, err := svc.ListProtectedResources(ctx, 1, -1, "")
+		assert.ErrorIs(t, err, service.ErrInvalidRequest)
+		assert.Nil(t, resources)
+	})
+
+	// Test an unknown owner returns no resources
+	t.Run("UnknownOwner", func(t *testing.T) {
+		resources, err := svc.ListProtectedResources(ctx, 10, 0, "nobody")
+		require.NoError(t, err)
+		assert.Empty(t, resources)
+	})
+
+	// Test a read token sees only read resources while an admin token also
+	// sees admin resources
+	t.Run("FilterByScope", func(t *testing.T) {
+		ids := func(resources []*models.ProtectedResource) []string {
+			out := make([]string, len(resources))
+			for i, resource := range resources {
+				out[i] = resource.ID
+			}
+			return out
+		}
+
+		read, err := svc.ListProtectedResources(withScopes("read"), 0, 0, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"protected-resource-1", "protected-resource-2"}, ids(read))
+
+		admin, err := svc.ListProtectedResources(withScopes("read", "admin"), 0, 0, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"protected-resource-1", "protected-resource-2", "protected-resource-3"}, ids(admin))
+	})
+
+	// Test a caller without scopes sees no resources
+	t.Run("NoScopes", func(t *testing.T) {
+		resources, err := svc.ListProtectedResources(context.Background(), 0, 0, "")
+		require.NoError(t, err)
+		assert.Empty(t, resources)
+	})
+}
+
+func TestExampleStatus(t *testing.T) {
+	log := logger.Default()
+	ctx := context.Background()
+
+	tel, err := telemetry.New(ctx, telemetry.Config{Enabled: false}, log)
+	require.NoError(t, err)
+
+	svc := service.New(repository.NewMemoryRepository(log), log, tel)
+
+	// Test an omitted status defaults to active
+	t.Run("Default", func(t *testing.T) {
+		example, err := svc.CreateExample(ctx, &models.ExampleRequest{Name: "Defaulted"})
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusActive, example.Status)
+	})
+
+	// Test a valid status is applied on create and update
+	t.Run("Valid", func(t *testing.T) {
+		example, err := svc.CreateExample(ctx, &models.ExampleRequest{Name: "Inactive", Status: models.StatusInactive})
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusInactive, example.Status)
+
+		example, err = svc.UpdateExample(ctx, example.ID, &models.ExampleRequest{Name: "Archived", Status: models.StatusArchived})
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusArchived, example.Status)
+
+		// Test an update without a status keeps the current one
+		example, err = svc.UpdateExample(ctx, example.ID, &models.ExampleRequest{Name: "Renamed"})
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusArchived, example.Status)
+	})
+
+	// Test an unknown status is a validation error on every write
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := svc.CreateExample(ctx, &models.ExampleRequest{Name: "Bad", Status: "deleted"})
+		assert.ErrorIs(t, err, service.ErrValidation)
+
+		example, err := svc.CreateExample(ctx, &models.ExampleRequest{Name: "Good"})
+		require.NoError(t, err)
+		_, err = svc.UpdateExample(ctx, example.ID, &models.ExampleRequest{Name: "Good", Status: "Active"})
+		assert.ErrorIs(t, err, service.ErrValidation)
+
+		_, _, err = svc.UpsertExample(ctx, "upserted", &models.ExampleRequest{Name: "Bad", Status: "deleted"})
+		assert.ErrorIs(t, err, service.ErrValidation)
+	})
+}
+
+func TestArchiveExamples(t *testing.T) {
+	log := logger.Default()
+	ctx := context.Background()
+
+	tel, err := telemetry.New(ctx, telemetry.Config{Enabled: false}, log)
+	require.NoError(t, err)
+
+	svc := service.New(repository.NewMemoryRepository(log), log, tel, service.WithListCache(time.Minute))
+
+	inactive, err := svc.CreateExample(ctx, &models.ExampleRequest{Name: "Inactive", Status: models.StatusInactive})
+	require.NoError(t, err)
+	active, err := svc.CreateExample(ctx, &models.ExampleRequest{Name: "Active"})
+	require.NoError(t, err)
+
+	// Test only matching examples are archived and counted
+	t.Run("ByStatus", func(t *testing.T) {
+		// Warm the list cache so a stale page would show
+		_, err := svc.ListExamples(ctx, 10, 0)
+		require.NoError(t, err)
+
+		count, err := svc.ArchiveExamples(ctx, models.ArchiveFilter{Status: models.StatusInactive})
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		example, err := svc.GetExample(ctx, inactive.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusArchived, example.Status)
+
+		example, err = svc.GetExample(ctx, active.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusActive, example.Status)
+
+		// Test the cached list sees the change
+		examples, err := svc.ListExamples(ctx, 10, 0)
+		require.NoError(t, err)
+		for _, example := range examples {
+			if example.ID == inactive.ID {
+				assert.Equal(t, models.StatusArchived, example.Status)
+			}
+		}
+	})
+
+	// Test a cutoff before every example matches nothing
+	t.Run("OlderThan", func(t *testing.T) {
+		count, err := svc.ArchiveExamples(ctx, models.ArchiveFilter{OlderThan: active.CreatedAt.Add(-time.Hour)})
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+
+	// Test an empty filter or an unarchivable status is a validation error
+	t.Run("InvalidFilter", func(t *testing.T) {
+		_, err := svc.ArchiveExamples(ctx, models.ArchiveFilter{})
+		assert.ErrorIs(t, err, service.ErrValidation)
+
+		_, err = svc.ArchiveExamples(ctx, models.ArchiveFilter{Status: models.StatusArchived})
+		assert.ErrorIs(t, err, service.ErrValidation)
+	})
+}
+
+func TestSearchExamples(t *testing.T) {
+	log := logger.Default()
+	ctx := context.Background()
+
+	tel, err := telemetry.New(ctx, telemetry.Config{Enabled: false}, log)
+	require.NoError(t, err)
+
+	svc := service.New(repository.NewMemoryRepository(log), log, tel)
+
+	_, err = svc.CreateExample(ctx, &models.ExampleRequest{Name: "Red Widget"})
+	require.NoError(t, err)
+	_, err = svc.CreateExample(ctx, &models.ExampleRequest{Name: "Holder", Description: "Keeps a widget upright"})
+	require.NoError(t, err)
+
+	// Test a name match
+	t.Run("Name", func(t *testing.T) {
+		examples, err := svc.SearchExamples(ctx, "red", 10)
+		require.NoError(t, err)
+		require.Len(t, examples, 1)
+		assert.Equal(t, "Red Widget", examples[0].Name)
+	})
+
+	// Test a description match, ranked below a name match
+	t.Run("Description", func(t *testing.T) {
+		examples, err := svc.SearchExamples(ctx, "Widget", 10)
+		require.NoError(t, err)
+		require.Len(t, examples, 2)
+		assert.Equal(t, "Red Widget", examples[0].Name)
+		assert.Equal(t, "Holder", examples[1].Name)
+	})
+
+	// Test no match returns an empty list
+	t.Run("NoMatch", func(t *testing.T) {
+		examples, err := svc.SearchExamples(ctx, "sprocket", 10)
+		require.NoError(t, err)
+		assert.Empty(t, examples)
+	})
+
+	// Test a blank query is a validation error
+	t.Run("EmptyQuery", func(t *testing.T) {
+		_, err := svc.SearchExamples(ctx, "  ", 10)
+		assert.ErrorIs(t, err, service.ErrValidation)
+	})
+}
+
+func TestUniqueNames(t *testing.T) {
+	log := logger.Default()
+	ctx := context.Background()
+
+	tel, err := telemetry.New(ctx, telemetry.Config{Enabled: false}, log)
+	require.NoError(t, err)
+
+	// Test creating an example with a taken name conflicts
+	t.Run("Conflict", func(t *testing.T) {
+		svc := service.New(repository.NewMemoryRepository(log), log, tel, service.WithUniqueNames(true))
+
+		_, err := svc.CreateExample(ctx, &models.ExampleRequest{Name: "Duplicate"})
+		require.NoError(t, err)
+
+		_, err = svc.CreateExample(ctx, &models.ExampleRequest{Name: "Duplicate"})
+		assert.ErrorIs(t, err, repository.ErrAlreadyExists)
+	})
+
+	// Test renaming an example to a taken name conflicts, while keeping its
+	// own name doesn't
+	t.Run("RenameConflict", func(t *testing.T) {
+		svc := service.New(repository.NewMemoryRepository(log), log, tel, service.WithUniqueNames(true))
+
+		_, err := svc.CreateExample(ctx, &models.ExampleRequest{Name: "Taken"})
+		require.NoError(t, err)
+		other, err := svc.CreateExample(ctx, &models.ExampleRequest{Name: "Other"})
+		require.NoError(t, err)
+
+		_, err = svc.UpdateExample(ctx, other.ID, &models.ExampleRequest{Name: "Taken"})
+		assert.ErrorIs(t, err, service.ErrConflict)
+		assert.ErrorIs(t, err, repository.ErrAlreadyExists)
+
+		_, err = svc.UpdateExample(ctx, other.ID, &models.ExampleRequest{Name: "Other", Description: "Updated"})
+		assert.NoError(t, err)
+	})
+
+	// Test bulk creates enforce unique names too
+	t.Run("BulkConflict", func(t *testing.T) {
+		svc := service.New(repository.NewMemoryRepository(log), log, tel, service.WithUniqueNames(true))
+
+		results, err := svc.BulkCreateExamples(ctx, []*models.ExampleRequest{
+			{Name: "Duplicate"},
+			{Name: "Duplicate"},
+		}, false)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.NoError(t, results[0].Err)
+		assert.ErrorIs(t, results[1].Err, repository.ErrAlreadyExists)
+	})
+
+	// Test duplicates are allowed when the check is off
+	t.Run("Disabled", func(t *testing.T) {
+		svc := service.New(repository.NewMemoryRepository(log), log, tel, service.WithUniqueNames(false))
+
+		first, err := svc.CreateExample(ctx, &models.ExampleRequest{Name: "Duplicate"})
+		require.NoError(t, err)
+
+		second, err := svc.CreateExample(ctx, &models.ExampleRequest{Name: "Duplicate"})
+		require.NoError(t, err)
+		assert.NotEqual(t, first.ID, second.ID)
+	})
+}
+
+func TestUpsertExample(t *testing.T) {
+	log := logger.Default()
+	ctx := context.Background()
+
+	tel, err := telemetry.New(ctx, telemetry.Config{Enabled: false}, log)
+	require.NoError(t, err)
+
+	svc := service.New(repository.NewMemoryRepository(log), log, tel, service.WithUniqueNames(true))
+
+	// Test an unknown ID is created with that ID
+	t.Run("Creates", func(t *testing.T) {
+		example, created, err := svc.UpsertExample(ctx, "upsert-1", &models.ExampleRequest{Name: "Upserted"})
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, "upsert-1", example.ID)
+
+		stored, err := svc.GetExample(ctx, "upsert-1")
+		require.NoError(t, err)
+		assert.Equal(t, "Upserted", stored.Name)
+	})
+
+	// Test an existing ID is updated, keeping its own name available to it
+	t.Run("Updates", func(t *testing.T) {
+		example, created, err := svc.UpsertExample(ctx, "upsert-1", &models.ExampleRequest{Name: "Upserted", Description: "Changed"})
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, "Changed", example.Description)
+	})
+
+	// Test a name held by another example conflicts
+	t.Run("NameConflict", func(t *testing.T) {
+		_, _, err := svc.UpsertExample(ctx, "upsert-2", &models.ExampleRequest{Name: "Upserted"})
+		assert.ErrorIs(t, err, repository.ErrAlreadyExists)
+	})
+
+	// Test updating without a status keeps the stored one
+	t.Run("KeepsStatus", func(t *testing.T) {
+		_, _, err := svc.UpsertExample(ctx, "upsert-3", &models.ExampleRequest{Name: "Inactive", Status: models.StatusInactive})
+		require.NoError(t, err)
+
+		example, created, err := svc.UpsertExample(ctx, "upsert-3", &models.ExampleRequest{Name: "Inactive", Description: "Changed"})
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, models.StatusInactive, example.Status)
+
+		stored, err := svc.GetExample(ctx, "upsert-3")
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusInactive, stored.Status)
+		assert.Equal(t, "Changed", stored.Description)
+	})
+}
+
+func TestServiceErrors(t *testing.T) {
+	log := logger.Default()
+	ctx := context.Background()
+
+	tel, err := telemetry.New(ctx, telemetry.Config{Enabled: false}, log)
+	require.NoError(t, err)
+
+	svc := service.New(repository.NewMemoryRepository(log), log, tel, service.WithUniqueNames(true))
+
+	// Test a missing example is reported as ErrNotFound
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := svc.GetExample(ctx, "missing")
+		assert.ErrorIs(t, err, service.ErrNotFound)
+
+		var serviceErr *service.Error
+		require.ErrorAs(t, err, &serviceErr)
+		assert.Equal(t, service.ErrNotFound, serviceErr.Kind)
+	})
+
+	// Test a taken name is reported as ErrConflict
+	t.Run("Conflict", func(t *testing.T) {
+		_, err := svc.CreateExample(ctx, &models.ExampleRequest{Name: "Taken"})
+		require.NoError(t, err)
+
+		_, err = svc.CreateExample(ctx, &models.ExampleRequest{Name: "Taken"})
+		assert.ErrorIs(t, err, service.ErrConflict)
+	})
+
+	// Test an invalid cursor is reported as ErrValidation
+	t.Run("Validation", func(t *testing.T) {
+		_, _, err := svc.ListExamplesAfter(ctx, "not a cursor", 10)
+		assert.ErrorIs(t, err, service.ErrValidation)
+	})
+
+	// Test a repository failure is reported as ErrInternal
+	t.Run("Internal", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("CreateExample", mock.Anything, mock.Anything).Return(repository.ErrInternal)
+
+		_, err := service.New(mockRepo, log, tel).CreateExample(ctx, &models.ExampleRequest{Name: "Example"})
+		assert.ErrorIs(t, err, service.ErrInternal)
+		assert.ErrorIs(t, err, repository.ErrInternal)
+	})
+}
+
+// recordingAuditor keeps every recorded audit entry
+type recordingAuditor struct {
+	mu      sync.Mutex
+	entries []audit.AuditEntry
+}
+
+func (a *recordingAuditor) Record(_ context.Context, entry audit.AuditEntry) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries = append(a.entries, entry)
+}
+
+func TestServiceAudit(t *testing.T) {
+	log := logger.Default()
+
+	tel, err := telemetry.New(context.Background(), telemetry.Config{
+		ServiceName: "test-service",
+		Enabled:     false,
+	}, log)
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), auth.UserIDContextKey, "user-42")
+	ctx = context.WithValue(ctx, middleware.RequestIDKey, "req-1")
+
+	// Test create and delete record an entry with the acting user
+	t.Run("CreateDelete", func(t *testing.T) {
+		auditor := &recordingAuditor{}
+		svc := service.New(repository.NewMemoryRepository(log), log, tel, service.WithAuditor(auditor))
+
+		example, err := svc.CreateExample(ctx, &models.ExampleRequest{Name: "New Example"})
+		require.NoError(t, err)
+		require.NoError(t, svc.DeleteExample(ctx, example.ID))
+
+		require.Len(t, auditor.entries, 2)
+		for i, action := range []string{audit.ActionCreate, audit.ActionDelete} {
+			entry := auditor.entries[i]
+			assert.Equal(t, action, entry.Action)
+			assert.Equal(t, "user-42", entry.UserID)
+			assert.Equal(t, "example", entry.ResourceType)
+			assert.Equal(t, example.ID, entry.ResourceID)
+			assert.Equal(t, "req-1", entry.RequestID)
+			assert.False(t, entry.Timestamp.IsZero())
+		}
+	})
+
+	// Test archiving records an entry for each archived example
+	t.Run("Archive", func(t *testing.T) {
+		auditor := &recordingAuditor{}
+		svc := service.New(repository.NewMemoryRepository(log), log, tel, service.WithAuditor(auditor))
+
+		first, err := svc.CreateExample(ctx, &models.ExampleRequest{Name: "First", Status: models.StatusInactive})
+		require.NoError(t, err)
+		second, err := svc.CreateExample(ctx, &models.ExampleRequest{Name: "Second", Status: models.StatusInactive})
+		require.NoError(t, err)
+		auditor.entries = nil
+
+		_, err = svc.ArchiveExamples(ctx, models.ArchiveFilter{Status: models.StatusInactive})
+		require.NoError(t, err)
+
+		require.Len(t, auditor.entries, 2)
+		ids := []string{auditor.entries[0].ResourceID, auditor.entries[1].ResourceID}
+		assert.ElementsMatch(t, []string{first.ID, second.ID}, ids)
+		for _, entry := range auditor.entries {
+			assert.Equal(t, audit.ActionArchive, entry.Action)
+		}
+	})
+
+	// Test nothing is recorded when the write fails
+	t.Run("NotRecordedOnFailure", func(t *testing.T) {
+		auditor := &recordingAuditor{}
+		svc := service.New(repository.NewMemoryRepository(log), log, tel, service.WithAuditor(auditor))
+
+		require.Error(t, svc.DeleteExample(ctx, uuid.New().String()))
+		assert.Empty(t, auditor.entries)
+	})
+}
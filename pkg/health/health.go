@@ -5,6 +5,8 @@ package health
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"sync"
 	"time"
@@ -38,17 +40,47 @@ type Component struct {
 // Check is a function that performs a health check on a component
 type Check func(ctx context.Context) Component
 
+// DefaultCheckTimeout is how long a check added with AddCheck may run
+const DefaultCheckTimeout = 5 * time.Second
+
+// registeredCheck is a check with its own timeout
+type registeredCheck struct {
+	check   Check
+	timeout time.Duration
+
+	// name is the component name from the last completed run, used to
+	// report a timed out check
+	name string
+}
+
+// BuildInfo describes the running build. Fields left empty are omitted.
+type BuildInfo struct {
+	Version   string `json:"-"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"buildTime,omitempty"`
+	GoVersion string `json:"goVersion,omitempty"`
+}
+
+// isZero reports whether no build metadata beyond the version is set
+func (b BuildInfo) isZero() bool {
+	return b.Commit == "" && b.BuildTime == "" && b.GoVersion == ""
+}
+
 // Checker provides health/readiness/liveness endpoints
 type Checker struct {
 	appName     string
 	version     string
+	build       *BuildInfo
 	description string
-	checks      []Check
+	checks      []*registeredCheck
 	mu          sync.RWMutex
 	cache       *StatusResponse
 	cacheTTL    time.Duration
 	lastUpdate  time.Time
 	log         logger.Logger // Add logger for error handling
+
+	// degradedIsReady makes readiness report 200 rather than 503 while degraded
+	degradedIsReady bool
 }
 
 // StatusResponse represents the overall health status of the service
@@ -56,28 +88,66 @@ type StatusResponse struct {
 	Name        string      `json:"name"`
 	Version     string      `json:"version"`
 	Description string      `json:"description,omitempty"`
+	Build       *BuildInfo  `json:"build,omitempty"`
 	Status      Status      `json:"status"`
 	Components  []Component `json:"components,omitempty"`
 	Timestamp   time.Time   `json:"timestamp"`
 }
 
-// NewHealthCheck creates a new health check handler
-func NewHealthCheck(appName, version, description string, log logger.Logger) *Checker {
+// VersionResponse describes the running build
+type VersionResponse struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"buildTime"`
+}
+
+// NewHealthCheck creates a new health check handler. The version and any
+// other build metadata in build are included in every status response.
+func NewHealthCheck(appName, description string, build BuildInfo, log logger.Logger) *Checker {
+	var buildPtr *BuildInfo
+	if !build.isZero() {
+		buildPtr = &build
+	}
+
 	return &Checker{
 		appName:     appName,
-		version:     version,
+		version:     build.Version,
+		build:       buildPtr,
 		description: description,
-		checks:      []Check{},
+		checks:      []*registeredCheck{},
 		cacheTTL:    time.Second * 10,
 		log:         log,
+
+		degradedIsReady: true,
 	}
 }
 
-// AddCheck adds a health check component
+// SetDegradedIsReady sets whether readiness succeeds while a component is
+// DEGRADED. It defaults to true; set it to false for orchestrators that should
+// stop routing traffic to a degraded instance. Liveness is unaffected.
+func (h *Checker) SetDegradedIsReady(ready bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.degradedIsReady = ready
+}
+
+// AddCheck adds a health check component with the default timeout
 func (h *Checker) AddCheck(check Check) {
+	h.AddCheckWithTimeout(check, DefaultCheckTimeout)
+}
+
+// AddCheckWithTimeout adds a health check component that may run for at most
+// timeout (DefaultCheckTimeout if not positive). A check that overruns is
+// reported DOWN without holding up the other checks.
+func (h *Checker) AddCheckWithTimeout(check Check, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	h.checks = append(h.checks, check)
+	h.checks = append(h.checks, &registeredCheck{check: check, timeout: timeout})
 	h.cache = nil // Invalidate cache
 }
 
@@ -103,6 +173,7 @@ func (h *Checker) LivenessHandler() http.HandlerFunc {
 		status := &StatusResponse{
 			Name:      h.appName,
 			Version:   h.version,
+			Build:     h.build,
 			Status:    StatusUp,
 			Timestamp: time.Now(),
 		}
@@ -115,6 +186,27 @@ func (h *Checker) LivenessHandler() http.HandlerFunc {
 	}
 }
 
+// VersionHandler handles the /version endpoint. It only reports build
+// metadata and never runs the health checks.
+func (h *Checker) VersionHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		resp := VersionResponse{
+			Name:    h.appName,
+			Version: h.version,
+		}
+		if h.build != nil {
+			resp.Commit = h.build.Commit
+			resp.BuildTime = h.build.BuildTime
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			h.log.Error("Failed to encode version", logger.Error(err))
+		}
+	}
+}
+
 // ReadinessHandler handles the /health/readiness endpoint
 func (h *Checker) ReadinessHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -122,6 +214,13 @@ func (h *Checker) ReadinessHandler() http.HandlerFunc {
 
 		status, httpStatus := h.getHealth(ctx)
 
+		h.mu.RLock()
+		degradedIsReady := h.degradedIsReady
+		h.mu.RUnlock()
+		if status.Status == StatusDegraded && !degradedIsReady {
+			httpStatus = http.StatusServiceUnavailable
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(httpStatus)
 		if err := json.NewEncoder(w).Encode(status); err != nil {
@@ -151,32 +250,24 @@ func (h *Checker) getHealth(ctx context.Context) (*StatusResponse, int) {
 		return h.cache, statusToHTTP(h.cache.Status)
 	}
 
-	components := make([]Component, 0, len(h.checks))
+	components := make([]Component, len(h.checks))
 	status := StatusUp
 
 	// Execute all health checks concurrently
 	var wg sync.WaitGroup
-	resultCh := make(chan Component, len(h.checks))
-
-	for _, check := range h.checks {
+	for i, check := range h.checks {
 		wg.Add(1)
-		go func(c Check) {
+		go func(i int, c *registeredCheck) {
 			defer wg.Done()
-			// Use a timeout to prevent hanging health checks
-			ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
-			defer cancel()
-
-			resultCh <- c(ctxTimeout)
-		}(check)
+			components[i] = c.run(ctx, i)
+		}(i, check)
 	}
 
 	// Wait for all checks to complete
 	wg.Wait()
-	close(resultCh)
 
 	// Collect results
-	for component := range resultCh {
-		components = append(components, component)
+	for _, component := range components {
 		if component.Status == StatusDown {
 			status = StatusDown
 		} else if component.Status == StatusDegraded && status == StatusUp {
@@ -188,6 +279,7 @@ func (h *Checker) getHealth(ctx context.Context) (*StatusResponse, int) {
 		Name:        h.appName,
 		Version:     h.version,
 		Description: h.description,
+		Build:       h.build,
 		Status:      status,
 		Components:  components,
 		Timestamp:   time.Now(),
@@ -200,6 +292,39 @@ func (h *Checker) getHealth(ctx context.Context) (*StatusResponse, int) {
 	return result, statusToHTTP(status)
 }
 
+// run runs the check within its timeout. A check that overruns is left to
+// finish in the background and reported DOWN.
+func (c *registeredCheck) run(ctx context.Context, i int) Component {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resultCh := make(chan Component, 1)
+	go func() {
+		resultCh <- c.check(ctx)
+	}()
+
+	select {
+	case component := <-resultCh:
+		c.name = component.Name
+		return component
+	case <-ctx.Done():
+		name := c.name
+		if name == "" {
+			name = fmt.Sprintf("check-%d", i+1)
+		}
+		return Component{
+			Name:        name,
+			Status:      StatusDown,
+			Description: "Health check timed out",
+			Details: map[string]interface{}{
+				"error":   "timeout",
+				"timeout": c.timeout.String(),
+			},
+			LastChecked: time.Now(),
+		}
+	}
+}
+
 // statusToHTTP converts a health status to an HTTP status code
 func statusToHTTP(status Status) int {
 	switch status {
@@ -238,3 +363,89 @@ func DBCheck(name string, pingFn func(context.Context) error) Check {
 		return component
 	}
 }
+
+// OAuth2Check creates a health check for an OAuth2 provider that sends a GET
+// to url, such as its introspection or JWKS endpoint, with client. The
+// provider is UP if it responds with anything but a 5xx status, since most
+// such endpoints reject the unauthenticated request.
+func OAuth2Check(name, url string, client *http.Client) Check {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return func(ctx context.Context) Component {
+		start := time.Now()
+		status, err := ping(ctx, client, url)
+		duration := time.Since(start)
+
+		component := Component{
+			Name:        name,
+			Status:      StatusUp,
+			Description: "OAuth2 provider is reachable",
+			Details: map[string]interface{}{
+				"url":          url,
+				"responseTime": duration.String(),
+			},
+			LastChecked: time.Now(),
+		}
+
+		switch {
+		case err != nil:
+			component.Status = StatusDown
+			component.Description = "OAuth2 provider is unreachable"
+			component.Details["error"] = err.Error()
+		case status >= http.StatusInternalServerError:
+			component.Status = StatusDown
+			component.Description = "OAuth2 provider is unavailable"
+			component.Details["statusCode"] = status
+		default:
+			component.Details["statusCode"] = status
+		}
+
+		return component
+	}
+}
+
+// ping sends a GET to url and returns the response status
+func ping(ctx context.Context, client *http.Client, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Drain a little of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	return resp.StatusCode, nil
+}
+
+// CircuitBreakerCheck creates a health check for a circuit breaker. The
+// component is DEGRADED whenever stateFn reports anything other than "closed".
+func CircuitBreakerCheck(name string, stateFn func() string) Check {
+	return func(_ context.Context) Component {
+		state := stateFn()
+
+		component := Component{
+			Name:        name,
+			Status:      StatusUp,
+			Description: "Circuit breaker is closed",
+			Details: map[string]interface{}{
+				"state": state,
+			},
+			LastChecked: time.Now(),
+		}
+
+		if state != "closed" {
+			component.Status = StatusDegraded
+			component.Description = "Circuit breaker is " + state
+		}
+
+		return component
+	}
+}
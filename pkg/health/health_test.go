@@ -0,0 +1,232 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dBiTech/go-apiTemplate/pkg/health"
+	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+)
+
+func TestBuildInfo(t *testing.T) {
+	// getHealth serves /health and decodes the response
+	getHealth := func(t *testing.T, checker *health.Checker) map[string]interface{} {
+		t.Helper()
+
+		w := httptest.NewRecorder()
+		checker.HealthHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+
+	// Test build fields are reported when set
+	t.Run("Set", func(t *testing.T) {
+		checker := health.NewHealthCheck("test-app", "Test", health.BuildInfo{
+			Version:   "1.2.3",
+			Commit:    "abc1234",
+			BuildTime: "2024-01-02T03:04:05Z",
+			GoVersion: "go1.23.3",
+		}, logger.Default())
+
+		resp := getHealth(t, checker)
+		assert.Equal(t, "1.2.3", resp["version"])
+
+		build, ok := resp["build"].(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, "abc1234", build["commit"])
+		assert.Equal(t, "2024-01-02T03:04:05Z", build["buildTime"])
+		assert.Equal(t, "go1.23.3", build["goVersion"])
+	})
+
+	// Test empty build fields are omitted
+	t.Run("Empty", func(t *testing.T) {
+		checker := health.NewHealthCheck("test-app", "Test", health.BuildInfo{Version: "dev"}, logger.Default())
+
+		resp := getHealth(t, checker)
+		assert.Equal(t, "dev", resp["version"])
+		assert.NotContains(t, resp, "build")
+	})
+
+	// Test only the fields that are set appear in the build object
+	t.Run("Partial", func(t *testing.T) {
+		checker := health.NewHealthCheck("test-app", "Test", health.BuildInfo{
+			Version:   "dev",
+			GoVersion: "go1.23.3",
+		}, logger.Default())
+
+		build, ok := getHealth(t, checker)["build"].(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, map[string]interface{}{"goVersion": "go1.23.3"}, build)
+	})
+}
+
+func TestVersionHandler(t *testing.T) {
+	checks := 0
+	checker := health.NewHealthCheck("test-app", "Test", health.BuildInfo{
+		Version:   "1.2.3",
+		Commit:    "abc1234",
+		BuildTime: "2024-01-02T03:04:05Z",
+		GoVersion: "go1.23.3",
+	}, logger.Default())
+	checker.AddCheck(func(context.Context) health.Component {
+		checks++
+		return health.Component{Name: "counted", Status: health.StatusUp}
+	})
+
+	w := httptest.NewRecorder()
+	checker.VersionHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	// Test the response has exactly the version fields
+	t.Run("Shape", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, map[string]interface{}{
+			"name":      "test-app",
+			"version":   "1.2.3",
+			"commit":    "abc1234",
+			"buildTime": "2024-01-02T03:04:05Z",
+		}, resp)
+	})
+
+	// Test no health checks were run
+	t.Run("NoChecks", func(t *testing.T) {
+		assert.Equal(t, 0, checks)
+	})
+}
+
+func TestDegradedIsReady(t *testing.T) {
+	// newDegraded creates a checker with a single degraded component
+	newDegraded := func() *health.Checker {
+		checker := health.NewHealthCheck("test-app", "Test", health.BuildInfo{Version: "dev"}, logger.Default())
+		checker.AddCheck(func(context.Context) health.Component {
+			return health.Component{Name: "degraded", Status: health.StatusDegraded}
+		})
+		return checker
+	}
+
+	// serve returns the status code of handler
+	serve := func(handler http.HandlerFunc, path string) int {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code
+	}
+
+	// Test a degraded component keeps readiness at 200 by default
+	t.Run("Default", func(t *testing.T) {
+		checker := newDegraded()
+
+		assert.Equal(t, http.StatusOK, serve(checker.ReadinessHandler(), "/health/readiness"))
+		assert.Equal(t, http.StatusOK, serve(checker.LivenessHandler(), "/health/liveness"))
+	})
+
+	// Test readiness fails on a degraded component when the policy is off,
+	// while liveness and /health are unchanged
+	t.Run("NotReady", func(t *testing.T) {
+		checker := newDegraded()
+		checker.SetDegradedIsReady(false)
+
+		assert.Equal(t, http.StatusServiceUnavailable, serve(checker.ReadinessHandler(), "/health/readiness"))
+		assert.Equal(t, http.StatusOK, serve(checker.LivenessHandler(), "/health/liveness"))
+		assert.Equal(t, http.StatusOK, serve(checker.HealthHandler(), "/health"))
+	})
+}
+
+func TestCheckTimeout(t *testing.T) {
+	checker := health.NewHealthCheck("test-app", "Test", health.BuildInfo{Version: "dev"}, logger.Default())
+
+	// A slow check that ignores its context
+	release := make(chan struct{})
+	defer close(release)
+	checker.AddCheckWithTimeout(func(context.Context) health.Component {
+		<-release
+		return health.Component{Name: "slow", Status: health.StatusUp}
+	}, 50*time.Millisecond)
+
+	checker.AddCheck(func(context.Context) health.Component {
+		return health.Component{Name: "fast", Status: health.StatusUp}
+	})
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	checker.HealthHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	elapsed := time.Since(start)
+
+	var resp health.StatusResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Components, 2)
+
+	// Test the slow check times out on its own budget rather than the default
+	t.Run("TimesOut", func(t *testing.T) {
+		assert.Less(t, elapsed, health.DefaultCheckTimeout)
+
+		slow := resp.Components[0]
+		assert.Equal(t, health.StatusDown, slow.Status)
+		assert.Equal(t, "timeout", slow.Details["error"])
+	})
+
+	// Test the other check still reports its own result
+	t.Run("OthersUnaffected", func(t *testing.T) {
+		assert.Equal(t, "fast", resp.Components[1].Name)
+		assert.Equal(t, health.StatusUp, resp.Components[1].Status)
+	})
+
+	// Test the aggregate is down
+	t.Run("Aggregate", func(t *testing.T) {
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, health.StatusDown, resp.Status)
+	})
+}
+
+func TestOAuth2Check(t *testing.T) {
+	// provider stubs an OAuth2 provider endpoint responding with status
+	provider := func(t *testing.T, status int) string {
+		t.Helper()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(status)
+		}))
+		t.Cleanup(srv.Close)
+		return srv.URL
+	}
+
+	// Test a reachable provider is UP, even when it rejects the request
+	t.Run("Reachable", func(t *testing.T) {
+		component := health.OAuth2Check("oauth2", provider(t, http.StatusUnauthorized), nil)(context.Background())
+
+		assert.Equal(t, "oauth2", component.Name)
+		assert.Equal(t, health.StatusUp, component.Status)
+		assert.Equal(t, http.StatusUnauthorized, component.Details["statusCode"])
+	})
+
+	// Test a provider responding 503 is DOWN
+	t.Run("Unavailable", func(t *testing.T) {
+		component := health.OAuth2Check("oauth2", provider(t, http.StatusServiceUnavailable), nil)(context.Background())
+
+		assert.Equal(t, health.StatusDown, component.Status)
+		assert.Equal(t, http.StatusServiceUnavailable, component.Details["statusCode"])
+	})
+
+	// Test an unreachable provider is DOWN
+	t.Run("Unreachable", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		srv.Close()
+
+		component := health.OAuth2Check("oauth2", srv.URL, nil)(context.Background())
+
+		assert.Equal(t, health.StatusDown, component.Status)
+		assert.Contains(t, component.Details, "error")
+	})
+}
@@ -0,0 +1,102 @@
+// Package httpclient provides an HTTP client for calling external services.
+// Every outbound request is traced as a client span, carries the trace
+// propagation headers, and is counted and timed in Prometheus metrics.
+package httpclient
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dBiTech/go-apiTemplate/pkg/metrics"
+	"github.com/dBiTech/go-apiTemplate/pkg/telemetry"
+)
+
+// New creates an HTTP client whose requests are traced with tel and recorded
+// in m. Either may be nil: without tel the global tracer provider is used,
+// and without m no metrics are recorded.
+func New(tel *telemetry.Telemetry, m *metrics.Metrics) *http.Client {
+	return &http.Client{Transport: NewTransport(http.DefaultTransport, tel, m)}
+}
+
+// NewTransport wraps next, or http.DefaultTransport if nil, with the tracing
+// and metrics of New, for clients that need their own transport settings
+func NewTransport(next http.RoundTripper, tel *telemetry.Telemetry, m *metrics.Metrics) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	t := &transport{next: next}
+	if tel != nil {
+		t.tracer = tel.Tracer("httpclient")
+	} else {
+		t.tracer = otel.Tracer("httpclient")
+	}
+
+	if m != nil {
+		t.requests = m.NewCounter("http_client_requests_total",
+			"Total number of outbound HTTP requests.", []string{"method", "host", "status"})
+		t.duration = m.NewHistogram("http_client_request_duration_seconds",
+			"Outbound HTTP request latencies in seconds.", []string{"method", "host"}, nil)
+	}
+
+	return t
+}
+
+// transport traces and measures requests sent through next
+type transport struct {
+	next   http.RoundTripper
+	tracer trace.Tracer
+
+	// requests and duration are nil when no metrics are recorded
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// RoundTrip sends the request inside a client span, with the span's context
+// injected into a copy of the request headers
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := t.tracer.Start(req.Context(), req.Method+" "+req.URL.Host,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.method", req.Method),
+			attribute.String("http.url", req.URL.Redacted()),
+			attribute.String("net.peer.name", req.URL.Host),
+		),
+	)
+	defer span.End()
+
+	// A RoundTripper must not modify the caller's request
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	elapsed := time.Since(start)
+
+	status := "error"
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		status = strconv.Itoa(resp.StatusCode)
+		span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+		if resp.StatusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+		}
+	}
+
+	if t.requests != nil {
+		t.requests.WithLabelValues(req.Method, req.URL.Host, status).Inc()
+		t.duration.WithLabelValues(req.Method, req.URL.Host).Observe(elapsed.Seconds())
+	}
+
+	return resp, err
+}
@@ -0,0 +1,77 @@
+package httpclient_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dBiTech/go-apiTemplate/pkg/httpclient"
+	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+	"github.com/dBiTech/go-apiTemplate/pkg/metrics"
+	"github.com/dBiTech/go-apiTemplate/pkg/telemetry"
+)
+
+func TestClient(t *testing.T) {
+	// Record spans from the global tracer provider, which disabled telemetry uses
+	recorder := tracetest.NewSpanRecorder()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	tel, err := telemetry.New(context.Background(), telemetry.Config{Enabled: false}, logger.Default())
+	require.NoError(t, err)
+	m := metrics.NewMetrics("test", metrics.WithRuntimeCollectors(false))
+
+	var traceparent string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	resp, err := httpclient.New(tel, m).Get(upstream.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+
+	// Test a client span is recorded for the request
+	t.Run("Span", func(t *testing.T) {
+		assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+		assert.Equal(t, "GET "+upstream.Listener.Addr().String(), span.Name())
+	})
+
+	// Test the span's context is propagated to the server
+	t.Run("Propagation", func(t *testing.T) {
+		require.NotEmpty(t, traceparent)
+		assert.Contains(t, traceparent, span.SpanContext().TraceID().String())
+		assert.Contains(t, traceparent, span.SpanContext().SpanID().String())
+	})
+
+	// Test the request is counted
+	t.Run("Metrics", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		body, err := io.ReadAll(w.Body)
+		require.NoError(t, err)
+
+		assert.Contains(t, string(body), `test_http_client_requests_total{host="`+upstream.Listener.Addr().String()+`",method="GET",status="200"} 1`)
+		assert.Contains(t, string(body), "test_http_client_request_duration_seconds_count")
+	})
+}
@@ -0,0 +1,272 @@
+package logger
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// logfmtEncoding is the zap encoding name of the logfmt encoder
+const logfmtEncoding = "logfmt"
+
+// logfmtTimeLayout matches the ISO8601 time of the JSON format
+const logfmtTimeLayout = "2006-01-02T15:04:05.000Z0700"
+
+func init() {
+	if err := zap.RegisterEncoder(logfmtEncoding, func(cfg zapcore.EncoderConfig) (zapcore.Encoder, error) {
+		return newLogfmtEncoder(cfg), nil
+	}); err != nil {
+		panic(err)
+	}
+}
+
+var logfmtPool = buffer.NewPool()
+
+// logfmtEncoder writes entries as logfmt, one line of space-separated
+// key=value pairs. Values containing spaces, quotes, equals signs or control
+// characters are quoted, and arrays, objects and reflected values are
+// written as quoted JSON. Keys of fields in a namespace are prefixed with the
+// namespace and a dot.
+type logfmtEncoder struct {
+	cfg zapcore.EncoderConfig
+
+	// buf holds the encoded context fields added with With
+	buf        *buffer.Buffer
+	namespaces []string
+}
+
+// newLogfmtEncoder creates a logfmt encoder using the keys in cfg
+func newLogfmtEncoder(cfg zapcore.EncoderConfig) *logfmtEncoder {
+	return &logfmtEncoder{cfg: cfg, buf: logfmtPool.Get()}
+}
+
+// Clone copies the encoder and its context fields
+func (e *logfmtEncoder) Clone() zapcore.Encoder {
+	clone := &logfmtEncoder{
+		cfg:        e.cfg,
+		buf:        logfmtPool.Get(),
+		namespaces: append([]string(nil), e.namespaces...),
+	}
+	_, _ = clone.buf.Write(e.buf.Bytes())
+	return clone
+}
+
+// EncodeEntry writes the entry's metadata, the context fields, then fields
+func (e *logfmtEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	line := &logfmtEncoder{cfg: e.cfg, buf: logfmtPool.Get()}
+
+	if e.cfg.TimeKey != "" {
+		line.AddString(e.cfg.TimeKey, ent.Time.Format(logfmtTimeLayout))
+	}
+	if e.cfg.LevelKey != "" {
+		line.AddString(e.cfg.LevelKey, ent.Level.String())
+	}
+	if e.cfg.NameKey != "" && ent.LoggerName != "" {
+		line.AddString(e.cfg.NameKey, ent.LoggerName)
+	}
+	if e.cfg.CallerKey != "" && ent.Caller.Defined {
+		line.AddString(e.cfg.CallerKey, ent.Caller.TrimmedPath())
+	}
+	if e.cfg.MessageKey != "" {
+		line.AddString(e.cfg.MessageKey, ent.Message)
+	}
+
+	if e.buf.Len() > 0 {
+		line.separate()
+		_, _ = line.buf.Write(e.buf.Bytes())
+	}
+	line.namespaces = append(line.namespaces, e.namespaces...)
+
+	for _, field := range fields {
+		field.AddTo(line)
+	}
+
+	if e.cfg.StacktraceKey != "" && ent.Stack != "" {
+		line.namespaces = nil
+		line.AddString(e.cfg.StacktraceKey, ent.Stack)
+	}
+
+	line.buf.AppendString(zapcore.DefaultLineEnding)
+	return line.buf, nil
+}
+
+// AddArray writes the array as quoted JSON
+func (e *logfmtEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	m := zapcore.NewMapObjectEncoder()
+	if err := m.AddArray(key, arr); err != nil {
+		return err
+	}
+	return e.AddReflected(key, m.Fields[key])
+}
+
+// AddObject writes the object as quoted JSON
+func (e *logfmtEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	m := zapcore.NewMapObjectEncoder()
+	if err := obj.MarshalLogObject(m); err != nil {
+		return err
+	}
+	return e.AddReflected(key, m.Fields)
+}
+
+// AddReflected writes the value as quoted JSON
+func (e *logfmtEncoder) AddReflected(key string, value interface{}) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	e.AddByteString(key, b)
+	return nil
+}
+
+// OpenNamespace prefixes the keys of the fields added after it with key
+func (e *logfmtEncoder) OpenNamespace(key string) {
+	e.namespaces = append(e.namespaces, key)
+}
+
+// AddBinary writes the bytes base64 encoded
+func (e *logfmtEncoder) AddBinary(key string, value []byte) {
+	e.AddString(key, base64.StdEncoding.EncodeToString(value))
+}
+
+// AddByteString writes UTF-8 bytes as a string
+func (e *logfmtEncoder) AddByteString(key string, value []byte) {
+	e.AddString(key, string(value))
+}
+
+// AddBool writes true or false
+func (e *logfmtEncoder) AddBool(key string, value bool) {
+	e.addKey(key)
+	e.buf.AppendBool(value)
+}
+
+// AddComplex128 writes the number in Go's complex syntax
+func (e *logfmtEncoder) AddComplex128(key string, value complex128) {
+	e.AddString(key, strconv.FormatComplex(value, 'g', -1, 128))
+}
+
+// AddComplex64 writes the number in Go's complex syntax
+func (e *logfmtEncoder) AddComplex64(key string, value complex64) {
+	e.AddString(key, strconv.FormatComplex(complex128(value), 'g', -1, 64))
+}
+
+// AddDuration writes the duration in Go's duration syntax, e.g. 1.5s
+func (e *logfmtEncoder) AddDuration(key string, value time.Duration) {
+	e.AddString(key, value.String())
+}
+
+// AddFloat64 writes the number, or NaN, +Inf or -Inf
+func (e *logfmtEncoder) AddFloat64(key string, value float64) {
+	e.addFloat(key, value, 64)
+}
+
+// AddFloat32 writes the number, or NaN, +Inf or -Inf
+func (e *logfmtEncoder) AddFloat32(key string, value float32) {
+	e.addFloat(key, float64(value), 32)
+}
+
+// AddInt writes the integer
+func (e *logfmtEncoder) AddInt(key string, value int) { e.AddInt64(key, int64(value)) }
+
+// AddInt32 writes the integer
+func (e *logfmtEncoder) AddInt32(key string, value int32) { e.AddInt64(key, int64(value)) }
+
+// AddInt16 writes the integer
+func (e *logfmtEncoder) AddInt16(key string, value int16) { e.AddInt64(key, int64(value)) }
+
+// AddInt8 writes the integer
+func (e *logfmtEncoder) AddInt8(key string, value int8) { e.AddInt64(key, int64(value)) }
+
+// AddInt64 writes the integer
+func (e *logfmtEncoder) AddInt64(key string, value int64) {
+	e.addKey(key)
+	e.buf.AppendInt(value)
+}
+
+// AddString writes the string, quoted if needed
+func (e *logfmtEncoder) AddString(key, value string) {
+	e.addKey(key)
+	if needsQuoting(value) {
+		e.buf.AppendString(strconv.Quote(value))
+	} else {
+		e.buf.AppendString(value)
+	}
+}
+
+// AddTime writes the time in the same layout as the entry time
+func (e *logfmtEncoder) AddTime(key string, value time.Time) {
+	e.AddString(key, value.Format(logfmtTimeLayout))
+}
+
+// AddUint writes the integer
+func (e *logfmtEncoder) AddUint(key string, value uint) { e.AddUint64(key, uint64(value)) }
+
+// AddUint32 writes the integer
+func (e *logfmtEncoder) AddUint32(key string, value uint32) { e.AddUint64(key, uint64(value)) }
+
+// AddUint16 writes the integer
+func (e *logfmtEncoder) AddUint16(key string, value uint16) { e.AddUint64(key, uint64(value)) }
+
+// AddUint8 writes the integer
+func (e *logfmtEncoder) AddUint8(key string, value uint8) { e.AddUint64(key, uint64(value)) }
+
+// AddUintptr writes the integer
+func (e *logfmtEncoder) AddUintptr(key string, value uintptr) { e.AddUint64(key, uint64(value)) }
+
+// AddUint64 writes the integer
+func (e *logfmtEncoder) AddUint64(key string, value uint64) {
+	e.addKey(key)
+	e.buf.AppendUint(value)
+}
+
+// addFloat writes a float of the given bit size
+func (e *logfmtEncoder) addFloat(key string, value float64, bitSize int) {
+	e.addKey(key)
+	switch {
+	case math.IsNaN(value):
+		e.buf.AppendString("NaN")
+	case math.IsInf(value, 1):
+		e.buf.AppendString("+Inf")
+	case math.IsInf(value, -1):
+		e.buf.AppendString("-Inf")
+	default:
+		e.buf.AppendFloat(value, bitSize)
+	}
+}
+
+// addKey writes the separator and the key, prefixed with any namespaces
+func (e *logfmtEncoder) addKey(key string) {
+	e.separate()
+	for _, ns := range e.namespaces {
+		e.buf.AppendString(ns)
+		e.buf.AppendByte('.')
+	}
+	e.buf.AppendString(key)
+	e.buf.AppendByte('=')
+}
+
+// separate writes a space unless the buffer is empty
+func (e *logfmtEncoder) separate() {
+	if e.buf.Len() > 0 {
+		e.buf.AppendByte(' ')
+	}
+}
+
+// needsQuoting reports whether a value must be quoted to be read back as one
+// logfmt value
+func needsQuoting(s string) bool {
+	if s == "" || !utf8.ValidString(s) {
+		return true
+	}
+	return strings.IndexFunc(s, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || unicode.IsControl(r)
+	}) >= 0
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLogfmtEncoder(t *testing.T) {
+	enc := newLogfmtEncoder(zap.NewProductionEncoderConfig())
+	entry := zapcore.Entry{
+		Level:   zapcore.InfoLevel,
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Message: "request handled",
+	}
+
+	// encode encodes the entry with enc and returns the line
+	encode := func(t *testing.T, enc zapcore.Encoder, fields ...Field) string {
+		t.Helper()
+
+		buf, err := enc.EncodeEntry(entry, fields)
+		require.NoError(t, err)
+		defer buf.Free()
+		return buf.String()
+	}
+
+	// Test a message with a couple of fields is written as key=value pairs
+	t.Run("Fields", func(t *testing.T) {
+		line := encode(t, enc, String("method", "GET"), Int("status", 200))
+
+		assert.Equal(t, `ts=2024-01-02T03:04:05.000Z level=info msg="request handled" method=GET status=200`+"\n", line)
+	})
+
+	// Test values that would break the pair are quoted
+	t.Run("Quoting", func(t *testing.T) {
+		line := encode(t, enc, String("path", "/a b"), String("empty", ""), Error(errors.New(`bad "x"`)))
+
+		assert.Contains(t, line, ` path="/a b" empty="" error="bad \"x\""`)
+	})
+
+	// Test context fields come before the entry's fields
+	t.Run("Context", func(t *testing.T) {
+		withCtx := enc.Clone()
+		String("request_id", "abc").AddTo(withCtx)
+
+		line := encode(t, withCtx, Bool("cached", true))
+
+		assert.Contains(t, line, ` msg="request handled" request_id=abc cached=true`)
+		assert.NotContains(t, encode(t, enc), "request_id")
+	})
+
+}
@@ -4,7 +4,9 @@ package logger
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -22,6 +24,24 @@ type Logger interface {
 	WithContext(ctx context.Context) Logger
 }
 
+// LevelSetter is implemented by loggers whose level can be changed at runtime
+type LevelSetter interface {
+	SetLevel(level string) error
+}
+
+// DebugChecker is implemented by loggers that can report whether they emit
+// debug entries
+type DebugChecker interface {
+	DebugEnabled() bool
+}
+
+// DebugEnabled reports whether log emits debug entries. It is false for
+// loggers that don't implement DebugChecker.
+func DebugEnabled(log Logger) bool {
+	checker, ok := log.(DebugChecker)
+	return ok && checker.DebugEnabled()
+}
+
 // Field defines a log field
 type Field = zapcore.Field
 
@@ -50,6 +70,11 @@ func Bool(key string, value bool) Field {
 	return zap.Bool(key, value)
 }
 
+// Float64 creates a float64 field
+func Float64(key string, value float64) Field {
+	return zap.Float64(key, value)
+}
+
 // Duration creates a duration field
 func Duration(key string, value time.Duration) Field {
 	return zap.Duration(key, value)
@@ -62,34 +87,59 @@ func Any(key string, value interface{}) Field {
 
 type loggerImpl struct {
 	logger *zap.Logger
+	level  zap.AtomicLevel
 }
 
-// New creates a new logger instance
-func New(level, format string) (Logger, error) {
+// New creates a new logger instance in the json, logfmt or text format; any
+// other format is an error. In the JSON and logfmt formats every entry carries
+// its caller, and Error and Fatal entries a stacktrace. Any opts are applied
+// to the underlying zap logger after the defaults.
+func New(level, format string, opts ...zap.Option) (Logger, error) {
 	var zapLevel zapcore.Level
 	if err := zapLevel.UnmarshalText([]byte(level)); err != nil {
 		zapLevel = zapcore.InfoLevel
 	}
 
+	buildOpts := []zap.Option{
+		zap.AddCaller(),
+		zap.AddCallerSkip(1),
+	}
+
 	var config zap.Config
-	if format == "json" {
+	switch format {
+	case "json", logfmtEncoding:
 		config = zap.NewProductionConfig()
+		config.Encoding = format
 		config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-	} else {
+		config.EncoderConfig.CallerKey = "caller"
+		config.EncoderConfig.StacktraceKey = "stacktrace"
+		config.DisableCaller = false
+
+		// Stacktraces are added explicitly so they don't depend on the
+		// preset's defaults
+		config.DisableStacktrace = true
+		buildOpts = append(buildOpts, zap.AddStacktrace(zapcore.ErrorLevel))
+	case "text":
 		config = zap.NewDevelopmentConfig()
 		config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
 	}
-	config.Level = zap.NewAtomicLevelAt(zapLevel)
+	atomicLevel := zap.NewAtomicLevelAt(zapLevel)
+	config.Level = atomicLevel
 
-	logger, err := config.Build(
-		zap.AddCaller(),
-		zap.AddCallerSkip(1),
-	)
+	logger, err := config.Build(append(buildOpts, opts...)...)
 	if err != nil {
 		return nil, err
 	}
 
-	return &loggerImpl{logger: logger}, nil
+	return &loggerImpl{logger: logger, level: atomicLevel}, nil
+}
+
+// NewFromZap creates a logger backed by an existing zap logger. The zap
+// logger's own level applies, so SetLevel has no effect on it.
+func NewFromZap(zapLogger *zap.Logger) Logger {
+	return &loggerImpl{logger: zapLogger, level: zap.NewAtomicLevelAt(zapLogger.Level())}
 }
 
 // Default returns a default logger instance
@@ -99,7 +149,7 @@ func Default() Logger {
 		// If we can't create a logger, create a minimal one
 		config := zap.NewProductionConfig()
 		zapLogger, _ := config.Build()
-		return &loggerImpl{logger: zapLogger}
+		return &loggerImpl{logger: zapLogger, level: config.Level}
 	}
 	return logger
 }
@@ -126,7 +176,17 @@ func (l *loggerImpl) Fatal(msg string, fields ...Field) {
 }
 
 func (l *loggerImpl) With(fields ...Field) Logger {
-	return &loggerImpl{logger: l.logger.With(fields...)}
+	return &loggerImpl{logger: l.logger.With(fields...), level: l.level}
+}
+
+// SetLevel changes the level of the logger and every logger derived from it
+func (l *loggerImpl) SetLevel(level string) error {
+	return l.level.UnmarshalText([]byte(level))
+}
+
+// DebugEnabled reports whether debug entries are emitted at the current level
+func (l *loggerImpl) DebugEnabled() bool {
+	return l.logger.Core().Enabled(zapcore.DebugLevel)
 }
 
 func (l *loggerImpl) WithContext(_ context.Context) Logger {
@@ -140,15 +200,39 @@ type ContextKey string
 
 const loggerKey ContextKey = "logger"
 
+// contextLogger holds the logger stored in a context. Every context derived
+// from that one shares it, so fields added with AddFields are also seen by
+// middleware that stored the logger further up the chain.
+type contextLogger struct {
+	mu     sync.RWMutex
+	logger Logger
+}
+
 // FromContext extracts a logger from a context
 func FromContext(ctx context.Context) Logger {
-	if logger, ok := ctx.Value(loggerKey).(Logger); ok {
-		return logger
+	if holder, ok := ctx.Value(loggerKey).(*contextLogger); ok {
+		holder.mu.RLock()
+		defer holder.mu.RUnlock()
+		return holder.logger
 	}
 	return Default()
 }
 
 // ToContext adds a logger to a context
 func ToContext(ctx context.Context, logger Logger) context.Context {
-	return context.WithValue(ctx, loggerKey, logger)
+	return context.WithValue(ctx, loggerKey, &contextLogger{logger: logger})
+}
+
+// AddFields adds fields to the logger stored in ctx, updating it in place. It
+// reports false if ctx has no logger.
+func AddFields(ctx context.Context, fields ...Field) bool {
+	holder, ok := ctx.Value(loggerKey).(*contextLogger)
+	if !ok {
+		return false
+	}
+
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+	holder.logger = holder.logger.With(fields...)
+	return true
 }
@@ -0,0 +1,48 @@
+package logger_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+)
+
+func TestJSONStacktrace(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	log, err := logger.New("debug", "json", zap.WrapCore(func(zapcore.Core) zapcore.Core {
+		return core
+	}))
+	require.NoError(t, err)
+
+	log.Info("informational")
+	log.Error("failure", logger.Error(errors.New("boom")))
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 2)
+
+	// Test an info entry has a caller but no stacktrace
+	t.Run("Info", func(t *testing.T) {
+		assert.Empty(t, entries[0].Stack)
+		assert.True(t, entries[0].Caller.Defined)
+		assert.Contains(t, entries[0].Caller.File, "logger_test.go")
+	})
+
+	// Test an error entry has a stacktrace and a caller
+	t.Run("Error", func(t *testing.T) {
+		assert.NotEmpty(t, entries[1].Stack)
+		assert.True(t, entries[1].Caller.Defined)
+		assert.Contains(t, entries[1].Caller.File, "logger_test.go")
+	})
+}
+
+func TestUnknownFormat(t *testing.T) {
+	_, err := logger.New("info", "xml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"xml"`)
+}
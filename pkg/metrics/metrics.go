@@ -3,10 +3,12 @@
 package metrics
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -15,6 +17,7 @@ import (
 
 // Metrics holds all metrics instances
 type Metrics struct {
+	namespace            string
 	registry             *prometheus.Registry
 	httpRequestsTotal    *prometheus.CounterVec
 	httpRequestDuration  *prometheus.HistogramVec
@@ -23,8 +26,102 @@ type Metrics struct {
 	httpRequestSize      *prometheus.HistogramVec
 }
 
+// Option configures optional behavior of a Metrics instance
+type Option func(*options)
+
+// options holds the settings applied by Option functions
+type options struct {
+	goCollector         bool
+	processCollector    bool
+	buildInfo           prometheus.Labels
+	durationBuckets     []float64
+	requestSizeBuckets  []float64
+	responseSizeBuckets []float64
+}
+
+// defaultOptions returns the options used when none are provided
+func defaultOptions() options {
+	return options{
+		goCollector:         true,
+		processCollector:    true,
+		durationBuckets:     []float64{0.001, 0.01, 0.1, 0.5, 1, 2, 5, 10},
+		requestSizeBuckets:  []float64{100, 1000, 10000, 100000, 1000000},
+		responseSizeBuckets: []float64{100, 1000, 10000, 100000, 1000000},
+	}
+}
+
+// WithDurationBuckets sets the buckets, in seconds, of the request duration
+// histogram. An empty slice keeps the defaults.
+func WithDurationBuckets(buckets []float64) Option {
+	return func(o *options) {
+		if len(buckets) > 0 {
+			o.durationBuckets = buckets
+		}
+	}
+}
+
+// WithRequestSizeBuckets sets the buckets, in bytes, of the request size
+// histogram. An empty slice keeps the defaults.
+func WithRequestSizeBuckets(buckets []float64) Option {
+	return func(o *options) {
+		if len(buckets) > 0 {
+			o.requestSizeBuckets = buckets
+		}
+	}
+}
+
+// WithResponseSizeBuckets sets the buckets, in bytes, of the response size
+// histogram. An empty slice keeps the defaults.
+func WithResponseSizeBuckets(buckets []float64) Option {
+	return func(o *options) {
+		if len(buckets) > 0 {
+			o.responseSizeBuckets = buckets
+		}
+	}
+}
+
+// WithGoCollector enables or disables the Go runtime collector (go_* series)
+func WithGoCollector(enabled bool) Option {
+	return func(o *options) {
+		o.goCollector = enabled
+	}
+}
+
+// WithProcessCollector enables or disables the process collector (process_* series)
+func WithProcessCollector(enabled bool) Option {
+	return func(o *options) {
+		o.processCollector = enabled
+	}
+}
+
+// WithRuntimeCollectors enables or disables both the Go runtime and process
+// collectors, e.g. when they're registered elsewhere
+func WithRuntimeCollectors(enabled bool) Option {
+	return func(o *options) {
+		o.goCollector = enabled
+		o.processCollector = enabled
+	}
+}
+
+// WithBuildInfo registers a build_info gauge set to 1, labeled with the
+// running build so dashboards can annotate deploys
+func WithBuildInfo(version, commit, goVersion string) Option {
+	return func(o *options) {
+		o.buildInfo = prometheus.Labels{
+			"version":   version,
+			"commit":    commit,
+			"goversion": goVersion,
+		}
+	}
+}
+
 // NewMetrics creates a new metrics instance
-func NewMetrics(namespace string) *Metrics {
+func NewMetrics(namespace string, opts ...Option) *Metrics {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	registry := prometheus.NewRegistry()
 
 	httpRequestsTotal := promauto.With(registry).NewCounterVec(
@@ -41,7 +138,7 @@ func NewMetrics(namespace string) *Metrics {
 			Namespace: namespace,
 			Name:      "http_request_duration_seconds",
 			Help:      "Duration of HTTP requests in seconds.",
-			Buckets:   []float64{0.001, 0.01, 0.1, 0.5, 1, 2, 5, 10},
+			Buckets:   o.durationBuckets,
 		},
 		[]string{"method", "path", "status"},
 	)
@@ -60,7 +157,7 @@ func NewMetrics(namespace string) *Metrics {
 			Namespace: namespace,
 			Name:      "http_response_size_bytes",
 			Help:      "Size of HTTP responses in bytes.",
-			Buckets:   []float64{100, 1000, 10000, 100000, 1000000},
+			Buckets:   o.responseSizeBuckets,
 		},
 		[]string{"method", "path", "status"},
 	)
@@ -70,18 +167,30 @@ func NewMetrics(namespace string) *Metrics {
 			Namespace: namespace,
 			Name:      "http_request_size_bytes",
 			Help:      "Size of HTTP requests in bytes.",
-			Buckets:   []float64{100, 1000, 10000, 100000, 1000000},
+			Buckets:   o.requestSizeBuckets,
 		},
 		[]string{"method", "path"},
 	)
 
+	if o.buildInfo != nil {
+		promauto.With(registry).NewGauge(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Name:        "build_info",
+			Help:        "Build information of the running binary, always 1.",
+			ConstLabels: o.buildInfo,
+		}).Set(1)
+	}
+
 	// Register default Go collectors
-	registry.MustRegister(
-		collectors.NewGoCollector(),
-		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
-	)
+	if o.goCollector {
+		registry.MustRegister(collectors.NewGoCollector())
+	}
+	if o.processCollector {
+		registry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	}
 
 	return &Metrics{
+		namespace:            namespace,
 		registry:             registry,
 		httpRequestsTotal:    httpRequestsTotal,
 		httpRequestDuration:  httpRequestDuration,
@@ -96,19 +205,68 @@ func (m *Metrics) Handler() http.Handler {
 	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
 }
 
+// NewCounter registers a labeled counter against the metrics registry and returns it.
+// Registering the same counter twice returns the previously registered collector.
+func (m *Metrics) NewCounter(name, help string, labels []string) *prometheus.CounterVec {
+	counter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: m.namespace,
+			Name:      name,
+			Help:      help,
+		},
+		labels,
+	)
+
+	if err := m.registry.Register(counter); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(*prometheus.CounterVec); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+
+	return counter
+}
+
+// NewHistogram registers a labeled histogram against the metrics registry and returns it.
+// When buckets is empty the Prometheus default buckets are used.
+// Registering the same histogram twice returns the previously registered collector.
+func (m *Metrics) NewHistogram(name, help string, labels []string, buckets []float64) *prometheus.HistogramVec {
+	histogram := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: m.namespace,
+			Name:      name,
+			Help:      help,
+			Buckets:   buckets,
+		},
+		labels,
+	)
+
+	if err := m.registry.Register(histogram); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(*prometheus.HistogramVec); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+
+	return histogram
+}
+
 // InstrumentHandler wraps an HTTP handler with metrics collection
 func (m *Metrics) InstrumentHandler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		path := r.URL.Path
 		method := r.Method
 
-		// Track request size
-		requestSize := computeApproximateRequestSize(r)
-		m.httpRequestSize.WithLabelValues(method, path).Observe(float64(requestSize))
-
-		// Track in-flight requests
-		m.httpRequestsInFlight.WithLabelValues(method, path).Inc()
-		defer m.httpRequestsInFlight.WithLabelValues(method, path).Dec()
+		// Track in-flight requests by the route they're about to be routed
+		// to. The deferred decrement also runs if the handler panics.
+		inFlight := m.httpRequestsInFlight.WithLabelValues(method, pendingRoutePattern(r))
+		inFlight.Inc()
+		defer inFlight.Dec()
 
 		// Track response size and status code
 		rw := newResponseWriter(w)
@@ -119,12 +277,47 @@ func (m *Metrics) InstrumentHandler(next http.Handler) http.Handler {
 		duration := time.Since(startTime).Seconds()
 		statusCode := strconv.Itoa(rw.statusCode)
 
+		// The route pattern is only known once the router has matched the request
+		path := routePattern(r)
+
+		// Track request size
+		requestSize := computeApproximateRequestSize(r)
+		m.httpRequestSize.WithLabelValues(method, path).Observe(float64(requestSize))
+
 		m.httpRequestsTotal.WithLabelValues(method, path, statusCode).Inc()
 		m.httpRequestDuration.WithLabelValues(method, path, statusCode).Observe(duration)
 		m.httpResponseSize.WithLabelValues(method, path, statusCode).Observe(float64(rw.size))
 	})
 }
 
+// routePattern returns the matched chi route pattern for the request,
+// falling back to the raw URL path when no route was matched
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return r.URL.Path
+}
+
+// pendingRoutePattern returns the chi route pattern the request will be
+// routed to. Router-level middleware runs before routing, so the pattern is
+// looked up against the router when it hasn't been matched yet.
+func pendingRoutePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+		if rctx.Routes != nil {
+			if pattern := rctx.Routes.Find(chi.NewRouteContext(), r.Method, r.URL.Path); pattern != "" {
+				return pattern
+			}
+		}
+	}
+	return r.URL.Path
+}
+
 // responseWriter is a wrapper for http.ResponseWriter that stores status code and response size
 type responseWriter struct {
 	http.ResponseWriter
@@ -150,6 +343,19 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return size, err
 }
 
+// Flush implements http.Flusher if the underlying ResponseWriter supports it,
+// so streamed responses reach the client as they're written
+func (rw *responseWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // computeApproximateRequestSize returns the approximate request size in bytes
 func computeApproximateRequestSize(r *http.Request) int {
 	size := 0
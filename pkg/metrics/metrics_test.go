@@ -0,0 +1,251 @@
+package metrics_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dBiTech/go-apiTemplate/pkg/metrics"
+)
+
+// scrape returns the text exposition output of the metrics handler
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	w := httptest.NewRecorder()
+
+	m.Handler().ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+
+	return string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	// Test default collectors
+	t.Run("DefaultCollectors", func(t *testing.T) {
+		m := metrics.NewMetrics("test")
+
+		output := scrape(t, m)
+		assert.Contains(t, output, "go_goroutines")
+		assert.Contains(t, output, "process_")
+	})
+
+	// Test the build info gauge carries the build labels
+	t.Run("BuildInfo", func(t *testing.T) {
+		m := metrics.NewMetrics("test", metrics.WithBuildInfo("1.2.3", "abc123", "go1.23.3"))
+
+		output := scrape(t, m)
+		assert.Contains(t, output, `test_build_info{commit="abc123",goversion="go1.23.3",version="1.2.3"} 1`)
+	})
+
+	// Test the build info gauge is only registered when configured
+	t.Run("NoBuildInfo", func(t *testing.T) {
+		assert.NotContains(t, scrape(t, metrics.NewMetrics("test")), "build_info")
+	})
+
+	// Test disabling the Go collector
+	t.Run("GoCollectorDisabled", func(t *testing.T) {
+		m := metrics.NewMetrics("test", metrics.WithGoCollector(false))
+
+		output := scrape(t, m)
+		assert.NotContains(t, output, "go_")
+		assert.Contains(t, output, "process_")
+	})
+
+	// Test disabling the process collector
+	t.Run("ProcessCollectorDisabled", func(t *testing.T) {
+		m := metrics.NewMetrics("test", metrics.WithProcessCollector(false))
+
+		output := scrape(t, m)
+		assert.Contains(t, output, "go_goroutines")
+		assert.NotContains(t, output, "process_")
+	})
+
+	// Test disabling both collectors
+	t.Run("AllCollectorsDisabled", func(t *testing.T) {
+		m := metrics.NewMetrics("test",
+			metrics.WithGoCollector(false),
+			metrics.WithProcessCollector(false),
+		)
+
+		output := scrape(t, m)
+		assert.NotContains(t, output, "go_")
+		assert.NotContains(t, output, "process_")
+	})
+
+	// Test disabling the runtime collectors together
+	t.Run("RuntimeCollectorsDisabled", func(t *testing.T) {
+		m := metrics.NewMetrics("test", metrics.WithRuntimeCollectors(false))
+
+		output := scrape(t, m)
+		assert.NotContains(t, output, "go_goroutines")
+		assert.NotContains(t, output, "process_")
+	})
+
+	// Test that requests are labeled with the route pattern
+	t.Run("RoutePatternLabel", func(t *testing.T) {
+		m := metrics.NewMetrics("test")
+
+		router := chi.NewRouter()
+		router.Use(m.InstrumentHandler)
+		router.Get("/examples/{id}", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+		for _, id := range []string{"abc", "def"} {
+			req := httptest.NewRequest(http.MethodGet, "/examples/"+id, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+		}
+
+		output := scrape(t, m)
+		assert.Contains(t, output, `test_http_requests_total{method="GET",path="/examples/{id}",status="200"} 2`)
+		for _, line := range strings.Split(output, "\n") {
+			if strings.HasPrefix(line, "test_http_requests_total") {
+				assert.NotContains(t, line, "/examples/abc")
+				assert.NotContains(t, line, "/examples/def")
+			}
+		}
+	})
+
+	// Test that unmatched requests fall back to the raw path
+	t.Run("RawPathFallback", func(t *testing.T) {
+		m := metrics.NewMetrics("test")
+
+		handler := m.InstrumentHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/unrouted", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		output := scrape(t, m)
+		var found bool
+		for _, line := range strings.Split(output, "\n") {
+			if strings.HasPrefix(line, "test_http_requests_total") && strings.Contains(line, `path="/unrouted"`) {
+				found = true
+			}
+		}
+		assert.True(t, found)
+	})
+
+	// Test registering and incrementing a custom counter
+	t.Run("NewCounter", func(t *testing.T) {
+		m := metrics.NewMetrics("test")
+
+		counter := m.NewCounter("widgets_total", "Total number of widgets.", []string{"kind"})
+		counter.WithLabelValues("blue").Inc()
+		counter.WithLabelValues("blue").Inc()
+
+		// Registering again returns the same collector
+		again := m.NewCounter("widgets_total", "Total number of widgets.", []string{"kind"})
+		again.WithLabelValues("blue").Inc()
+
+		output := scrape(t, m)
+		assert.Contains(t, output, `test_widgets_total{kind="blue"} 3`)
+	})
+
+	// Test the HTTP histograms use custom buckets
+	t.Run("CustomBuckets", func(t *testing.T) {
+		m := metrics.NewMetrics("test",
+			metrics.WithDurationBuckets([]float64{0.005, 0.025, 0.05}),
+			metrics.WithRequestSizeBuckets([]float64{64}),
+			metrics.WithResponseSizeBuckets([]float64{512, 4096}),
+		)
+
+		r := chi.NewRouter()
+		r.Use(m.InstrumentHandler)
+		r.Get("/buckets", func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		})
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/buckets", nil))
+
+		output := scrape(t, m)
+		assert.Contains(t, output, `test_http_request_duration_seconds_bucket{method="GET",path="/buckets",status="200",le="0.025"}`)
+		assert.NotContains(t, output, `test_http_request_duration_seconds_bucket{method="GET",path="/buckets",status="200",le="0.1"}`)
+		assert.Contains(t, output, `test_http_request_size_bytes_bucket{method="GET",path="/buckets",le="64"}`)
+		assert.Contains(t, output, `test_http_response_size_bytes_bucket{method="GET",path="/buckets",status="200",le="4096"}`)
+	})
+
+	// Test registering and observing a custom histogram
+	t.Run("NewHistogram", func(t *testing.T) {
+		m := metrics.NewMetrics("test")
+
+		histogram := m.NewHistogram("widget_size", "Size of widgets.", []string{"kind"}, []float64{1, 10})
+		histogram.WithLabelValues("blue").Observe(5)
+
+		output := scrape(t, m)
+		assert.Contains(t, output, `test_widget_size_bucket{kind="blue",le="1"} 0`)
+		assert.Contains(t, output, `test_widget_size_bucket{kind="blue",le="10"} 1`)
+		assert.Contains(t, output, `test_widget_size_count{kind="blue"} 1`)
+	})
+}
+
+func TestInFlight(t *testing.T) {
+	m := metrics.NewMetrics("test", metrics.WithGoCollector(false), metrics.WithProcessCollector(false))
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+
+	router := chi.NewRouter()
+	router.Use(m.InstrumentHandler)
+	router.Get("/slow/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		entered <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+	router.Get("/panic/{id}", func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	})
+
+	// Test concurrent requests are counted under their route pattern
+	t.Run("Concurrent", func(t *testing.T) {
+		const requests = 3
+
+		var wg sync.WaitGroup
+		for i := 0; i < requests; i++ {
+			wg.Add(1)
+			go func(id int) {
+				defer wg.Done()
+				req := httptest.NewRequest(http.MethodGet, "/slow/"+strconv.Itoa(id), nil)
+				router.ServeHTTP(httptest.NewRecorder(), req)
+			}(i)
+		}
+		for i := 0; i < requests; i++ {
+			<-entered
+		}
+
+		output := scrape(t, m)
+		assert.Contains(t, output, `test_http_requests_in_flight{method="GET",path="/slow/{id}"} 3`)
+		assert.NotContains(t, output, `path="/slow/0"`)
+
+		close(release)
+		wg.Wait()
+
+		assert.Contains(t, scrape(t, m), `test_http_requests_in_flight{method="GET",path="/slow/{id}"} 0`)
+	})
+
+	// Test a panicking handler still decrements the gauge
+	t.Run("Panic", func(t *testing.T) {
+		func() {
+			defer func() { _ = recover() }()
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic/1", nil))
+		}()
+
+		assert.Contains(t, scrape(t, m), `test_http_requests_in_flight{method="GET",path="/panic/{id}"} 0`)
+	})
+}
@@ -4,12 +4,20 @@ package telemetry
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
@@ -21,6 +29,7 @@ import (
 // Telemetry holds the tracer provider and other telemetry components
 type Telemetry struct {
 	tracerProvider *sdktrace.TracerProvider
+	meterProvider  *sdkmetric.MeterProvider
 	log            logger.Logger
 }
 
@@ -31,18 +40,95 @@ type Config struct {
 	Environment    string
 	Endpoint       string
 	Enabled        bool
+	MetricsEnabled bool   // Export metrics over OTLP to the same endpoint as traces
+	Sampler        string // Sampler spec: "always", "never", or "ratio:<0..1>"
+	Protocol       string // OTLP protocol for traces and metrics: "grpc" (default) or "http"
+	Insecure       bool   // Disable TLS for the OTLP connection
 }
 
-// New creates a new telemetry instance
+// newTraceClient creates an OTLP trace client for the configured protocol
+func newTraceClient(cfg Config) (otlptrace.Client, error) {
+	switch cfg.Protocol {
+	case "", "grpc":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.NewClient(opts...), nil
+	case "http":
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.NewClient(opts...), nil
+	default:
+		return nil, fmt.Errorf("unknown OTLP protocol %q: expected grpc or http", cfg.Protocol)
+	}
+}
+
+// newMetricExporter creates an OTLP metric exporter for the configured protocol
+func newMetricExporter(ctx context.Context, cfg Config) (sdkmetric.Exporter, error) {
+	switch cfg.Protocol {
+	case "", "grpc":
+		opts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+		return otlpmetricgrpc.New(ctx, opts...)
+	case "http":
+		opts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		}
+		return otlpmetrichttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown OTLP protocol %q: expected grpc or http", cfg.Protocol)
+	}
+}
+
+// ParseSampler converts a sampler spec into a trace sampler.
+// Supported specs are "always" (the default when empty), "never", and
+// "ratio:<fraction>" where fraction is between 0 and 1.
+func ParseSampler(spec string) (sdktrace.Sampler, error) {
+	switch spec {
+	case "", "always":
+		return sdktrace.AlwaysSample(), nil
+	case "never":
+		return sdktrace.NeverSample(), nil
+	}
+
+	ratioStr, ok := strings.CutPrefix(spec, "ratio:")
+	if !ok {
+		return nil, fmt.Errorf("unknown sampler %q: expected always, never, or ratio:<fraction>", spec)
+	}
+
+	ratio, err := strconv.ParseFloat(ratioStr, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sampler ratio %q: %w", ratioStr, err)
+	}
+	if ratio < 0 || ratio > 1 {
+		return nil, fmt.Errorf("invalid sampler ratio %q: must be between 0 and 1", ratioStr)
+	}
+
+	return sdktrace.TraceIDRatioBased(ratio), nil
+}
+
+// New creates a new telemetry instance. Tracing and OTLP metrics export are
+// enabled independently by cfg.Enabled and cfg.MetricsEnabled.
 func New(ctx context.Context, cfg Config, log logger.Logger) (*Telemetry, error) {
-	if !cfg.Enabled {
+	telemetry := &Telemetry{log: log}
+
+	if !cfg.Enabled && !cfg.MetricsEnabled {
 		log.Info("telemetry is disabled")
-		return &Telemetry{log: log}, nil
+		return telemetry, nil
 	}
 
 	log.Info("initializing telemetry",
 		logger.String("serviceName", cfg.ServiceName),
-		logger.String("endpoint", cfg.Endpoint))
+		logger.String("endpoint", cfg.Endpoint),
+		logger.Bool("tracing", cfg.Enabled),
+		logger.Bool("metrics", cfg.MetricsEnabled),
+		logger.String("sampler", cfg.Sampler))
 
 	// Create a resource describing the service
 	res, err := resource.New(ctx,
@@ -56,50 +142,98 @@ func New(ctx context.Context, cfg Config, log logger.Logger) (*Telemetry, error)
 		return nil, err
 	}
 
+	if cfg.Enabled {
+		tracerProvider, err := newTracerProvider(ctx, cfg, res)
+		if err != nil {
+			return nil, err
+		}
+
+		// Set global trace provider
+		otel.SetTracerProvider(tracerProvider)
+
+		// Set global propagator
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+			propagation.TraceContext{},
+			propagation.Baggage{},
+		))
+
+		telemetry.tracerProvider = tracerProvider
+	}
+
+	if cfg.MetricsEnabled {
+		meterProvider, err := newMeterProvider(ctx, cfg, res)
+		if err != nil {
+			_ = telemetry.Shutdown(ctx)
+			return nil, err
+		}
+
+		// Set global meter provider
+		otel.SetMeterProvider(meterProvider)
+
+		telemetry.meterProvider = meterProvider
+	}
+
+	return telemetry, nil
+}
+
+// newTracerProvider creates a tracer provider exporting spans over OTLP
+func newTracerProvider(ctx context.Context, cfg Config, res *resource.Resource) (*sdktrace.TracerProvider, error) {
+	sampler, err := ParseSampler(cfg.Sampler)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create OTLP exporter
-	client := otlptracegrpc.NewClient(
-		otlptracegrpc.WithEndpoint(cfg.Endpoint),
-		otlptracegrpc.WithInsecure(),
-	)
+	client, err := newTraceClient(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	exporter, err := otlptrace.New(ctx, client)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create trace provider
-	tracerProvider := sdktrace.NewTracerProvider(
+	return sdktrace.NewTracerProvider(
 		sdktrace.WithBatcher(exporter),
 		sdktrace.WithResource(res),
-		sdktrace.WithSampler(sdktrace.AlwaysSample()),
-	)
-
-	// Set global trace provider
-	otel.SetTracerProvider(tracerProvider)
+		sdktrace.WithSampler(sampler),
+	), nil
+}
 
-	// Set global propagator
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
-		propagation.TraceContext{},
-		propagation.Baggage{},
-	))
+// newMeterProvider creates a meter provider exporting metrics over OTLP
+func newMeterProvider(ctx context.Context, cfg Config, res *resource.Resource) (*sdkmetric.MeterProvider, error) {
+	// Create OTLP metric exporter
+	metricExporter, err := newMetricExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	return &Telemetry{
-		tracerProvider: tracerProvider,
-		log:            log,
-	}, nil
+	return sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)),
+		sdkmetric.WithResource(res),
+	), nil
 }
 
-// Shutdown shuts down the tracer provider
+// Shutdown shuts down the tracer and meter providers
 func (t *Telemetry) Shutdown(ctx context.Context) error {
-	if t.tracerProvider == nil {
+	if t.tracerProvider == nil && t.meterProvider == nil {
 		return nil
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
 	defer cancel()
 
-	if err := t.tracerProvider.Shutdown(ctx); err != nil {
-		return err
+	if t.tracerProvider != nil {
+		if err := t.tracerProvider.Shutdown(ctx); err != nil {
+			return err
+		}
+	}
+
+	if t.meterProvider != nil {
+		if err := t.meterProvider.Shutdown(ctx); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -112,3 +246,12 @@ func (t *Telemetry) Tracer(name string) trace.Tracer {
 	}
 	return otel.Tracer(name)
 }
+
+// Meter returns a meter instance. When OTLP metrics are disabled the global
+// meter provider is used, which is a no-op unless configured elsewhere.
+func (t *Telemetry) Meter(name string) metric.Meter {
+	if t.meterProvider != nil {
+		return t.meterProvider.Meter(name)
+	}
+	return otel.Meter(name)
+}
@@ -0,0 +1,180 @@
+package telemetry_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+
+	"github.com/dBiTech/go-apiTemplate/pkg/logger"
+	"github.com/dBiTech/go-apiTemplate/pkg/telemetry"
+)
+
+func TestTelemetry(t *testing.T) {
+	log := logger.Default()
+	ctx := context.Background()
+
+	// Test a no-op meter is returned when metrics export is disabled
+	t.Run("MeterDisabled", func(t *testing.T) {
+		tel, err := telemetry.New(ctx, telemetry.Config{
+			ServiceName:    "test-service",
+			Enabled:        false,
+			MetricsEnabled: false,
+		}, log)
+		require.NoError(t, err)
+
+		meter := tel.Meter("test")
+		require.NotNil(t, meter)
+
+		counter, err := meter.Int64Counter("test_counter")
+		require.NoError(t, err)
+		assert.NotPanics(t, func() {
+			counter.Add(ctx, 1)
+		})
+
+		require.NoError(t, tel.Shutdown(ctx))
+	})
+
+	// Test metrics are exported when tracing is disabled
+	t.Run("MetricsWithoutTracing", func(t *testing.T) {
+		tel, err := telemetry.New(ctx, telemetry.Config{
+			ServiceName:    "test-service",
+			Endpoint:       "127.0.0.1:1",
+			Enabled:        false,
+			MetricsEnabled: true,
+			Insecure:       true,
+		}, log)
+		require.NoError(t, err)
+
+		_, ok := otel.GetMeterProvider().(*sdkmetric.MeterProvider)
+		assert.True(t, ok, "meter provider should be set")
+
+		// Nothing listens on the endpoint, so don't wait long for the flush
+		shutdownCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		_ = tel.Shutdown(shutdownCtx)
+	})
+}
+
+func TestParseSampler(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        string
+		description string
+		wantErr     bool
+	}{
+		{name: "Empty", spec: "", description: "AlwaysOnSampler"},
+		{name: "Always", spec: "always", description: "AlwaysOnSampler"},
+		{name: "Never", spec: "never", description: "AlwaysOffSampler"},
+		{name: "Ratio", spec: "ratio:0.1", description: "TraceIDRatioBased{0.1}"},
+		{name: "RatioOne", spec: "ratio:1", description: "AlwaysOnSampler"},
+		{name: "Unknown", spec: "sometimes", wantErr: true},
+		{name: "RatioNotANumber", spec: "ratio:abc", wantErr: true},
+		{name: "RatioTooLarge", spec: "ratio:1.5", wantErr: true},
+		{name: "RatioNegative", spec: "ratio:-0.1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sampler, err := telemetry.ParseSampler(tt.spec)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Nil(t, sampler)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.description, sampler.Description())
+		})
+	}
+
+	// Test an invalid sampler fails telemetry construction
+	t.Run("InvalidSamplerInNew", func(t *testing.T) {
+		_, err := telemetry.New(context.Background(), telemetry.Config{
+			ServiceName: "test-service",
+			Enabled:     true,
+			Sampler:     "bogus",
+		}, logger.Default())
+		require.Error(t, err)
+	})
+}
+
+func TestTraceProtocol(t *testing.T) {
+	log := logger.Default()
+	ctx := context.Background()
+
+	// Test spans are exported over OTLP/HTTP
+	t.Run("HTTP", func(t *testing.T) {
+		var exported atomic.Int32
+		collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/v1/traces" {
+				exported.Add(1)
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer collector.Close()
+
+		tel, err := telemetry.New(ctx, telemetry.Config{
+			ServiceName: "test-service",
+			Endpoint:    strings.TrimPrefix(collector.URL, "http://"),
+			Enabled:     true,
+			Protocol:    "http",
+			Insecure:    true,
+		}, log)
+		require.NoError(t, err)
+
+		_, span := tel.Tracer("test").Start(ctx, "test-span")
+		span.End()
+
+		// Shutdown flushes the batched span to the collector
+		require.NoError(t, tel.Shutdown(ctx))
+		assert.Equal(t, int32(1), exported.Load())
+	})
+
+	// Test metrics are exported over OTLP/HTTP
+	t.Run("MetricsHTTP", func(t *testing.T) {
+		var exported atomic.Int32
+		collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/v1/metrics" {
+				exported.Add(1)
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer collector.Close()
+
+		tel, err := telemetry.New(ctx, telemetry.Config{
+			ServiceName:    "test-service",
+			Endpoint:       strings.TrimPrefix(collector.URL, "http://"),
+			MetricsEnabled: true,
+			Protocol:       "http",
+			Insecure:       true,
+		}, log)
+		require.NoError(t, err)
+
+		counter, err := tel.Meter("test").Int64Counter("test_counter")
+		require.NoError(t, err)
+		counter.Add(ctx, 1)
+
+		// Shutdown flushes the metrics to the collector
+		require.NoError(t, tel.Shutdown(ctx))
+		assert.Positive(t, exported.Load())
+	})
+
+	// Test an unknown protocol is rejected
+	t.Run("InvalidProtocol", func(t *testing.T) {
+		_, err := telemetry.New(ctx, telemetry.Config{
+			ServiceName: "test-service",
+			Enabled:     true,
+			Protocol:    "carrier-pigeon",
+		}, log)
+		require.Error(t, err)
+	})
+}
@@ -1,18 +1,27 @@
 package integration
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/dBiTech/go-apiTemplate/internal/api"
 	"github.com/dBiTech/go-apiTemplate/internal/config"
 	"github.com/dBiTech/go-apiTemplate/internal/models"
+	"github.com/dBiTech/go-apiTemplate/internal/service"
 )
 
 func TestAPIIntegration(t *testing.T) {
@@ -206,16 +215,47 @@ func TestAPIIntegration(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, w.Code)
 
-		var resources []*models.ProtectedResource
-		err = json.Unmarshal(w.Body.Bytes(), &resources)
+		var page models.Page[*models.ProtectedResource]
+		err = json.Unmarshal(w.Body.Bytes(), &page)
 		require.NoError(t, err)
 
+		resources := page.Data
 		assert.Len(t, resources, 2)
 		assert.NotEmpty(t, resources[0].ID)
 		assert.NotEmpty(t, resources[0].Name)
 		assert.NotEmpty(t, resources[0].Content)
 	})
 
+	// Test a read token and an admin token see different resources
+	t.Run("JWTProtectedEndpoint_ScopeFiltering", func(t *testing.T) {
+		authInstance := server.GetAuthenticator()
+
+		// list returns the IDs of the resources visible with the given scopes
+		list := func(scopes ...string) []string {
+			token, err := authInstance.GenerateJWTToken("test-user", []string{"user"}, scopes)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/protected/jwt", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var page models.Page[*models.ProtectedResource]
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+
+			ids := make([]string, len(page.Data))
+			for i, resource := range page.Data {
+				ids[i] = resource.ID
+			}
+			return ids
+		}
+
+		assert.Equal(t, []string{"protected-resource-1", "protected-resource-2"}, list("read"))
+		assert.Equal(t, []string{"protected-resource-1", "protected-resource-2", "protected-resource-3"}, list("read", "admin"))
+	})
+
 	// Test user profile endpoint (authorized with JWT)
 	t.Run("UserProfileEndpoint_JWTAuthorized", func(t *testing.T) {
 		// Get a JWT token from the server's auth instance
@@ -240,4 +280,567 @@ func TestAPIIntegration(t *testing.T) {
 		assert.NotEmpty(t, profile.Username)
 		assert.NotEmpty(t, profile.Email)
 	})
+
+	// Test the user profile reflects the roles and scopes in the token
+	t.Run("UserProfileEndpoint_TokenClaims", func(t *testing.T) {
+		authInstance := server.GetAuthenticator()
+		roles := []string{"editor", "auditor"}
+		scopes := []string{"read", "reports:export"}
+		token, err := authInstance.GenerateJWTToken("claims-user", roles, scopes)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var profile models.UserProfile
+		err = json.Unmarshal(w.Body.Bytes(), &profile)
+		require.NoError(t, err)
+
+		assert.Equal(t, "claims-user", profile.ID)
+		assert.Equal(t, roles, profile.Roles)
+		assert.Equal(t, scopes, profile.Scopes)
+	})
+
+	// Test the development reset route is absent unless enabled
+	t.Run("ResetRouteDisabled", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/examples", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+
+	// Test unknown paths return a JSON 404
+	t.Run("NotFound", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/does-not-exist", nil)
+		req.Header.Set("X-Request-ID", "test-request-id")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var resp map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		require.NoError(t, err)
+
+		assert.InDelta(t, float64(http.StatusNotFound), resp["status"], 0)
+		assert.Equal(t, "Not Found", resp["message"])
+		assert.Equal(t, "test-request-id", resp["requestId"])
+	})
+
+	// Test unsupported methods return a JSON 405 with the allowed methods
+	t.Run("MethodNotAllowed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/examples/some-id", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.Equal(t, "GET, PUT, DELETE", w.Header().Get("Allow"))
+
+		var resp map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		require.NoError(t, err)
+
+		assert.InDelta(t, float64(http.StatusMethodNotAllowed), resp["status"], 0)
+		assert.Equal(t, "Method Not Allowed", resp["message"])
+		assert.NotEmpty(t, resp["requestId"])
+	})
+
+	// Test error bodies carry the request ID from the response header
+	t.Run("ErrorRequestID", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/examples/does-not-exist", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		requestID := w.Header().Get("X-Request-ID")
+		require.NotEmpty(t, requestID)
+
+		var resp map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		require.NoError(t, err)
+
+		assert.Equal(t, requestID, resp["requestId"])
+	})
+}
+
+func TestDevRoutes(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:      "localhost",
+			Port:      8080,
+			DevRoutes: true,
+		},
+		Logging: config.LoggingConfig{
+			Level:  "info",
+			Format: "text",
+		},
+	}
+
+	server, err := api.NewServer(cfg)
+	require.NoError(t, err)
+	router := server.GetRouter()
+
+	// Test the reset route deletes every example
+	t.Run("ResetExamples", func(t *testing.T) {
+		for _, name := range []string{"First", "Second"} {
+			body, err := json.Marshal(models.ExampleRequest{Name: name})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/examples", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusCreated, w.Code)
+		}
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/examples", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		req = httptest.NewRequest(http.MethodGet, "/api/v1/examples", nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var page models.Page[models.Example]
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		assert.Empty(t, page.Data)
+	})
+}
+
+func TestExportExamples(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host: "localhost",
+			Port: 8080,
+		},
+		Logging: config.LoggingConfig{
+			Level:  "info",
+			Format: "text",
+		},
+	}
+
+	server, err := api.NewServer(cfg)
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(server.GetRouter())
+	defer ts.Close()
+
+	const total = 5
+	for i := 0; i < total; i++ {
+		body, err := json.Marshal(models.ExampleRequest{Name: fmt.Sprintf("Export %d", i)})
+		require.NoError(t, err)
+
+		resp, err := http.Post(ts.URL+"/api/v1/examples", "application/json", bytes.NewReader(body))
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+
+	// Test the export streams one JSON record per line
+	t.Run("StreamsNDJSON", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/api/v1/examples/export")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+		count := 0
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			var example models.Example
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &example))
+			assert.NotEmpty(t, example.ID)
+			count++
+		}
+		require.NoError(t, scanner.Err())
+		assert.Equal(t, total, count)
+	})
+}
+
+func TestAdminListener(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host: "127.0.0.1",
+			Port: 0,
+		},
+		Logging: config.LoggingConfig{
+			Level:  "info",
+			Format: "text",
+		},
+		Metrics: config.MetricsConfig{
+			Enabled:       true,
+			Host:          "127.0.0.1",
+			Port:          0,
+			AdminListener: true,
+		},
+	}
+
+	server, err := api.NewServer(cfg)
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	defer server.Stop()
+
+	require.NotEmpty(t, server.AdminAddr())
+	require.NotEqual(t, server.Addr(), server.AdminAddr())
+
+	// get fetches a path from addr and returns the status code
+	get := func(t *testing.T, addr, path string) int {
+		t.Helper()
+
+		resp, err := http.Get("http://" + addr + path)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp.StatusCode
+	}
+
+	// Test metrics are served on the admin port only
+	t.Run("Metrics", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, get(t, server.AdminAddr(), "/metrics"))
+		assert.Equal(t, http.StatusNotFound, get(t, server.Addr(), "/metrics"))
+	})
+
+	// Test the API is served on the API port only
+	t.Run("API", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, get(t, server.Addr(), "/api/v1/hello"))
+		assert.Equal(t, http.StatusNotFound, get(t, server.AdminAddr(), "/api/v1/hello"))
+	})
+}
+
+func TestShutdownHooks(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host: "127.0.0.1",
+			Port: 0,
+		},
+		Logging: config.LoggingConfig{
+			Level:  "info",
+			Format: "text",
+		},
+	}
+
+	server, err := api.NewServer(cfg)
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+
+	var order []string
+	server.OnShutdown(func(ctx context.Context) error {
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline)
+		order = append(order, "first")
+		return nil
+	})
+	server.OnShutdown(func(context.Context) error {
+		order = append(order, "second")
+		return errors.New("cleanup failed")
+	})
+
+	server.Stop()
+
+	// Test both hooks ran, last registered first, despite the failure
+	assert.Equal(t, []string{"second", "first"}, order)
+}
+
+func TestShutdownClosesStreams(t *testing.T) {
+	server, err := api.NewServer(&config.Config{
+		Server: config.ServerConfig{
+			Host: "127.0.0.1",
+			Port: 0,
+		},
+		Logging: config.LoggingConfig{
+			Level:  "info",
+			Format: "text",
+		},
+		Auth: config.AuthConfig{
+			Enabled:           true,
+			JWTSecret:         "test-secret-key",
+			JWTSigningMethod:  "HS256",
+			JWTExpirationTime: 24 * 60 * 60 * 1000000000, // 24 hours in nanoseconds
+			JWTIssuer:         "api-template-test",
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+
+	token, err := server.GetAuthenticator().GenerateJWTToken("watcher", []string{"user"}, []string{"read"})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+server.Addr()+"/api/v1/examples/events", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	stopped := make(chan time.Duration, 1)
+	go func() {
+		start := time.Now()
+		server.Stop()
+		stopped <- time.Since(start)
+	}()
+
+	// Test the open stream is told to close rather than cut off
+	scanner := bufio.NewScanner(resp.Body)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	assert.Contains(t, lines, "event: close")
+
+	// Test Stop didn't wait out the shutdown timeout on the stream
+	select {
+	case elapsed := <-stopped:
+		assert.Less(t, elapsed, 5*time.Second)
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "server didn't stop")
+	}
+}
+
+func TestBasePath(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:     "localhost",
+			Port:     8080,
+			BasePath: "/myservice/v1",
+		},
+		Logging: config.LoggingConfig{
+			Level:  "info",
+			Format: "text",
+		},
+	}
+
+	server, err := api.NewServer(cfg)
+	require.NoError(t, err)
+	router := server.GetRouter()
+
+	// get serves a GET for path and returns the status code
+	get := func(path string) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code
+	}
+
+	// Test the API is served under the configured base path only
+	t.Run("API", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, get("/myservice/v1/hello"))
+		assert.Equal(t, http.StatusNotFound, get("/api/v1/hello"))
+	})
+
+	// Test health routes stay at the root
+	t.Run("Health", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, get("/health"))
+	})
+}
+
+func TestExampleStatus(t *testing.T) {
+	server, err := api.NewServer(&config.Config{
+		Server: config.ServerConfig{
+			Host: "localhost",
+			Port: 8080,
+		},
+		Logging: config.LoggingConfig{
+			Level:  "info",
+			Format: "text",
+		},
+	})
+	require.NoError(t, err)
+	router := server.GetRouter()
+
+	// create posts an example with the given JSON body
+	create := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/examples", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Test a valid status is stored
+	t.Run("Valid", func(t *testing.T) {
+		w := create(`{"name":"Archived example","status":"archived"}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var example models.Example
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &example))
+		assert.Equal(t, models.StatusArchived, example.Status)
+	})
+
+	// Test an invalid status is rejected
+	t.Run("Invalid", func(t *testing.T) {
+		w := create(`{"name":"Bad example","status":"deleted"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "status must be one of active, inactive, archived")
+	})
+
+	// Test an omitted status defaults to active
+	t.Run("Default", func(t *testing.T) {
+		w := create(`{"name":"Plain example"}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var example models.Example
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &example))
+		assert.Equal(t, models.StatusActive, example.Status)
+	})
+}
+
+func TestWatchExamples(t *testing.T) {
+	server, err := api.NewServer(&config.Config{
+		Server: config.ServerConfig{
+			Host: "localhost",
+			Port: 8080,
+			// Exercise the upgrade through the wrapping middleware
+			Compression:  true,
+			ServerTiming: true,
+		},
+		Logging: config.LoggingConfig{
+			Level:  "info",
+			Format: "text",
+		},
+		Auth: config.AuthConfig{
+			Enabled:           true,
+			JWTSecret:         "test-secret-key",
+			JWTSigningMethod:  "HS256",
+			JWTExpirationTime: 24 * 60 * 60 * 1000000000, // 24 hours in nanoseconds
+			JWTIssuer:         "api-template-test",
+		},
+	})
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(server.GetRouter())
+	defer ts.Close()
+
+	watchURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/api/v1/examples/watch"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Test the upgrade requires a token
+	t.Run("Unauthorized", func(t *testing.T) {
+		_, resp, err := websocket.Dial(ctx, watchURL, nil)
+		require.Error(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	// Test a watcher receives the event for an example created after connecting
+	t.Run("ReceivesCreate", func(t *testing.T) {
+		token, err := server.GetAuthenticator().GenerateJWTToken("watcher", []string{"user"}, []string{"read"})
+		require.NoError(t, err)
+
+		conn, _, err := websocket.Dial(ctx, watchURL, &websocket.DialOptions{
+			HTTPHeader: http.Header{"Authorization": {"Bearer " + token}},
+		})
+		require.NoError(t, err)
+		defer conn.CloseNow()
+
+		body, err := json.Marshal(models.ExampleRequest{Name: "Watched"})
+		require.NoError(t, err)
+		resp, err := http.Post(ts.URL+"/api/v1/examples", "application/json", bytes.NewReader(body))
+		require.NoError(t, err)
+		var created models.Example
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var event service.Event
+		require.NoError(t, wsjson.Read(ctx, conn, &event))
+		assert.Equal(t, service.EventExampleCreated, event.Type)
+		assert.Equal(t, created.ID, event.EntityID)
+
+		require.NoError(t, conn.Close(websocket.StatusNormalClosure, ""))
+	})
+}
+
+func TestMetricsAuth(t *testing.T) {
+	// newRouter builds a server with metrics configured by metricsCfg
+	newRouter := func(t *testing.T, metricsCfg config.MetricsConfig) (*api.Server, http.Handler) {
+		t.Helper()
+
+		metricsCfg.Enabled = true
+		server, err := api.NewServer(&config.Config{
+			Server: config.ServerConfig{
+				Host: "localhost",
+				Port: 8080,
+			},
+			Logging: config.LoggingConfig{
+				Level:  "info",
+				Format: "text",
+			},
+			Metrics: metricsCfg,
+			Auth: config.AuthConfig{
+				Enabled:           true,
+				JWTSecret:         "test-secret-key",
+				JWTSigningMethod:  "HS256",
+				JWTExpirationTime: 24 * 60 * 60 * 1000000000, // 24 hours in nanoseconds
+				JWTIssuer:         "api-template-test",
+			},
+		})
+		require.NoError(t, err)
+		return server, server.GetRouter()
+	}
+
+	// get serves a GET /metrics through router after prepare and returns the recorder
+	get := func(router http.Handler, prepare func(*http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if prepare != nil {
+			prepare(req)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Test metrics are open by default
+	t.Run("OpenByDefault", func(t *testing.T) {
+		_, router := newRouter(t, config.MetricsConfig{})
+		assert.Equal(t, http.StatusOK, get(router, nil).Code)
+	})
+
+	// Test basic auth credentials are required when configured
+	t.Run("BasicAuth", func(t *testing.T) {
+		_, router := newRouter(t, config.MetricsConfig{
+			RequireAuth: true,
+			Username:    "prometheus",
+			Password:    "scrape-secret",
+		})
+
+		w := get(router, nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, `Basic realm="metrics"`, w.Header().Get("WWW-Authenticate"))
+
+		w = get(router, func(r *http.Request) { r.SetBasicAuth("prometheus", "wrong") })
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = get(router, func(r *http.Request) { r.SetBasicAuth("prometheus", "scrape-secret") })
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	// Test a bearer token is required when no basic auth user is configured
+	t.Run("Bearer", func(t *testing.T) {
+		server, router := newRouter(t, config.MetricsConfig{RequireAuth: true})
+
+		assert.Equal(t, http.StatusUnauthorized, get(router, nil).Code)
+
+		token, err := server.GetAuthenticator().GenerateJWTToken("prometheus", nil, nil)
+		require.NoError(t, err)
+
+		w := get(router, func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) })
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
 }